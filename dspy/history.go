@@ -0,0 +1,69 @@
+package dspy
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Conversation roles.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one turn of a conversation.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// History is an input field type holding the prior turns of a
+// conversation. A Predictor renders History fields as the conversation so
+// far, ahead of the other inputs, so a chat program can be written and
+// optimized like a single-turn one:
+//
+//	type ChatInput struct {
+//		History  dspy.History
+//		Question string
+//	}
+type History struct {
+	Messages []Message `json:"messages"`
+}
+
+// NewHistory creates a history from messages, oldest first.
+func NewHistory(messages ...Message) History {
+	return History{Messages: messages}
+}
+
+// Len returns the number of messages.
+func (h History) Len() int {
+	return len(h.Messages)
+}
+
+// Append returns a copy of h with messages added at the end.
+func (h History) Append(messages ...Message) History {
+	all := make([]Message, 0, len(h.Messages)+len(messages))
+	all = append(all, h.Messages...)
+	return History{Messages: append(all, messages...)}
+}
+
+// String renders the history one message per line, as "Role: content".
+func (h History) String() string {
+	lines := make([]string, len(h.Messages))
+	for i, m := range h.Messages {
+		lines[i] = fmt.Sprintf("%s: %s", roleTitle(m.Role), m.Content)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roleTitle capitalizes role for display.
+func roleTitle(role string) string {
+	r, size := utf8.DecodeRuneInString(role)
+	if size == 0 {
+		return "Unknown"
+	}
+	return string(unicode.ToUpper(r)) + role[size:]
+}
@@ -0,0 +1,64 @@
+package dspy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+func TestHistory_String(t *testing.T) {
+	h := NewHistory(
+		Message{Role: RoleUser, Content: "Who directed Oppenheimer?"},
+		Message{Role: RoleAssistant, Content: "Christopher Nolan."},
+	)
+	want := "User: Who directed Oppenheimer?\nAssistant: Christopher Nolan."
+	if got := h.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestHistory_AppendCopies(t *testing.T) {
+	base := NewHistory(Message{Role: RoleUser, Content: "a"})
+	first := base.Append(Message{Role: RoleAssistant, Content: "b"})
+	second := base.Append(Message{Role: RoleAssistant, Content: "c"})
+
+	if base.Len() != 1 || first.Messages[1].Content != "b" || second.Messages[1].Content != "c" {
+		t.Errorf("Expected Append not to share storage, got %v %v %v", base, first, second)
+	}
+}
+
+func TestPredictor_RendersHistory(t *testing.T) {
+	type Input struct {
+		History  History
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	sig := NewSignature[Input, Output]("Chat", "Answer the follow-up question")
+	predictor := NewPredictor(sig, llm.NewMockClient())
+
+	prompt := predictor.buildPrompt(Input{
+		History: NewHistory(
+			Message{Role: RoleUser, Content: "Who directed Oppenheimer?"},
+			Message{Role: RoleAssistant, Content: "Christopher Nolan."},
+		),
+		Question: "Where was he born?",
+	})
+
+	history := strings.Index(prompt, "Conversation history:\nUser: Who directed Oppenheimer?\nAssistant: Christopher Nolan.")
+	input := strings.Index(prompt, "Input:\nQuestion: Where was he born?")
+	if history < 0 || input < 0 || history > input {
+		t.Errorf("Expected history rendered before the inputs, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "History:") {
+		t.Errorf("Expected history not to be rendered as a plain field, got:\n%s", prompt)
+	}
+
+	empty := predictor.buildPrompt(Input{Question: "Hi"})
+	if strings.Contains(empty, "Conversation history") {
+		t.Errorf("Expected no history section for an empty history, got:\n%s", empty)
+	}
+}
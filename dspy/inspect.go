@@ -0,0 +1,196 @@
+package dspy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// DefaultInteractionLogSize is the number of interactions kept by an
+// InteractionLog created with a non-positive size.
+const DefaultInteractionLogSize = 20
+
+// Interaction is a single LLM call made by a Predictor, as kept by an
+// InteractionLog.
+type Interaction struct {
+	// Predictor is the name of the signature that was executed.
+	Predictor string `json:"predictor"`
+	// Time is when Forward was called.
+	Time time.Time `json:"time"`
+	// Messages are the rendered messages sent to the LLM.
+	Messages []Message `json:"messages"`
+	// Response is the raw text returned by the LLM.
+	Response string `json:"response"`
+	// Output is the parsed output, or nil when Error is set.
+	Output interface{} `json:"output,omitempty"`
+	// Error is the error returned by Forward, if any.
+	Error string `json:"error,omitempty"`
+	// Usage is the token usage and time reported by the LLM client.
+	Usage llm.Usage `json:"usage"`
+	// Latency is the wall-clock duration of Forward, in nanoseconds when
+	// encoded as JSON.
+	Latency time.Duration `json:"latency"`
+}
+
+// InteractionLog is a ring buffer of the most recent Interactions, for
+// inspecting what was sent to and returned by the LLM. It is safe for
+// concurrent use.
+//
+// Attach one to a Predictor with WithInteractionLog, or to every
+// Predictor with SetInteractionLog.
+type InteractionLog struct {
+	mu      sync.Mutex
+	entries []Interaction
+	next    int
+	full    bool
+}
+
+// NewInteractionLog creates a log that keeps the last size interactions.
+// A non-positive size means DefaultInteractionLogSize.
+func NewInteractionLog(size int) *InteractionLog {
+	if size <= 0 {
+		size = DefaultInteractionLogSize
+	}
+	return &InteractionLog{entries: make([]Interaction, size)}
+}
+
+// Record adds an interaction, discarding the oldest one if the log is full.
+func (l *InteractionLog) Record(interaction Interaction) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = interaction
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Last returns the n most recent interactions, oldest first. A
+// non-positive n returns all of them.
+func (l *InteractionLog) Last(n int) []Interaction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	size := l.lenLocked()
+	if n <= 0 || n > size {
+		n = size
+	}
+	out := make([]Interaction, n)
+	for i := range out {
+		out[i] = l.entries[(l.next-n+i+len(l.entries))%len(l.entries)]
+	}
+	return out
+}
+
+// Len returns the number of interactions in the log.
+func (l *InteractionLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lenLocked()
+}
+
+func (l *InteractionLog) lenLocked() int {
+	if l.full {
+		return len(l.entries)
+	}
+	return l.next
+}
+
+// Reset discards all interactions.
+func (l *InteractionLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.entries {
+		l.entries[i] = Interaction{}
+	}
+	l.next = 0
+	l.full = false
+}
+
+// Print writes the n most recent interactions to w in a readable form,
+// oldest first. A non-positive n prints all of them.
+func (l *InteractionLog) Print(w io.Writer, n int) error {
+	for i, interaction := range l.Last(n) {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, interaction.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the n most recent interactions to w as an indented
+// JSON array, oldest first. A non-positive n writes all of them.
+func (l *InteractionLog) WriteJSON(w io.Writer, n int) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(l.Last(n)); err != nil {
+		return fmt.Errorf("encode interactions: %w", err)
+	}
+	return nil
+}
+
+// String renders the interaction as printed by InteractionLog.Print.
+func (i Interaction) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[%s] %s (%s", i.Time.Format(time.RFC3339), i.Predictor, i.Latency.Round(time.Millisecond))
+	if i.Usage.Calls > 0 {
+		fmt.Fprintf(&b, ", %s, %d input + %d output tokens", i.Usage.Model, i.Usage.InputTokens, i.Usage.OutputTokens)
+	}
+	b.WriteString(")\n")
+
+	for _, m := range i.Messages {
+		fmt.Fprintf(&b, "\n%s:\n%s\n", roleTitle(m.Role), m.Content)
+	}
+	if i.Response != "" {
+		fmt.Fprintf(&b, "\nResponse:\n%s\n", i.Response)
+	}
+	if i.Error != "" {
+		fmt.Fprintf(&b, "\nError: %s\n", i.Error)
+	} else {
+		fmt.Fprintf(&b, "\nOutput: %+v\n", i.Output)
+	}
+	return b.String()
+}
+
+var globalLog struct {
+	mu  sync.RWMutex
+	log *InteractionLog
+}
+
+// SetInteractionLog makes every Predictor record its interactions into
+// log, in addition to any log set with WithInteractionLog. A nil log
+// disables global recording.
+func SetInteractionLog(log *InteractionLog) {
+	globalLog.mu.Lock()
+	defer globalLog.mu.Unlock()
+	globalLog.log = log
+}
+
+// GlobalInteractionLog returns the log set with SetInteractionLog, or nil.
+func GlobalInteractionLog() *InteractionLog {
+	globalLog.mu.RLock()
+	defer globalLog.mu.RUnlock()
+	return globalLog.log
+}
+
+// InspectHistory prints the n most recent interactions in the global log
+// to w, like DSPy's inspect_history. It prints nothing if no global log is
+// set.
+func InspectHistory(w io.Writer, n int) error {
+	log := GlobalInteractionLog()
+	if log == nil {
+		return nil
+	}
+	return log.Print(w, n)
+}
@@ -0,0 +1,143 @@
+package dspy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+func TestInteractionLog_RingBuffer(t *testing.T) {
+	log := NewInteractionLog(3)
+	for i := 1; i <= 5; i++ {
+		log.Record(Interaction{Predictor: fmt.Sprint(i)})
+	}
+
+	if log.Len() != 3 {
+		t.Fatalf("Expected 3 interactions kept, got %d", log.Len())
+	}
+	var names []string
+	for _, interaction := range log.Last(0) {
+		names = append(names, interaction.Predictor)
+	}
+	if got := strings.Join(names, ","); got != "3,4,5" {
+		t.Errorf("Expected the latest interactions oldest first, got %s", got)
+	}
+	if last := log.Last(1); len(last) != 1 || last[0].Predictor != "5" {
+		t.Errorf("Expected the most recent interaction, got %v", last)
+	}
+
+	log.Reset()
+	if log.Len() != 0 || len(log.Last(0)) != 0 {
+		t.Error("Expected an empty log after Reset")
+	}
+}
+
+func TestPredictor_WithInteractionLog(t *testing.T) {
+	type Input struct {
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	client := llm.NewMockClient().
+		WithResponse("Question: 2+2?", "Answer: 4").
+		WithDefaultResponse("")
+	log := NewInteractionLog(10)
+	predictor := NewPredictor(NewSignature[Input, Output]("Math", "Add numbers"), client).
+		WithInteractionLog(log)
+
+	predictor.Forward(context.Background(), Input{Question: "2+2?"})
+	predictor.Forward(context.Background(), Input{Question: "unknown"})
+
+	interactions := log.Last(0)
+	if len(interactions) != 2 {
+		t.Fatalf("Expected 2 interactions, got %d", len(interactions))
+	}
+	ok, failed := interactions[0], interactions[1]
+	if ok.Predictor != "Math" || ok.Response != "Answer: 4" || ok.Output.(Output).Answer != "4" {
+		t.Errorf("Unexpected interaction %+v", ok)
+	}
+	if len(ok.Messages) != 1 || !strings.Contains(ok.Messages[0].Content, "Question: 2+2?") {
+		t.Errorf("Expected the rendered prompt, got %v", ok.Messages)
+	}
+	if failed.Error == "" || failed.Output != nil {
+		t.Errorf("Expected the failed call recorded with its error, got %+v", failed)
+	}
+
+	var buf bytes.Buffer
+	if err := log.Print(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "] Math (") || !strings.Contains(buf.String(), "Error: ") ||
+		strings.Contains(buf.String(), "Answer: 4") {
+		t.Errorf("Expected only the last interaction printed, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := log.WriteJSON(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Fatalf("Expected a JSON array of 2 interactions, got %s (%v)", buf.String(), err)
+	}
+	if decoded[0]["output"].(map[string]interface{})["Answer"] != "4" {
+		t.Errorf("Expected the parsed output in JSON, got %v", decoded[0])
+	}
+}
+
+func TestInspectHistory_GlobalLogAndUsage(t *testing.T) {
+	type Input struct {
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"message":{"content":"Answer: Paris"}}],
+			"usage":{"prompt_tokens":12,"completion_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	if err := InspectHistory(&buf, 1); err != nil || buf.Len() != 0 {
+		t.Fatalf("Expected nothing printed without a global log, got %q (%v)", buf.String(), err)
+	}
+
+	log := NewInteractionLog(5)
+	SetInteractionLog(log)
+	defer SetInteractionLog(nil)
+
+	client := llm.NewOpenAIClient("key").WithBaseURL(server.URL)
+	predictor := NewPredictor(NewSignature[Input, Output]("Capital", ""), client)
+	ctx, pipeline := llm.TrackUsage(context.Background())
+	if _, err := predictor.Forward(ctx, Input{Question: "France?"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := pipeline.Usage(); got.InputTokens != 12 || got.Calls != 1 {
+		t.Errorf("Expected the caller's tracker to see the predictor's usage, got %+v", got)
+	}
+
+	usage := log.Last(1)[0].Usage
+	if usage.Model != "gpt-4o-mini" || usage.InputTokens != 12 || usage.OutputTokens != 3 || usage.Calls != 1 {
+		t.Errorf("Expected usage reported by the client, got %+v", usage)
+	}
+
+	if err := InspectHistory(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Capital", "gpt-4o-mini, 12 input + 3 output tokens", "User:\n", "Response:\nAnswer: Paris", "Output: {Answer:Paris}"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, buf.String())
+		}
+	}
+}
@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/supadev-ai/go-dspy/llm"
+	"github.com/supadev-ai/go-dspy/tracing"
 )
 
 // Predictor is an LLM-backed module that uses a Signature to transform inputs to outputs.
 type Predictor[I any, O any] struct {
 	Signature Signature[I, O]
 	Client    llm.Client
+
+	log *InteractionLog
 }
 
 // NewPredictor creates a new Predictor with the given signature and LLM client.
@@ -24,26 +28,88 @@ func NewPredictor[I any, O any](sig Signature[I, O], client llm.Client) *Predict
 	}
 }
 
+// WithInteractionLog records every call made by the predictor into log,
+// for inspection with InteractionLog.Print.
+func (p *Predictor[I, O]) WithInteractionLog(log *InteractionLog) *Predictor[I, O] {
+	p.log = log
+	return p
+}
+
 // Forward implements the Module interface.
-func (p *Predictor[I, O]) Forward(ctx context.Context, input I) (O, error) {
-	var output O
+// If ctx carries a Trace, the call is recorded into it. Each call is also
+// wrapped in an OpenTelemetry span, and the LLM calls it makes are labeled
+// with the predictor in metrics; see the tracing package. Calls are kept in
+// the predictor's and the global InteractionLog, if set.
+func (p *Predictor[I, O]) Forward(ctx context.Context, input I) (output O, err error) {
+	start := time.Now()
+	ctx, usage := llm.TrackUsage(ctx)
+	ctx = tracing.WithLabels(ctx, tracing.AttrPredictor.String(p.Signature.Name))
+	ctx, span := tracing.Start(ctx, "predict "+p.Signature.Name, tracing.AttrPredictor.String(p.Signature.Name))
+	defer func() { tracing.End(span, err) }()
 
 	prompt := p.buildPrompt(input)
-	
+	tracing.RecordPrompt(span, prompt)
+
 	response, err := p.Client.Generate(ctx, prompt)
 	if err != nil {
-		return output, ErrModuleExecution("predictor.Forward", err)
+		err = ErrModuleExecution("predictor.Forward", err)
+		p.record(ctx, start, usage, input, prompt, "", output, err)
+		return output, err
 	}
+	tracing.RecordCompletion(span, response)
 
 	// Parse the response into the output type
 	parsed, err := p.parseResponse(response)
 	if err != nil {
-		return output, ErrModuleExecution("predictor.parseResponse", err)
+		err = ErrModuleExecution("predictor.parseResponse", err)
+		p.record(ctx, start, usage, input, prompt, response, output, err)
+		return output, err
 	}
 
+	p.record(ctx, start, usage, input, prompt, response, parsed, nil)
 	return parsed, nil
 }
 
+// record appends a trace entry for a Forward call to the trace in ctx and
+// adds the call to the interaction logs.
+func (p *Predictor[I, O]) record(ctx context.Context, start time.Time, usage *llm.UsageTracker, input I, prompt, completion string, output O, err error) {
+	latency := time.Since(start)
+	recordTrace(ctx, TraceEntry{
+		Kind:       TraceKindPredict,
+		Predictor:  p.Signature.Name,
+		Input:      input,
+		Prompt:     prompt,
+		Completion: completion,
+		Output:     output,
+		Err:        err,
+		Latency:    latency,
+	})
+
+	global := GlobalInteractionLog()
+	if p.log == nil && global == nil {
+		return
+	}
+	interaction := Interaction{
+		Predictor: p.Signature.Name,
+		Time:      start,
+		Messages:  []Message{{Role: RoleUser, Content: prompt}},
+		Response:  completion,
+		Usage:     usage.Usage(),
+		Latency:   latency,
+	}
+	if err != nil {
+		interaction.Error = err.Error()
+	} else {
+		interaction.Output = output
+	}
+	if p.log != nil {
+		p.log.Record(interaction)
+	}
+	if global != nil && global != p.log {
+		global.Record(interaction)
+	}
+}
+
 // buildPrompt constructs a prompt from the signature and input.
 func (p *Predictor[I, O]) buildPrompt(input I) string {
 	var parts []string
@@ -52,8 +118,18 @@ func (p *Predictor[I, O]) buildPrompt(input I) string {
 		parts = append(parts, p.Signature.Description)
 	}
 
-	// Extract input fields
+	// Extract input fields, rendering conversation history as prior turns
 	inputFields := p.extractFields(input)
+	for key, value := range inputFields {
+		history, ok := asHistory(value)
+		if !ok {
+			continue
+		}
+		delete(inputFields, key)
+		if history.Len() > 0 {
+			parts = append(parts, "\nConversation history:", history.String())
+		}
+	}
 	if len(inputFields) > 0 {
 		parts = append(parts, "\nInput:")
 		for key, value := range inputFields {
@@ -107,7 +183,7 @@ func (p *Predictor[I, O]) extractFields(v interface{}) map[string]interface{} {
 func (p *Predictor[I, O]) getOutputFieldNames() []string {
 	var output O
 	typ := reflect.TypeOf(output)
-	
+
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
 	}
@@ -131,7 +207,7 @@ func (p *Predictor[I, O]) getOutputFieldNames() []string {
 // It tries JSON parsing first, then falls back to simple field extraction.
 func (p *Predictor[I, O]) parseResponse(response string) (O, error) {
 	var output O
-	
+
 	// Try JSON parsing first (if response looks like JSON)
 	responseTrimmed := strings.TrimSpace(response)
 	if strings.HasPrefix(responseTrimmed, "{") || strings.HasPrefix(responseTrimmed, "[") {
@@ -179,7 +255,7 @@ func (p *Predictor[I, O]) parseResponse(response string) (O, error) {
 						valueEnd = len(response) - valueStart
 					}
 					valueStr := strings.TrimSpace(response[valueStart : valueStart+valueEnd])
-					
+
 					// Set the field value based on type
 					fieldVal := val.Field(i)
 					if fieldVal.CanSet() {
@@ -201,3 +277,18 @@ func (p *Predictor[I, O]) parseResponse(response string) (O, error) {
 
 	return output, nil
 }
+
+// asHistory returns v as a History if it is one or points to one.
+func asHistory(v interface{}) (History, bool) {
+	switch h := v.(type) {
+	case History:
+		return h, true
+	case *History:
+		if h == nil {
+			return History{}, true
+		}
+		return *h, true
+	default:
+		return History{}, false
+	}
+}
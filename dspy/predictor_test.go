@@ -4,7 +4,11 @@ import (
 	"context"
 	"testing"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	"github.com/supadev-ai/go-dspy/llm"
+	"github.com/supadev-ai/go-dspy/tracing"
 )
 
 func TestNewPredictor(t *testing.T) {
@@ -134,3 +138,35 @@ func TestPredictor_Forward_ClientError(t *testing.T) {
 		t.Error("Expected error from client, got nil")
 	}
 }
+
+func TestPredictor_Forward_Span(t *testing.T) {
+	type Input struct {
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	tracing.SetCaptureContent(true)
+	defer func() {
+		tracing.SetTracerProvider(nil)
+		tracing.SetCaptureContent(false)
+	}()
+
+	client := llm.NewMockClient().WithDefaultResponse("Answer: 4")
+	predictor := NewPredictor(NewSignature[Input, Output]("Math", "Add numbers"), client)
+	if _, err := predictor.Forward(context.Background(), Input{Question: "2+2?"}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "predict Math" {
+		t.Fatalf("Expected one predict span, got %v", spans)
+	}
+	events := spans[0].Events()
+	if len(events) != 2 || events[0].Name != tracing.EventPrompt || events[1].Name != tracing.EventCompletion {
+		t.Errorf("Expected captured prompt and completion, got %v", events)
+	}
+}
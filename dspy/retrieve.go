@@ -0,0 +1,110 @@
+package dspy
+
+import (
+	"context"
+	"time"
+)
+
+// Passage is a piece of retrieved text with its relevance score.
+type Passage struct {
+	ID       string
+	Text     string
+	Score    float64
+	Metadata map[string]string
+}
+
+// Retriever finds the passages most relevant to a query.
+type Retriever interface {
+	// Retrieve returns at most k passages ordered by descending score.
+	Retrieve(ctx context.Context, query string, k int) ([]Passage, error)
+}
+
+// Reranker reorders retrieved passages by relevance to a query.
+type Reranker interface {
+	// Rerank returns at most k of passages ordered by descending relevance.
+	Rerank(ctx context.Context, query string, passages []Passage, k int) ([]Passage, error)
+}
+
+// Retrieve is a module that looks up passages for a query string.
+// It can be composed with Predictors to build retrieve-then-answer programs.
+//
+// If a Reranker is set, Candidates passages are retrieved and the
+// reranker picks the top K of them.
+type Retrieve struct {
+	Name       string
+	Retriever  Retriever
+	K          int
+	Reranker   Reranker
+	Candidates int
+}
+
+// NewRetrieve creates a Retrieve module returning the top k passages.
+func NewRetrieve(retriever Retriever, k int) *Retrieve {
+	return &Retrieve{
+		Name:      "Retrieve",
+		Retriever: retriever,
+		K:         k,
+	}
+}
+
+// WithName sets the name recorded in traces.
+func (r *Retrieve) WithName(name string) *Retrieve {
+	r.Name = name
+	return r
+}
+
+// WithReranker sets a reranker choosing the top K among candidates
+// retrieved passages. Candidates smaller than K are raised to K.
+func (r *Retrieve) WithReranker(reranker Reranker, candidates int) *Retrieve {
+	r.Reranker = reranker
+	r.Candidates = candidates
+	return r
+}
+
+// Forward implements the Module interface.
+// If ctx carries a Trace, the retrieval is recorded into it.
+func (r *Retrieve) Forward(ctx context.Context, query string) ([]Passage, error) {
+	start := time.Now()
+
+	passages, err := r.retrieve(ctx, query)
+	if err != nil {
+		err = ErrModuleExecution("retrieve.Forward", err)
+		passages = nil
+	}
+
+	recordTrace(ctx, TraceEntry{
+		Kind:      TraceKindRetrieve,
+		Predictor: r.Name,
+		Input:     query,
+		Output:    passages,
+		Err:       err,
+		Latency:   time.Since(start),
+	})
+
+	return passages, err
+}
+
+// retrieve fetches passages and reranks them if a reranker is set.
+func (r *Retrieve) retrieve(ctx context.Context, query string) ([]Passage, error) {
+	if r.Reranker == nil {
+		return r.Retriever.Retrieve(ctx, query, r.K)
+	}
+
+	passages, err := r.Retriever.Retrieve(ctx, query, max(r.Candidates, r.K))
+	if err != nil {
+		return nil, err
+	}
+	if len(passages) == 0 {
+		return passages, nil
+	}
+	return r.Reranker.Rerank(ctx, query, passages, r.K)
+}
+
+// PassageTexts returns the text of each passage, in order.
+func PassageTexts(passages []Passage) []string {
+	texts := make([]string, len(passages))
+	for i, p := range passages {
+		texts[i] = p.Text
+	}
+	return texts
+}
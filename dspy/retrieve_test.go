@@ -0,0 +1,158 @@
+package dspy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// staticRetriever returns passages whose text contains the query.
+type staticRetriever struct {
+	passages []Passage
+	err      error
+}
+
+func (s *staticRetriever) Retrieve(ctx context.Context, query string, k int) ([]Passage, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var result []Passage
+	for _, p := range s.passages {
+		if strings.Contains(strings.ToLower(p.Text), strings.ToLower(query)) && len(result) < k {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func TestRetrieve_Forward(t *testing.T) {
+	retriever := &staticRetriever{passages: []Passage{
+		{ID: "1", Text: "Go was designed at Google.", Score: 1},
+		{ID: "2", Text: "Go has goroutines.", Score: 0.5},
+		{ID: "3", Text: "Rust has a borrow checker.", Score: 0.1},
+	}}
+
+	retrieve := NewRetrieve(retriever, 1)
+	trace := NewTrace()
+	ctx := WithTrace(context.Background(), trace)
+
+	passages, err := retrieve.Forward(ctx, "go")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(passages) != 1 || passages[0].ID != "1" {
+		t.Errorf("Expected top passage only, got %v", passages)
+	}
+
+	entries := trace.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 trace entry, got %d", len(entries))
+	}
+	if entries[0].Kind != TraceKindRetrieve || entries[0].Predictor != "Retrieve" || entries[0].Input != "go" {
+		t.Errorf("Unexpected trace entry %+v", entries[0])
+	}
+}
+
+func TestRetrieve_Forward_Error(t *testing.T) {
+	retrieve := NewRetrieve(&staticRetriever{err: errors.New("index offline")}, 3).WithName("Search")
+	trace := NewTrace()
+
+	_, err := retrieve.Forward(WithTrace(context.Background(), trace), "go")
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	entries := trace.Entries()
+	if len(entries) != 1 || entries[0].Err == nil || entries[0].Predictor != "Search" {
+		t.Errorf("Expected failed retrieval to be traced, got %+v", entries)
+	}
+}
+
+// reverseReranker reverses passage order and records its inputs.
+type reverseReranker struct {
+	got []Passage
+	err error
+}
+
+func (r *reverseReranker) Rerank(ctx context.Context, query string, passages []Passage, k int) ([]Passage, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.got = passages
+	var result []Passage
+	for i := len(passages) - 1; i >= 0 && len(result) < k; i-- {
+		result = append(result, passages[i])
+	}
+	return result, nil
+}
+
+func TestRetrieve_Forward_Reranker(t *testing.T) {
+	retriever := &staticRetriever{passages: []Passage{
+		{ID: "1", Text: "Go was designed at Google."},
+		{ID: "2", Text: "Go has goroutines."},
+		{ID: "3", Text: "Go has channels."},
+	}}
+	reranker := &reverseReranker{}
+
+	retrieve := NewRetrieve(retriever, 1).WithReranker(reranker, 3)
+	passages, err := retrieve.Forward(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(reranker.got) != 3 {
+		t.Errorf("Expected reranker to see 3 candidates, got %d", len(reranker.got))
+	}
+	if len(passages) != 1 || passages[0].ID != "3" {
+		t.Errorf("Expected reranked top passage, got %v", passages)
+	}
+
+	reranker.err = errors.New("reranker offline")
+	if _, err := retrieve.Forward(context.Background(), "go"); err == nil {
+		t.Error("Expected reranker error, got nil")
+	}
+}
+
+func TestRetrieveThenAnswer(t *testing.T) {
+	type Input struct {
+		Context  string
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	retrieve := NewRetrieve(&staticRetriever{passages: []Passage{
+		{ID: "1", Text: "Go was designed at Google."},
+	}}, 3)
+	client := llm.NewMockClient().WithResponse("Context: Go was designed at Google.", "Answer: Google")
+	answer := NewPredictor(NewSignature[Input, Output]("Answer", "Answer using the context"), client)
+
+	trace := NewTrace()
+	ctx := WithTrace(context.Background(), trace)
+
+	passages, err := retrieve.Forward(ctx, "go")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out, err := answer.Forward(ctx, Input{
+		Context:  strings.Join(PassageTexts(passages), "\n"),
+		Question: "Where was Go designed?",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if out.Answer != "Google" {
+		t.Errorf("Expected answer 'Google', got '%s'", out.Answer)
+	}
+
+	entries := trace.Entries()
+	if len(entries) != 2 || entries[0].Kind != TraceKindRetrieve || entries[1].Kind != TraceKindPredict {
+		t.Errorf("Expected retrieve then predict entries, got %+v", entries)
+	}
+}
@@ -0,0 +1,95 @@
+package dspy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Kinds of trace entries.
+const (
+	TraceKindPredict  = "predict"
+	TraceKindRetrieve = "retrieve"
+)
+
+// TraceEntry records a single predictor or retrieval invocation.
+type TraceEntry struct {
+	// Kind is TraceKindPredict or TraceKindRetrieve.
+	Kind string
+	// Predictor is the name of the signature or module that was executed.
+	Predictor string
+	// Input is the value passed to Forward.
+	Input interface{}
+	// Prompt is the rendered prompt sent to the LLM. Empty for retrievals.
+	Prompt string
+	// Completion is the raw text returned by the LLM. Empty for retrievals.
+	Completion string
+	// Output is the parsed output, or the zero value when Err is set.
+	Output interface{}
+	// Err is the error returned by Forward, if any.
+	Err error
+	// Latency is the wall-clock duration of the call.
+	Latency time.Duration
+}
+
+// Trace collects TraceEntries from every predictor executed under a context.
+// It is safe for concurrent use.
+type Trace struct {
+	mu      sync.Mutex
+	entries []TraceEntry
+}
+
+// NewTrace creates an empty trace.
+func NewTrace() *Trace {
+	return &Trace{}
+}
+
+// Record appends an entry to the trace.
+func (t *Trace) Record(entry TraceEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, entry)
+}
+
+// Entries returns a copy of the recorded entries in call order.
+func (t *Trace) Entries() []TraceEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := make([]TraceEntry, len(t.entries))
+	copy(entries, t.entries)
+	return entries
+}
+
+// Len returns the number of recorded entries.
+func (t *Trace) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
+
+// Reset discards all recorded entries.
+func (t *Trace) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = nil
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context that records predictor calls into t.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the trace attached to ctx, or nil if there is none.
+func TraceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// recordTrace appends entry to the trace attached to ctx, if any.
+func recordTrace(ctx context.Context, entry TraceEntry) {
+	if t := TraceFromContext(ctx); t != nil {
+		t.Record(entry)
+	}
+}
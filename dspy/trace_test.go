@@ -0,0 +1,104 @@
+package dspy
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+func TestTraceFromContext_None(t *testing.T) {
+	if trace := TraceFromContext(context.Background()); trace != nil {
+		t.Errorf("Expected nil trace, got %v", trace)
+	}
+}
+
+func TestTrace_RecordAndReset(t *testing.T) {
+	trace := NewTrace()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			trace.Record(TraceEntry{Predictor: "p"})
+		}()
+	}
+	wg.Wait()
+
+	if trace.Len() != 10 {
+		t.Errorf("Expected 10 entries, got %d", trace.Len())
+	}
+
+	trace.Reset()
+	if len(trace.Entries()) != 0 {
+		t.Errorf("Expected no entries after Reset, got %d", len(trace.Entries()))
+	}
+}
+
+func TestPredictor_Forward_RecordsTrace(t *testing.T) {
+	type Input struct {
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	client := llm.NewMockClient().
+		WithResponse("Question: What is Go?", "Answer: A language.")
+	predictor := NewPredictor(NewSignature[Input, Output]("QA", "Answer questions"), client)
+
+	trace := NewTrace()
+	ctx := WithTrace(context.Background(), trace)
+
+	if _, err := predictor.Forward(ctx, Input{Question: "What is Go?"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := trace.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Predictor != "QA" {
+		t.Errorf("Expected predictor 'QA', got '%s'", entry.Predictor)
+	}
+	if entry.Completion != "Answer: A language." {
+		t.Errorf("Expected raw completion, got '%s'", entry.Completion)
+	}
+	if out, ok := entry.Output.(Output); !ok || out.Answer != "A language." {
+		t.Errorf("Expected parsed output, got %#v", entry.Output)
+	}
+	if entry.Prompt == "" {
+		t.Error("Expected rendered prompt to be recorded")
+	}
+	if entry.Err != nil {
+		t.Errorf("Expected no error in entry, got %v", entry.Err)
+	}
+}
+
+func TestPredictor_Forward_RecordsError(t *testing.T) {
+	type Input struct {
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	client := llm.NewMockClient().WithDefaultResponse("")
+	predictor := NewPredictor(NewSignature[Input, Output]("QA", "Answer questions"), client)
+
+	trace := NewTrace()
+	ctx := WithTrace(context.Background(), trace)
+
+	if _, err := predictor.Forward(ctx, Input{Question: "?"}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	entries := trace.Entries()
+	if len(entries) != 1 || entries[0].Err == nil {
+		t.Errorf("Expected one entry with an error, got %#v", entries)
+	}
+}
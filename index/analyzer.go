@@ -0,0 +1,134 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenizer splits text into tokens.
+type Tokenizer func(text string) []string
+
+// Stemmer reduces a token to its stem.
+type Stemmer func(token string) string
+
+// DefaultTokenizer lowercases text and splits it into runs of letters and digits.
+func DefaultTokenizer(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// LightStemmer strips common English inflectional suffixes.
+// It is deliberately conservative: stems shorter than three letters are
+// left alone so that short words do not collapse together.
+func LightStemmer(token string) string {
+	rules := []struct {
+		suffix      string
+		replacement string
+	}{
+		{"sses", "ss"},
+		{"ies", "y"},
+		{"ing", ""},
+		{"edly", ""},
+		{"eed", "ee"},
+		{"ed", ""},
+		{"ly", ""},
+		{"s", ""},
+	}
+
+	for _, rule := range rules {
+		if !strings.HasSuffix(token, rule.suffix) {
+			continue
+		}
+		if rule.suffix == "s" && strings.HasSuffix(token, "ss") {
+			return token
+		}
+		base := strings.TrimSuffix(token, rule.suffix)
+		// "agreed" -> "agree", but keep "need" and "speed".
+		if rule.suffix == "eed" && !strings.ContainsAny(base, vowels) {
+			return token
+		}
+		stem := base + rule.replacement
+		if len(stem) < 3 {
+			return token
+		}
+		// "running" -> "runn" -> "run", but keep "added" -> "add",
+		// "falling" -> "fall" and "seeing" -> "see".
+		if rule.replacement == "" && rule.suffix != "s" && rule.suffix != "ly" && undoubled(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		return stem
+	}
+	return token
+}
+
+const vowels = "aeiouy"
+
+// undoubled reports whether stem ends in a consonant that a suffix doubled,
+// as in "runn" or "stopp": dropping it leaves a short consonant-vowel-
+// consonant ending. Doubled l, s, f and z are usually part of the word.
+func undoubled(stem string) bool {
+	n := len(stem)
+	if n < 4 || stem[n-1] != stem[n-2] || strings.ContainsRune(vowels+"lsfz", rune(stem[n-1])) {
+		return false
+	}
+	return strings.ContainsRune(vowels, rune(stem[n-3])) && !strings.ContainsRune(vowels, rune(stem[n-4]))
+}
+
+// EnglishStopwords is a small set of common English function words.
+var EnglishStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in",
+	"into", "is", "it", "no", "not", "of", "on", "or", "such", "that", "the",
+	"their", "then", "there", "these", "they", "this", "to", "was", "will",
+	"with", "what", "which", "who", "how", "do", "does", "did",
+}
+
+// Analyzer turns text into index terms using a tokenizer, an optional
+// stemmer and a stopword list.
+type Analyzer struct {
+	Tokenizer Tokenizer
+	Stemmer   Stemmer
+	Stopwords map[string]bool
+}
+
+// NewAnalyzer creates an analyzer using DefaultTokenizer, LightStemmer and
+// EnglishStopwords.
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{
+		Tokenizer: DefaultTokenizer,
+		Stemmer:   LightStemmer,
+		Stopwords: stopwordSet(EnglishStopwords),
+	}
+}
+
+// Analyze returns the index terms for text.
+func (a *Analyzer) Analyze(text string) []string {
+	tokenize := a.Tokenizer
+	if tokenize == nil {
+		tokenize = DefaultTokenizer
+	}
+
+	tokens := tokenize(text)
+	terms := tokens[:0]
+	for _, tok := range tokens {
+		if a.Stopwords[tok] {
+			continue
+		}
+		if a.Stemmer != nil {
+			tok = a.Stemmer(tok)
+		}
+		if tok != "" {
+			terms = append(terms, tok)
+		}
+	}
+	return terms
+}
+
+// stopwordSet builds a lookup set from a list of words.
+func stopwordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
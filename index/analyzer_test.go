@@ -0,0 +1,58 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLightStemmer(t *testing.T) {
+	tests := map[string]string{
+		"cats":      "cat",
+		"ponies":    "pony",
+		"running":   "run",
+		"falling":   "fall",
+		"jumped":    "jump",
+		"press":     "press",
+		"classes":   "class",
+		"quickly":   "quick",
+		"is":        "is",
+		"sing":      "sing",
+		"goroutine": "goroutine",
+		"add":       "add",
+		"added":     "add",
+		"adding":    "add",
+		"see":       "see",
+		"seeing":    "see",
+		"agree":     "agree",
+		"agreed":    "agree",
+		"agreeing":  "agree",
+		"run":       "run",
+		"stopped":   "stop",
+		"need":      "need",
+		"puffed":    "puff",
+	}
+
+	for in, want := range tests {
+		if got := LightStemmer(in); got != want {
+			t.Errorf("LightStemmer(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	got := analyzer.Analyze("The cats are RUNNING in the garden!")
+	want := []string{"cat", "run", "garden"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Analyze() = %v, want %v", got, want)
+	}
+
+	analyzer.Stemmer = nil
+	analyzer.Stopwords = nil
+	got = analyzer.Analyze("The cats")
+	want = []string{"the", "cats"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Analyze() without stemming = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,350 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+// Document is a unit of text stored in an index.
+// Vector is required by vector indexes and ignored by keyword indexes.
+type Document struct {
+	ID       string            `json:"id"`
+	Text     string            `json:"text"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Vector   []float32         `json:"vector,omitempty"`
+}
+
+// Result is a document matched by a query, with its score.
+type Result struct {
+	Document
+	Score float64
+}
+
+// bm25Doc is an indexed document with its term statistics.
+type bm25Doc struct {
+	doc    Document
+	terms  map[string]int
+	length int
+}
+
+// BM25 is an in-process keyword index using Okapi BM25 ranking.
+// It implements dspy.Retriever and is safe for concurrent use.
+// Configure the analyzer before adding documents: documents are analyzed
+// once, when they are added.
+type BM25 struct {
+	mu       sync.RWMutex
+	k1       float64
+	b        float64
+	analyzer *Analyzer
+	docs     map[string]*bm25Doc
+	postings map[string]map[string]int
+	totalLen int
+}
+
+// NewBM25 creates an empty index with k1=1.2, b=0.75 and the default analyzer.
+func NewBM25() *BM25 {
+	return &BM25{
+		k1:       1.2,
+		b:        0.75,
+		analyzer: NewAnalyzer(),
+		docs:     make(map[string]*bm25Doc),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// WithK1 sets the term frequency saturation parameter.
+func (idx *BM25) WithK1(k1 float64) *BM25 {
+	idx.k1 = k1
+	return idx
+}
+
+// WithB sets the document length normalization parameter, between 0 and 1.
+func (idx *BM25) WithB(b float64) *BM25 {
+	idx.b = b
+	return idx
+}
+
+// WithAnalyzer replaces the analyzer used for documents and queries.
+func (idx *BM25) WithAnalyzer(analyzer *Analyzer) *BM25 {
+	idx.analyzer = analyzer
+	return idx
+}
+
+// WithTokenizer sets the tokenizer.
+func (idx *BM25) WithTokenizer(tokenizer Tokenizer) *BM25 {
+	idx.analyzer.Tokenizer = tokenizer
+	return idx
+}
+
+// WithStemmer sets the stemmer. A nil stemmer disables stemming.
+func (idx *BM25) WithStemmer(stemmer Stemmer) *BM25 {
+	idx.analyzer.Stemmer = stemmer
+	return idx
+}
+
+// WithStopwords sets the stopword list. An empty list disables stopwords.
+func (idx *BM25) WithStopwords(words []string) *BM25 {
+	idx.analyzer.Stopwords = stopwordSet(words)
+	return idx
+}
+
+// Upsert adds documents to the index, replacing any with the same ID.
+func (idx *BM25) Upsert(docs ...Document) error {
+	for _, doc := range docs {
+		if doc.ID == "" {
+			return fmt.Errorf("document has empty ID")
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, doc := range docs {
+		idx.remove(doc.ID)
+		idx.add(doc)
+	}
+	return nil
+}
+
+// Delete removes documents by ID. Unknown IDs are ignored.
+func (idx *BM25) Delete(ids ...string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, id := range ids {
+		idx.remove(id)
+	}
+}
+
+// Get returns the document with the given ID.
+func (idx *BM25) Get(id string) (Document, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	d, ok := idx.docs[id]
+	if !ok {
+		return Document{}, false
+	}
+	return d.doc, true
+}
+
+// Len returns the number of indexed documents.
+func (idx *BM25) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search returns the top k documents for query, ordered by descending score.
+// Documents that share no terms with the query are not returned.
+func (idx *BM25) Search(query string, k int) []Result {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if len(idx.docs) == 0 || k <= 0 {
+		return nil
+	}
+
+	n := float64(len(idx.docs))
+	avgLen := float64(idx.totalLen) / n
+	scores := make(map[string]float64)
+
+	for _, term := range idx.analyzer.Analyze(query) {
+		posting := idx.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			length := float64(idx.docs[id].length)
+			freq := float64(tf)
+			norm := freq + idx.k1*(1-idx.b+idx.b*length/avgLen)
+			scores[id] += idf * freq * (idx.k1 + 1) / norm
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{Document: idx.docs[id].doc, Score: score})
+	}
+	sortResults(results)
+
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// Retrieve implements the dspy.Retriever interface.
+func (idx *BM25) Retrieve(ctx context.Context, query string, k int) ([]dspy.Passage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	return toPassages(idx.Search(query, k)), nil
+}
+
+// bm25Snapshot is the serialized form of a BM25 index.
+// Term statistics are rebuilt from the documents on load.
+type bm25Snapshot struct {
+	Version   int        `json:"version"`
+	K1        float64    `json:"k1"`
+	B         float64    `json:"b"`
+	Documents []Document `json:"documents"`
+}
+
+// Save writes the index parameters and documents to w as JSON.
+func (idx *BM25) Save(w io.Writer) error {
+	idx.mu.RLock()
+	snap := bm25Snapshot{Version: 1, K1: idx.k1, B: idx.b}
+	for _, d := range idx.docs {
+		snap.Documents = append(snap.Documents, d.doc)
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(snap.Documents, func(i, j int) bool {
+		return snap.Documents[i].ID < snap.Documents[j].ID
+	})
+
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("encode index: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the contents of the index with those read from r.
+// Documents are re-analyzed with the index's current analyzer.
+func (idx *BM25) Load(r io.Reader) error {
+	var snap bm25Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("decode index: %w", err)
+	}
+	if snap.Version != 1 {
+		return fmt.Errorf("unsupported index version %d", snap.Version)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.k1 = snap.K1
+	idx.b = snap.B
+	idx.docs = make(map[string]*bm25Doc, len(snap.Documents))
+	idx.postings = make(map[string]map[string]int)
+	idx.totalLen = 0
+	for _, doc := range snap.Documents {
+		idx.add(doc)
+	}
+	return nil
+}
+
+// SaveFile writes the index to path, replacing it atomically.
+func (idx *BM25) SaveFile(path string) error {
+	return writeFileAtomic(path, idx.Save)
+}
+
+// LoadFile replaces the contents of the index with those saved at path.
+func (idx *BM25) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return idx.Load(f)
+}
+
+// add indexes doc. The caller must hold the write lock.
+func (idx *BM25) add(doc Document) {
+	doc.Vector = nil
+	terms := make(map[string]int)
+	length := 0
+	for _, term := range idx.analyzer.Analyze(doc.Text) {
+		terms[term]++
+		length++
+	}
+
+	idx.docs[doc.ID] = &bm25Doc{doc: doc, terms: terms, length: length}
+	idx.totalLen += length
+	for term, tf := range terms {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[string]int)
+			idx.postings[term] = posting
+		}
+		posting[doc.ID] = tf
+	}
+}
+
+// remove unindexes the document with id. The caller must hold the write lock.
+func (idx *BM25) remove(id string) {
+	d, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for term := range d.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLen -= d.length
+	delete(idx.docs, id)
+}
+
+// sortResults orders results by descending score, breaking ties by ID.
+func sortResults(results []Result) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+}
+
+// toPassages converts search results to retrieved passages.
+func toPassages(results []Result) []dspy.Passage {
+	passages := make([]dspy.Passage, len(results))
+	for i, r := range results {
+		passages[i] = dspy.Passage{
+			ID:       r.ID,
+			Text:     r.Text,
+			Score:    r.Score,
+			Metadata: r.Metadata,
+		}
+	}
+	return passages
+}
+
+// writeFileAtomic writes to a temporary file next to path using write,
+// syncs it and renames it over path.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
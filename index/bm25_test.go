@@ -0,0 +1,165 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+func newTestBM25(t *testing.T) *BM25 {
+	t.Helper()
+	idx := NewBM25()
+	err := idx.Upsert(
+		Document{ID: "go", Text: "Go is a statically typed language with goroutines and channels.", Metadata: map[string]string{"lang": "go"}},
+		Document{ID: "rust", Text: "Rust is a systems language with a borrow checker."},
+		Document{ID: "python", Text: "Python is a dynamically typed language popular for machine learning."},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return idx
+}
+
+func TestBM25_Search(t *testing.T) {
+	idx := newTestBM25(t)
+
+	results := idx.Search("goroutines channels", 3)
+	if len(results) != 1 || results[0].ID != "go" {
+		t.Fatalf("Expected only the Go document, got %v", results)
+	}
+
+	results = idx.Search("typed language", 2)
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Score < results[1].Score {
+		t.Error("Expected results ordered by descending score")
+	}
+	for _, r := range results {
+		if r.ID == "rust" {
+			t.Error("Expected untyped document to rank below typed ones")
+		}
+	}
+}
+
+func TestBM25_UpsertAndDelete(t *testing.T) {
+	idx := newTestBM25(t)
+
+	if err := idx.Upsert(Document{ID: "rust", Text: "Rust has goroutines now, apparently."}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if idx.Len() != 3 {
+		t.Errorf("Expected upsert to replace, got %d documents", idx.Len())
+	}
+	if len(idx.Search("borrow", 5)) != 0 {
+		t.Error("Expected old text to be unindexed after update")
+	}
+
+	idx.Delete("go", "missing")
+	results := idx.Search("goroutines", 5)
+	if len(results) != 1 || results[0].ID != "rust" {
+		t.Errorf("Expected only updated document after delete, got %v", results)
+	}
+
+	if _, ok := idx.Get("go"); ok {
+		t.Error("Expected deleted document to be gone")
+	}
+
+	if err := idx.Upsert(Document{Text: "no id"}); err == nil {
+		t.Error("Expected error for empty ID, got nil")
+	}
+}
+
+func TestBM25_Parameters(t *testing.T) {
+	short := Document{ID: "short", Text: "apple"}
+	long := Document{ID: "long", Text: "apple " + strings.Repeat("filler ", 20)}
+
+	// With length normalization the short document wins.
+	idx := NewBM25()
+	idx.Upsert(short, long)
+	if results := idx.Search("apple", 2); results[0].ID != "short" {
+		t.Errorf("Expected short document first with b=0.75, got %v", results)
+	}
+
+	// Without it both score the same and ties break by ID.
+	idx = NewBM25().WithB(0)
+	idx.Upsert(short, long)
+	results := idx.Search("apple", 2)
+	if results[0].Score != results[1].Score || results[0].ID != "long" {
+		t.Errorf("Expected equal scores with b=0, got %v", results)
+	}
+}
+
+func TestBM25_CustomAnalyzer(t *testing.T) {
+	idx := NewBM25().
+		WithTokenizer(func(text string) []string { return strings.Split(text, ",") }).
+		WithStemmer(nil).
+		WithStopwords(nil)
+	idx.Upsert(Document{ID: "1", Text: "new york,paris"})
+
+	if len(idx.Search("new york", 1)) != 1 {
+		t.Error("Expected custom tokenizer to keep multi-word tokens")
+	}
+	if len(idx.Search("york", 1)) != 0 {
+		t.Error("Expected partial token not to match")
+	}
+}
+
+func TestBM25_Retrieve(t *testing.T) {
+	var retriever dspy.Retriever = newTestBM25(t)
+
+	passages, err := retriever.Retrieve(context.Background(), "goroutine", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(passages) != 1 || passages[0].ID != "go" || passages[0].Metadata["lang"] != "go" {
+		t.Errorf("Expected Go passage with metadata, got %v", passages)
+	}
+}
+
+func TestBM25_SaveAndLoad(t *testing.T) {
+	idx := newTestBM25(t).WithK1(2.0)
+
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatalf("Expected no error on Save, got %v", err)
+	}
+
+	loaded := NewBM25()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Expected no error on Load, got %v", err)
+	}
+
+	want := idx.Search("typed language", 3)
+	got := loaded.Search("typed language", 3)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d results after load, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Score != want[i].Score {
+			t.Errorf("Result %d differs after load: %v vs %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBM25_SaveFileAndLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	if err := newTestBM25(t).SaveFile(path); err != nil {
+		t.Fatalf("Expected no error on SaveFile, got %v", err)
+	}
+
+	loaded := NewBM25()
+	if err := loaded.LoadFile(path); err != nil {
+		t.Fatalf("Expected no error on LoadFile, got %v", err)
+	}
+
+	if loaded.Len() != 3 {
+		t.Errorf("Expected 3 documents after load, got %d", loaded.Len())
+	}
+}
@@ -0,0 +1,192 @@
+package index
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Flat is an exact vector index that compares the query against every
+// document. It is the reference for checking approximate indexes and is
+// fast enough for a few thousand documents. It is safe for concurrent use.
+type Flat struct {
+	mu         sync.RWMutex
+	distance   Distance
+	dimensions int
+	docs       map[string]flatDoc
+}
+
+// flatDoc is a stored document with its prepared vector.
+type flatDoc struct {
+	doc    Document
+	vector []float32
+}
+
+// NewFlat creates an empty exact index using the given distance.
+func NewFlat(distance Distance) *Flat {
+	return &Flat{
+		distance: distance,
+		docs:     make(map[string]flatDoc),
+	}
+}
+
+// Upsert implements the VectorIndex interface.
+func (f *Flat) Upsert(docs ...Document) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dimensions := f.dimensions
+	prepared := make([]flatDoc, len(docs))
+	for i, doc := range docs {
+		if doc.ID == "" {
+			return fmt.Errorf("document has empty ID")
+		}
+		doc.Vector = copyVector(doc.Vector)
+		vector, err := f.distance.prepare(doc.Vector, dimensions)
+		if err != nil {
+			return fmt.Errorf("document %s: %w", doc.ID, err)
+		}
+		dimensions = len(vector)
+		prepared[i] = flatDoc{doc: doc, vector: vector}
+	}
+
+	f.dimensions = dimensions
+	for _, p := range prepared {
+		f.docs[p.doc.ID] = p
+	}
+	return nil
+}
+
+// Delete implements the VectorIndex interface.
+func (f *Flat) Delete(ids ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range ids {
+		delete(f.docs, id)
+	}
+}
+
+// Get implements the VectorIndex interface.
+func (f *Flat) Get(id string) (Document, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	d, ok := f.docs[id]
+	return d.doc, ok
+}
+
+// Len implements the VectorIndex interface.
+func (f *Flat) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.docs)
+}
+
+// Clear implements the VectorIndex interface.
+func (f *Flat) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.docs = make(map[string]flatDoc)
+	f.dimensions = 0
+}
+
+// Search implements the VectorIndex interface.
+func (f *Flat) Search(query []float32, k int, filter Filter) []Result {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if k <= 0 || len(f.docs) == 0 {
+		return nil
+	}
+
+	q, err := f.distance.prepare(query, f.dimensions)
+	if err != nil {
+		return nil
+	}
+
+	distance := f.distance.distanceFunc()
+	results := make([]Result, 0, len(f.docs))
+	for _, d := range f.docs {
+		if filter != nil && !filter(d.doc.Metadata) {
+			continue
+		}
+		results = append(results, Result{
+			Document: d.doc,
+			Score:    f.distance.score(distance(q, d.vector)),
+		})
+	}
+	sortResults(results)
+
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// flatSnapshot is the serialized form of a Flat index.
+type flatSnapshot struct {
+	Version   int
+	Distance  Distance
+	Documents []Document
+}
+
+// Save writes the index to w in gob format.
+func (f *Flat) Save(w io.Writer) error {
+	f.mu.RLock()
+	snap := flatSnapshot{Version: 1, Distance: f.distance}
+	for _, d := range f.docs {
+		snap.Documents = append(snap.Documents, d.doc)
+	}
+	f.mu.RUnlock()
+
+	sort.Slice(snap.Documents, func(i, j int) bool {
+		return snap.Documents[i].ID < snap.Documents[j].ID
+	})
+
+	if err := gob.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("encode index: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the contents of the index with those read from r.
+func (f *Flat) Load(r io.Reader) error {
+	var snap flatSnapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("decode index: %w", err)
+	}
+	if snap.Version != 1 {
+		return fmt.Errorf("unsupported index version %d", snap.Version)
+	}
+
+	loaded := NewFlat(snap.Distance)
+	if err := loaded.Upsert(snap.Documents...); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.distance = loaded.distance
+	f.dimensions = loaded.dimensions
+	f.docs = loaded.docs
+	return nil
+}
+
+// SaveFile writes the index to path, replacing it atomically.
+func (f *Flat) SaveFile(path string) error {
+	return writeFileAtomic(path, f.Save)
+}
+
+// LoadFile replaces the contents of the index with those saved at path.
+func (f *Flat) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return f.Load(file)
+}
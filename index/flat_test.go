@@ -0,0 +1,113 @@
+package index
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestFlat_Distances(t *testing.T) {
+	docs := []Document{
+		{ID: "x", Vector: []float32{1, 0}},
+		{ID: "far-x", Vector: []float32{10, 0}},
+		{ID: "y", Vector: []float32{0, 1}},
+	}
+	query := []float32{2, 0.1}
+
+	tests := []struct {
+		distance Distance
+		wantTop  string
+	}{
+		{Cosine, "far-x"}, // ties with x on angle; breaks by ID
+		{DotProduct, "far-x"},
+		{Euclidean, "x"},
+	}
+
+	for _, tt := range tests {
+		idx := NewFlat(tt.distance)
+		if err := idx.Upsert(docs...); err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.distance, err)
+		}
+		results := idx.Search(query, 3, nil)
+		if len(results) != 3 || results[0].ID != tt.wantTop {
+			t.Errorf("%s: expected top result %q, got %v", tt.distance, tt.wantTop, results)
+		}
+	}
+}
+
+func TestFlat_EuclideanScore(t *testing.T) {
+	idx := NewFlat(Euclidean)
+	idx.Upsert(Document{ID: "a", Vector: []float32{3, 4}})
+
+	results := idx.Search([]float32{0, 0}, 1, nil)
+	if math.Abs(results[0].Score+5) > 1e-6 {
+		t.Errorf("Expected score -5, got %f", results[0].Score)
+	}
+}
+
+func TestFlat_FilterAndDelete(t *testing.T) {
+	idx := NewFlat(Cosine)
+	idx.Upsert(
+		Document{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]string{"user": "alice"}},
+		Document{ID: "b", Vector: []float32{1, 0.1}, Metadata: map[string]string{"user": "bob"}},
+	)
+
+	results := idx.Search([]float32{1, 0}, 2, MatchMetadata(map[string]string{"user": "bob"}))
+	if len(results) != 1 || results[0].ID != "b" {
+		t.Errorf("Expected only bob's document, got %v", results)
+	}
+
+	idx.Delete("a")
+	if _, ok := idx.Get("a"); ok || idx.Len() != 1 {
+		t.Error("Expected document to be deleted")
+	}
+}
+
+func TestFlat_DimensionMismatch(t *testing.T) {
+	idx := NewFlat(Cosine)
+	idx.Upsert(Document{ID: "a", Vector: []float32{1, 0}})
+
+	if err := idx.Upsert(Document{ID: "b", Vector: []float32{1, 0, 0}}); err == nil {
+		t.Error("Expected error for mismatched dimensions, got nil")
+	}
+	if err := idx.Upsert(Document{ID: "c"}); err == nil {
+		t.Error("Expected error for missing vector, got nil")
+	}
+	if results := idx.Search([]float32{1}, 1, nil); results != nil {
+		t.Errorf("Expected no results for mismatched query, got %v", results)
+	}
+}
+
+func TestFlat_CopiesVectors(t *testing.T) {
+	idx := NewFlat(Euclidean)
+	vector := []float32{1, 0}
+	idx.Upsert(Document{ID: "a", Vector: vector})
+	vector[0] = 100
+
+	if doc, _ := idx.Get("a"); doc.Vector[0] != 1 {
+		t.Errorf("Expected the stored vector unaffected by the caller, got %v", doc.Vector)
+	}
+	if results := idx.Search([]float32{1, 0}, 1, nil); len(results) != 1 || results[0].Score != 0 {
+		t.Errorf("Expected an exact match on the original vector, got %v", results)
+	}
+}
+
+func TestFlat_SaveAndLoad(t *testing.T) {
+	idx := NewFlat(DotProduct)
+	idx.Upsert(Document{ID: "a", Text: "alpha", Vector: []float32{1, 2}})
+
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatalf("Expected no error on Save, got %v", err)
+	}
+
+	loaded := NewFlat(Cosine)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Expected no error on Load, got %v", err)
+	}
+
+	results := loaded.Search([]float32{1, 1}, 1, nil)
+	if len(results) != 1 || results[0].Text != "alpha" || results[0].Score != 3 {
+		t.Errorf("Expected dot product index restored, got %v", results)
+	}
+}
@@ -0,0 +1,610 @@
+package index
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+)
+
+// HNSW is an approximate nearest neighbor index based on Hierarchical
+// Navigable Small World graphs (Malkov & Yashunin, 2016).
+// It is safe for concurrent use: searches run in parallel and writes are
+// serialized.
+//
+// Deleted and replaced documents are tombstoned rather than unlinked, so
+// the graph stays navigable. Call Compact to rebuild the graph once many
+// documents have been removed.
+type HNSW struct {
+	mu         sync.RWMutex
+	distance   Distance
+	dimensions int
+
+	m              int
+	efConstruction int
+	efSearch       int
+	levelMult      float64
+	rng            *rand.Rand
+
+	nodes      []*hnswNode
+	ids        map[string]int
+	entry      int
+	maxLevel   int
+	tombstones int
+}
+
+// hnswNode is a vertex in the graph.
+type hnswNode struct {
+	Doc       Document
+	Vector    []float32
+	Level     int
+	Neighbors [][]int
+	Deleted   bool
+}
+
+// NewHNSW creates an empty index using the given distance, with M=16,
+// efConstruction=200 and efSearch=64.
+func NewHNSW(distance Distance) *HNSW {
+	h := &HNSW{
+		distance: distance,
+		ids:      make(map[string]int),
+		entry:    -1,
+		rng:      rand.New(rand.NewSource(1)),
+	}
+	return h.WithM(16).WithEfConstruction(200).WithEfSearch(64)
+}
+
+// WithM sets the number of links created per node on each layer above the
+// base layer. The base layer allows twice as many.
+func (h *HNSW) WithM(m int) *HNSW {
+	if m < 2 {
+		m = 2
+	}
+	h.m = m
+	h.levelMult = 1 / math.Log(float64(m))
+	return h
+}
+
+// WithEfConstruction sets the candidate list size used while inserting.
+func (h *HNSW) WithEfConstruction(ef int) *HNSW {
+	h.efConstruction = ef
+	return h
+}
+
+// WithEfSearch sets the candidate list size used while searching.
+// Larger values improve recall at the cost of speed.
+func (h *HNSW) WithEfSearch(ef int) *HNSW {
+	h.efSearch = ef
+	return h
+}
+
+// WithSeed seeds the random level generator, for reproducible graphs.
+func (h *HNSW) WithSeed(seed int64) *HNSW {
+	h.rng = rand.New(rand.NewSource(seed))
+	return h
+}
+
+// Upsert implements the VectorIndex interface.
+func (h *HNSW) Upsert(docs ...Document) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	dimensions := h.dimensions
+	copied := make([]Document, len(docs))
+	vectors := make([][]float32, len(docs))
+	for i, doc := range docs {
+		if doc.ID == "" {
+			return fmt.Errorf("document has empty ID")
+		}
+		doc.Vector = copyVector(doc.Vector)
+		vector, err := h.distance.prepare(doc.Vector, dimensions)
+		if err != nil {
+			return fmt.Errorf("document %s: %w", doc.ID, err)
+		}
+		dimensions = len(vector)
+		copied[i] = doc
+		vectors[i] = vector
+	}
+
+	h.dimensions = dimensions
+	for i, doc := range copied {
+		h.remove(doc.ID)
+		h.insert(doc, vectors[i])
+	}
+	return nil
+}
+
+// Delete implements the VectorIndex interface.
+func (h *HNSW) Delete(ids ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, id := range ids {
+		h.remove(id)
+	}
+}
+
+// Get implements the VectorIndex interface.
+func (h *HNSW) Get(id string) (Document, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n, ok := h.ids[id]
+	if !ok {
+		return Document{}, false
+	}
+	return h.nodes[n].Doc, true
+}
+
+// Len implements the VectorIndex interface.
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.ids)
+}
+
+// Clear implements the VectorIndex interface.
+func (h *HNSW) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reset()
+	h.dimensions = 0
+}
+
+// Search implements the VectorIndex interface.
+// When filter rejects most documents the search visits more of the graph,
+// degrading towards an exhaustive scan rather than returning too few results.
+func (h *HNSW) Search(query []float32, k int, filter Filter) []Result {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if k <= 0 || len(h.ids) == 0 {
+		return nil
+	}
+
+	q, err := h.distance.prepare(query, h.dimensions)
+	if err != nil {
+		return nil
+	}
+
+	distance := h.distance.distanceFunc()
+	cur := h.entry
+	curDist := distance(q, h.nodes[cur].Vector)
+	for level := h.maxLevel; level > 0; level-- {
+		cur, curDist = h.greedyClosest(q, cur, curDist, level, distance)
+	}
+
+	accept := func(n int) bool {
+		node := h.nodes[n]
+		return !node.Deleted && (filter == nil || filter(node.Doc.Metadata))
+	}
+	found := h.searchLayer(q, cur, curDist, max(h.efSearch, k), 0, distance, accept)
+
+	if len(found) > k {
+		found = found[:k]
+	}
+	results := make([]Result, len(found))
+	for i, c := range found {
+		results[i] = Result{
+			Document: h.nodes[c.node].Doc,
+			Score:    h.distance.score(c.dist),
+		}
+	}
+	return results
+}
+
+// Compact rebuilds the graph without tombstoned nodes.
+func (h *HNSW) Compact() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rebuild()
+}
+
+// insert adds a node for doc. The caller must hold the write lock.
+func (h *HNSW) insert(doc Document, vector []float32) {
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	node := &hnswNode{
+		Doc:       doc,
+		Vector:    vector,
+		Level:     level,
+		Neighbors: make([][]int, level+1),
+	}
+	id := len(h.nodes)
+	h.nodes = append(h.nodes, node)
+	h.ids[doc.ID] = id
+
+	if h.entry < 0 {
+		h.entry = id
+		h.maxLevel = level
+		return
+	}
+
+	distance := h.distance.distanceFunc()
+	cur := h.entry
+	curDist := distance(vector, h.nodes[cur].Vector)
+	for l := h.maxLevel; l > level; l-- {
+		cur, curDist = h.greedyClosest(vector, cur, curDist, l, distance)
+	}
+
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vector, cur, curDist, h.efConstruction, l, distance, nil)
+		neighbors := h.selectNeighbors(candidates, h.maxLinks(l), distance)
+		node.Neighbors[l] = neighbors
+
+		for _, n := range neighbors {
+			h.link(n, id, l, distance)
+		}
+
+		cur, curDist = candidates[0].node, candidates[0].dist
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = id
+	}
+}
+
+// link adds a directed edge from n to target on level, pruning n's
+// neighbor list if it grows too long.
+func (h *HNSW) link(n, target, level int, distance func(a, b []float32) float32) {
+	node := h.nodes[n]
+	node.Neighbors[level] = append(node.Neighbors[level], target)
+
+	limit := h.maxLinks(level)
+	if len(node.Neighbors[level]) <= limit {
+		return
+	}
+
+	candidates := make([]hnswCandidate, len(node.Neighbors[level]))
+	for i, nb := range node.Neighbors[level] {
+		candidates[i] = hnswCandidate{node: nb, dist: distance(node.Vector, h.nodes[nb].Vector)}
+	}
+	sortCandidates(candidates)
+	node.Neighbors[level] = h.selectNeighbors(candidates, limit, distance)
+}
+
+// selectNeighbors picks up to m neighbors from candidates (sorted by
+// ascending distance) using the diversity heuristic from the HNSW paper,
+// then tops up with the closest discarded candidates.
+func (h *HNSW) selectNeighbors(candidates []hnswCandidate, m int, distance func(a, b []float32) float32) []int {
+	selected := make([]int, 0, m)
+	var discarded []int
+
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		diverse := true
+		for _, s := range selected {
+			if distance(h.nodes[c.node].Vector, h.nodes[s].Vector) < c.dist {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c.node)
+		} else {
+			discarded = append(discarded, c.node)
+		}
+	}
+
+	for _, d := range discarded {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, d)
+	}
+	return selected
+}
+
+// greedyClosest walks level from start towards the node closest to q.
+func (h *HNSW) greedyClosest(q []float32, start int, startDist float32, level int, distance func(a, b []float32) float32) (int, float32) {
+	cur, curDist := start, startDist
+	for changed := true; changed; {
+		changed = false
+		for _, nb := range h.nodes[cur].Neighbors[level] {
+			if d := distance(q, h.nodes[nb].Vector); d < curDist {
+				cur, curDist = nb, d
+				changed = true
+			}
+		}
+	}
+	return cur, curDist
+}
+
+// searchLayer runs a best-first search of level from the entry node and
+// returns up to ef accepted nodes sorted by ascending distance.
+// All nodes are traversed, but only those passing accept (every node when
+// accept is nil) are collected.
+func (h *HNSW) searchLayer(q []float32, entry int, entryDist float32, ef, level int, distance func(a, b []float32) float32, accept func(int) bool) []hnswCandidate {
+	visited := map[int]bool{entry: true}
+	candidates := &minHeap{{node: entry, dist: entryDist}}
+	results := &maxHeap{}
+	if accept == nil || accept(entry) {
+		heap.Push(results, hnswCandidate{node: entry, dist: entryDist})
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.dist > (*results)[0].dist {
+			break
+		}
+
+		for _, nb := range h.nodes[c.node].Neighbors[level] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+
+			d := distance(q, h.nodes[nb].Vector)
+			if results.Len() >= ef && d >= (*results)[0].dist {
+				continue
+			}
+			heap.Push(candidates, hnswCandidate{node: nb, dist: d})
+			if accept == nil || accept(nb) {
+				heap.Push(results, hnswCandidate{node: nb, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	found := make([]hnswCandidate, results.Len())
+	for i := len(found) - 1; i >= 0; i-- {
+		found[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return found
+}
+
+// remove tombstones the node for id. The caller must hold the write lock.
+func (h *HNSW) remove(id string) {
+	n, ok := h.ids[id]
+	if !ok {
+		return
+	}
+	h.nodes[n].Deleted = true
+	delete(h.ids, id)
+	h.tombstones++
+
+	// Keep the graph small once most of it is dead.
+	if h.tombstones > 64 && h.tombstones > len(h.ids) {
+		h.rebuild()
+	}
+}
+
+// rebuild reinserts all live nodes into a fresh graph.
+// The caller must hold the write lock.
+func (h *HNSW) rebuild() {
+	live := make([]*hnswNode, 0, len(h.ids))
+	for _, node := range h.nodes {
+		if !node.Deleted {
+			live = append(live, node)
+		}
+	}
+
+	h.reset()
+	for _, node := range live {
+		h.insert(node.Doc, node.Vector)
+	}
+}
+
+// reset empties the graph. The caller must hold the write lock.
+func (h *HNSW) reset() {
+	h.nodes = nil
+	h.ids = make(map[string]int)
+	h.entry = -1
+	h.maxLevel = 0
+	h.tombstones = 0
+}
+
+// maxLinks returns the neighbor list limit for level.
+func (h *HNSW) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * h.m
+	}
+	return h.m
+}
+
+// hnswSnapshot is the serialized form of an HNSW index.
+type hnswSnapshot struct {
+	Version        int
+	Distance       Distance
+	Dimensions     int
+	M              int
+	EfConstruction int
+	EfSearch       int
+	Nodes          []hnswSnapshotNode
+	Entry          int
+	MaxLevel       int
+}
+
+// hnswSnapshotNode is the serialized form of a node. Only the document's
+// vector is stored; the prepared vector is recomputed on load. Version 1
+// snapshots also stored the prepared vector, which gob skips when
+// decoding into this type.
+type hnswSnapshotNode struct {
+	Doc       Document
+	Level     int
+	Neighbors [][]int
+	Deleted   bool
+}
+
+// Save writes the index, including its graph, to w in gob format.
+func (h *HNSW) Save(w io.Writer) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snap := hnswSnapshot{
+		Version:        2,
+		Distance:       h.distance,
+		Dimensions:     h.dimensions,
+		M:              h.m,
+		EfConstruction: h.efConstruction,
+		EfSearch:       h.efSearch,
+		Nodes:          make([]hnswSnapshotNode, len(h.nodes)),
+		Entry:          h.entry,
+		MaxLevel:       h.maxLevel,
+	}
+	for i, node := range h.nodes {
+		snap.Nodes[i] = hnswSnapshotNode{
+			Doc:       node.Doc,
+			Level:     node.Level,
+			Neighbors: node.Neighbors,
+			Deleted:   node.Deleted,
+		}
+	}
+	if err := gob.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("encode index: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the contents of the index with those read from r.
+func (h *HNSW) Load(r io.Reader) error {
+	var snap hnswSnapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("decode index: %w", err)
+	}
+	if snap.Version != 1 && snap.Version != 2 {
+		return fmt.Errorf("unsupported index version %d", snap.Version)
+	}
+
+	nodes, err := snap.nodes()
+	if err != nil {
+		return fmt.Errorf("decode index: %w", err)
+	}
+	ids := make(map[string]int, len(nodes))
+	tombstones := 0
+	for i, node := range nodes {
+		if node.Deleted {
+			tombstones++
+			continue
+		}
+		if _, ok := ids[node.Doc.ID]; ok {
+			return fmt.Errorf("decode index: duplicate document %s", node.Doc.ID)
+		}
+		ids[node.Doc.ID] = i
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.distance = snap.Distance
+	h.dimensions = snap.Dimensions
+	h.WithM(snap.M)
+	h.efConstruction = snap.EfConstruction
+	h.efSearch = snap.EfSearch
+	h.nodes = nodes
+	h.ids = ids
+	h.entry = snap.Entry
+	h.maxLevel = snap.MaxLevel
+	h.tombstones = tombstones
+	return nil
+}
+
+// nodes validates the snapshot's graph and returns its nodes with their
+// prepared vectors, so that a damaged snapshot fails to load rather than
+// panicking in a later search.
+func (snap *hnswSnapshot) nodes() ([]*hnswNode, error) {
+	if len(snap.Nodes) == 0 {
+		if snap.Entry != -1 {
+			return nil, fmt.Errorf("entry point %d in an empty graph", snap.Entry)
+		}
+		return nil, nil
+	}
+	if snap.Dimensions <= 0 {
+		return nil, fmt.Errorf("bad dimensions %d", snap.Dimensions)
+	}
+	if snap.Entry < 0 || snap.Entry >= len(snap.Nodes) {
+		return nil, fmt.Errorf("entry point %d out of range", snap.Entry)
+	}
+	if snap.Nodes[snap.Entry].Level != snap.MaxLevel {
+		return nil, fmt.Errorf("entry point is on level %d, not the top level %d", snap.Nodes[snap.Entry].Level, snap.MaxLevel)
+	}
+
+	nodes := make([]*hnswNode, len(snap.Nodes))
+	for i, sn := range snap.Nodes {
+		if sn.Level < 0 || sn.Level > snap.MaxLevel || len(sn.Neighbors) != sn.Level+1 {
+			return nil, fmt.Errorf("node %d has a bad level", i)
+		}
+		for level, neighbors := range sn.Neighbors {
+			for _, nb := range neighbors {
+				if nb < 0 || nb >= len(snap.Nodes) || snap.Nodes[nb].Level < level {
+					return nil, fmt.Errorf("node %d has a bad neighbor %d on level %d", i, nb, level)
+				}
+			}
+		}
+		vector, err := snap.Distance.prepare(sn.Doc.Vector, snap.Dimensions)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %w", i, err)
+		}
+		nodes[i] = &hnswNode{
+			Doc:       sn.Doc,
+			Vector:    vector,
+			Level:     sn.Level,
+			Neighbors: sn.Neighbors,
+			Deleted:   sn.Deleted,
+		}
+	}
+	return nodes, nil
+}
+
+// SaveFile writes the index to path, replacing it atomically.
+func (h *HNSW) SaveFile(path string) error {
+	return writeFileAtomic(path, h.Save)
+}
+
+// LoadFile replaces the contents of the index with those saved at path.
+func (h *HNSW) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return h.Load(file)
+}
+
+// hnswCandidate is a node and its distance from the query.
+type hnswCandidate struct {
+	node int
+	dist float32
+}
+
+// sortCandidates orders candidates by ascending distance.
+func sortCandidates(c []hnswCandidate) {
+	sort.Slice(c, func(i, j int) bool { return c[i].dist < c[j].dist })
+}
+
+// minHeap is a heap of candidates with the closest on top.
+type minHeap []hnswCandidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(hnswCandidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// maxHeap is a heap of candidates with the farthest on top.
+type maxHeap []hnswCandidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].dist > h[j].dist }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(hnswCandidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
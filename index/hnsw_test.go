@@ -0,0 +1,262 @@
+package index
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func randomDocs(n, dims int, seed int64) []Document {
+	rng := rand.New(rand.NewSource(seed))
+	docs := make([]Document, n)
+	for i := range docs {
+		vec := make([]float32, dims)
+		for j := range vec {
+			vec[j] = rng.Float32()*2 - 1
+		}
+		docs[i] = Document{
+			ID:       fmt.Sprintf("doc-%d", i),
+			Vector:   vec,
+			Metadata: map[string]string{"shard": fmt.Sprint(i % 4)},
+		}
+	}
+	return docs
+}
+
+// recall returns the fraction of exact results found by the approximate ones.
+func recall(exact, approx []Result) float64 {
+	found := make(map[string]bool)
+	for _, r := range approx {
+		found[r.ID] = true
+	}
+	hits := 0
+	for _, r := range exact {
+		if found[r.ID] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(exact))
+}
+
+func TestHNSW_RecallAgainstFlat(t *testing.T) {
+	docs := randomDocs(500, 16, 1)
+	queries := randomDocs(20, 16, 2)
+
+	for _, distance := range []Distance{Cosine, DotProduct, Euclidean} {
+		flat := NewFlat(distance)
+		hnsw := NewHNSW(distance)
+		flat.Upsert(docs...)
+		hnsw.Upsert(docs...)
+
+		var total float64
+		for _, q := range queries {
+			total += recall(flat.Search(q.Vector, 10, nil), hnsw.Search(q.Vector, 10, nil))
+		}
+		if avg := total / float64(len(queries)); avg < 0.9 {
+			t.Errorf("%s: expected recall@10 >= 0.9, got %f", distance, avg)
+		}
+	}
+}
+
+func TestHNSW_Filter(t *testing.T) {
+	docs := randomDocs(500, 8, 3)
+	idx := NewHNSW(Cosine)
+	idx.Upsert(docs...)
+
+	filter := MatchMetadata(map[string]string{"shard": "2"})
+	results := idx.Search(docs[0].Vector, 10, filter)
+	if len(results) != 10 {
+		t.Fatalf("Expected 10 filtered results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Metadata["shard"] != "2" {
+			t.Errorf("Expected only shard 2, got %v", r.Metadata)
+		}
+	}
+}
+
+func TestHNSW_UpsertAndDelete(t *testing.T) {
+	docs := randomDocs(200, 8, 4)
+	idx := NewHNSW(Euclidean)
+	idx.Upsert(docs...)
+
+	idx.Delete("doc-0")
+	for _, r := range idx.Search(docs[0].Vector, 5, nil) {
+		if r.ID == "doc-0" {
+			t.Error("Expected deleted document not to be returned")
+		}
+	}
+
+	moved := Document{ID: "doc-1", Vector: docs[2].Vector}
+	idx.Upsert(moved)
+	if idx.Len() != 199 {
+		t.Errorf("Expected 199 documents, got %d", idx.Len())
+	}
+
+	results := idx.Search(docs[2].Vector, 2, nil)
+	ids := map[string]bool{results[0].ID: true, results[1].ID: true}
+	if !ids["doc-1"] || !ids["doc-2"] {
+		t.Errorf("Expected updated vector to be found, got %v", results)
+	}
+
+	// Deleting most documents triggers a rebuild; the rest stay searchable.
+	for i := 3; i < 180; i++ {
+		idx.Delete(fmt.Sprintf("doc-%d", i))
+	}
+	results = idx.Search(docs[190].Vector, 1, nil)
+	if len(results) != 1 || results[0].ID != "doc-190" {
+		t.Errorf("Expected exact match after deletes, got %v", results)
+	}
+
+	idx.Compact()
+	if idx.Len() != 22 {
+		t.Errorf("Expected 22 documents after compaction, got %d", idx.Len())
+	}
+}
+
+func TestHNSW_ConcurrentReaders(t *testing.T) {
+	docs := randomDocs(300, 8, 5)
+	idx := NewHNSW(Cosine)
+	idx.Upsert(docs[:200]...)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				idx.Search(docs[(i*50+j)%300].Vector, 5, nil)
+			}
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, doc := range docs[200:] {
+			idx.Upsert(doc)
+		}
+	}()
+	wg.Wait()
+
+	if idx.Len() != 300 {
+		t.Errorf("Expected 300 documents, got %d", idx.Len())
+	}
+}
+
+func TestHNSW_SaveFileAndLoadFile(t *testing.T) {
+	docs := randomDocs(300, 8, 6)
+	idx := NewHNSW(Cosine).WithM(8)
+	idx.Upsert(docs...)
+	idx.Delete("doc-5")
+
+	path := filepath.Join(t.TempDir(), "vectors.hnsw")
+	if err := idx.SaveFile(path); err != nil {
+		t.Fatalf("Expected no error on SaveFile, got %v", err)
+	}
+
+	loaded := NewHNSW(Euclidean)
+	if err := loaded.LoadFile(path); err != nil {
+		t.Fatalf("Expected no error on LoadFile, got %v", err)
+	}
+
+	if loaded.Len() != 299 {
+		t.Errorf("Expected 299 documents, got %d", loaded.Len())
+	}
+
+	want := idx.Search(docs[10].Vector, 5, nil)
+	got := loaded.Search(docs[10].Vector, 5, nil)
+	for i := range want {
+		if got[i].ID != want[i].ID {
+			t.Errorf("Result %d differs after load: %s vs %s", i, got[i].ID, want[i].ID)
+		}
+	}
+}
+
+func TestHNSW_CopiesVectors(t *testing.T) {
+	idx := NewHNSW(DotProduct)
+	vector := []float32{1, 0}
+	idx.Upsert(Document{ID: "a", Vector: vector})
+	vector[0] = -100
+
+	if results := idx.Search([]float32{1, 0}, 1, nil); len(results) != 1 || results[0].Score != 1 {
+		t.Errorf("Expected the stored vector unaffected by the caller, got %v", results)
+	}
+}
+
+func TestHNSW_SnapshotStoresVectorsOnce(t *testing.T) {
+	idx := NewHNSW(Cosine)
+	idx.Upsert(randomDocs(10, 4, 1)...)
+
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// Decode only the prepared vectors that version 1 also stored.
+	var snap struct {
+		Nodes []struct{ Vector []float32 }
+	}
+	if err := gob.NewDecoder(&buf).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	for i, node := range snap.Nodes {
+		if node.Vector != nil {
+			t.Fatalf("Expected node %d to store only its document vector", i)
+		}
+	}
+}
+
+func TestHNSW_LoadVersion1(t *testing.T) {
+	idx := NewHNSW(Euclidean).WithM(4)
+	docs := randomDocs(50, 4, 2)
+	idx.Upsert(docs...)
+
+	// Version 1 snapshots stored the nodes, prepared vectors included.
+	v1 := struct {
+		Version    int
+		Distance   Distance
+		Dimensions int
+		M          int
+		Nodes      []*hnswNode
+		Entry      int
+		MaxLevel   int
+	}{1, idx.distance, idx.dimensions, idx.m, idx.nodes, idx.entry, idx.maxLevel}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v1); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewHNSW(Cosine)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Expected a version 1 snapshot to load, got %v", err)
+	}
+	if got := loaded.Search(docs[3].Vector, 1, nil); len(got) != 1 || got[0].ID != "doc-3" {
+		t.Errorf("Expected doc-3, got %v", got)
+	}
+}
+
+func TestHNSW_LoadRejectsDamagedGraph(t *testing.T) {
+	idx := NewHNSW(Cosine)
+	idx.Upsert(randomDocs(20, 4, 3)...)
+
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var snap hnswSnapshot
+	if err := gob.NewDecoder(&buf).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	snap.Nodes[0].Neighbors[0][0] = len(snap.Nodes)
+	buf.Reset()
+	if err := gob.NewEncoder(&buf).Encode(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewHNSW(Cosine).Load(&buf); err == nil {
+		t.Error("Expected an out-of-range neighbor to fail the load")
+	}
+}
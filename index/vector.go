@@ -0,0 +1,151 @@
+package index
+
+import (
+	"fmt"
+	"math"
+)
+
+// Distance is the similarity function used by a vector index.
+type Distance int
+
+const (
+	// Cosine ranks by cosine similarity. Scores are in [-1, 1].
+	Cosine Distance = iota
+	// DotProduct ranks by inner product. Scores are the raw dot product.
+	DotProduct
+	// Euclidean ranks by L2 distance. Scores are the negated distance, so
+	// higher is still better.
+	Euclidean
+)
+
+// String returns the name of the distance.
+func (d Distance) String() string {
+	switch d {
+	case Cosine:
+		return "cosine"
+	case DotProduct:
+		return "dot"
+	case Euclidean:
+		return "l2"
+	default:
+		return fmt.Sprintf("Distance(%d)", int(d))
+	}
+}
+
+// Filter reports whether a document's metadata is acceptable to a search.
+// A nil Filter accepts every document.
+type Filter func(metadata map[string]string) bool
+
+// MatchMetadata returns a Filter accepting documents whose metadata contains
+// all the given key/value pairs.
+func MatchMetadata(match map[string]string) Filter {
+	return func(metadata map[string]string) bool {
+		for k, v := range match {
+			if metadata[k] != v {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// VectorIndex is an index of documents searchable by vector similarity.
+// Documents passed to Upsert must have a Vector.
+type VectorIndex interface {
+	// Upsert adds documents, replacing any with the same ID.
+	Upsert(docs ...Document) error
+	// Delete removes documents by ID. Unknown IDs are ignored.
+	Delete(ids ...string)
+	// Get returns the document with the given ID.
+	Get(id string) (Document, bool)
+	// Search returns the k documents most similar to query that pass filter,
+	// ordered by descending score.
+	Search(query []float32, k int, filter Filter) []Result
+	// Len returns the number of documents.
+	Len() int
+	// Clear removes all documents.
+	Clear()
+}
+
+// distanceFunc returns a lower-is-better distance between a and b.
+// For Cosine the vectors must already be normalized.
+func (d Distance) distanceFunc() func(a, b []float32) float32 {
+	switch d {
+	case DotProduct:
+		return func(a, b []float32) float32 { return -dot(a, b) }
+	case Euclidean:
+		return squaredL2
+	default:
+		return func(a, b []float32) float32 { return 1 - dot(a, b) }
+	}
+}
+
+// score converts a distance from distanceFunc to a higher-is-better score.
+func (d Distance) score(dist float32) float64 {
+	switch d {
+	case DotProduct:
+		return float64(-dist)
+	case Euclidean:
+		return -math.Sqrt(float64(dist))
+	default:
+		return float64(1 - dist)
+	}
+}
+
+// prepare validates a vector for insertion or search and, for Cosine,
+// returns a normalized copy. Other distances return vector itself.
+func (d Distance) prepare(vector []float32, dimensions int) ([]float32, error) {
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("empty vector")
+	}
+	if dimensions > 0 && len(vector) != dimensions {
+		return nil, fmt.Errorf("vector has %d dimensions, index has %d", len(vector), dimensions)
+	}
+	if d != Cosine {
+		return vector, nil
+	}
+	return normalize(vector), nil
+}
+
+// copyVector returns a copy of v, so that an index does not share the
+// caller's slice.
+func copyVector(v []float32) []float32 {
+	return append([]float32(nil), v...)
+}
+
+// dot returns the inner product of a and b.
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// squaredL2 returns the squared Euclidean distance between a and b.
+func squaredL2(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		diff := a[i] - b[i]
+		sum += diff * diff
+	}
+	return sum
+}
+
+// normalize returns a unit-length copy of v. Zero vectors are returned as-is.
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if norm == 0 {
+		copy(out, v)
+		return out
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i, x := range v {
+		out[i] = x * scale
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package index
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// VectorRetriever adapts a VectorIndex to the dspy.Retriever interface by
+// embedding queries with an llm.Embedder.
+type VectorRetriever struct {
+	Index    VectorIndex
+	Embedder llm.Embedder
+	Filter   Filter
+}
+
+// NewVectorRetriever creates a retriever over idx using embedder for queries.
+func NewVectorRetriever(idx VectorIndex, embedder llm.Embedder) *VectorRetriever {
+	return &VectorRetriever{
+		Index:    idx,
+		Embedder: embedder,
+	}
+}
+
+// WithFilter restricts retrieval to documents accepted by filter.
+func (r *VectorRetriever) WithFilter(filter Filter) *VectorRetriever {
+	r.Filter = filter
+	return r
+}
+
+// Retrieve implements the dspy.Retriever interface.
+func (r *VectorRetriever) Retrieve(ctx context.Context, query string, k int) ([]dspy.Passage, error) {
+	vectors, err := r.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("expected 1 query embedding, got %d", len(vectors))
+	}
+	return toPassages(r.Index.Search(vectors[0], k, r.Filter)), nil
+}
+
+// Upsert embeds the text of documents that have no vector and adds all
+// documents to the index.
+func (r *VectorRetriever) Upsert(ctx context.Context, docs ...Document) error {
+	var texts []string
+	var missing []int
+	for i, doc := range docs {
+		if len(doc.Vector) == 0 {
+			texts = append(texts, doc.Text)
+			missing = append(missing, i)
+		}
+	}
+
+	if len(texts) > 0 {
+		vectors, err := r.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("embed documents: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+		}
+		docs = append([]Document(nil), docs...)
+		for j, i := range missing {
+			docs[i].Vector = vectors[j]
+		}
+	}
+
+	return r.Index.Upsert(docs...)
+}
@@ -0,0 +1,37 @@
+package index
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+func TestVectorRetriever(t *testing.T) {
+	retriever := NewVectorRetriever(NewHNSW(Cosine), llm.NewHashEmbedder(64))
+	ctx := context.Background()
+
+	err := retriever.Upsert(ctx,
+		Document{ID: "go", Text: "Go has goroutines and channels", Metadata: map[string]string{"lang": "go"}},
+		Document{ID: "rust", Text: "Rust has ownership and borrowing", Metadata: map[string]string{"lang": "rust"}},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var r dspy.Retriever = retriever
+	passages, err := r.Retrieve(ctx, "goroutines and channels", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(passages) != 1 || passages[0].ID != "go" {
+		t.Errorf("Expected Go passage, got %v", passages)
+	}
+
+	passages, _ = retriever.WithFilter(MatchMetadata(map[string]string{"lang": "rust"})).
+		Retrieve(ctx, "goroutines and channels", 2)
+	if len(passages) != 1 || passages[0].ID != "rust" {
+		t.Errorf("Expected filtered Rust passage, got %v", passages)
+	}
+}
@@ -0,0 +1,303 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/supadev-ai/go-dspy/index"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// Metadata keys attached to every chunk.
+const (
+	MetaSource    = "source"
+	MetaChunk     = "chunk"
+	MetaStart     = "start"
+	MetaEnd       = "end"
+	MetaStartLine = "start_line"
+	MetaEndLine   = "end_line"
+	MetaHeading   = "heading"
+)
+
+// Source is a document to ingest.
+type Source struct {
+	// ID identifies the source, typically its path or URL. Chunk IDs are
+	// derived from it.
+	ID       string
+	Text     string
+	Metadata map[string]string
+}
+
+// Indexer is the write side of an index. index.BM25, index.Flat and
+// index.HNSW all implement it.
+type Indexer interface {
+	Upsert(docs ...index.Document) error
+}
+
+// Pruner is implemented by indexes that can look up and delete documents,
+// as index.BM25, index.Flat and index.HNSW do. When the Indexer is a Pruner,
+// Ingest deletes the chunks left over from a longer, earlier version of
+// each source.
+type Pruner interface {
+	Get(id string) (index.Document, bool)
+	Delete(ids ...string)
+}
+
+// Pipeline splits sources into chunks, optionally embeds them, and loads
+// them into an index.
+type Pipeline struct {
+	Indexer     Indexer
+	Splitter    Splitter
+	Embedder    llm.Embedder
+	BatchSize   int
+	Concurrency int
+}
+
+// NewPipeline creates a pipeline loading into indexer. By default the
+// splitter is chosen per source with SplitterFor, and chunks are not
+// embedded.
+func NewPipeline(indexer Indexer) *Pipeline {
+	return &Pipeline{
+		Indexer:     indexer,
+		BatchSize:   64,
+		Concurrency: 4,
+	}
+}
+
+// WithSplitter sets the splitter used for every source.
+func (p *Pipeline) WithSplitter(splitter Splitter) *Pipeline {
+	p.Splitter = splitter
+	return p
+}
+
+// WithEmbedder sets the embedder used to compute chunk vectors.
+// It is required for vector indexes.
+func (p *Pipeline) WithEmbedder(embedder llm.Embedder) *Pipeline {
+	p.Embedder = embedder
+	return p
+}
+
+// WithBatchSize sets how many chunks are embedded and indexed per call.
+func (p *Pipeline) WithBatchSize(n int) *Pipeline {
+	p.BatchSize = n
+	return p
+}
+
+// WithConcurrency sets how many embedding batches run at once.
+func (p *Pipeline) WithConcurrency(n int) *Pipeline {
+	p.Concurrency = n
+	return p
+}
+
+// Chunk splits sources into index documents with provenance metadata.
+// Chunk IDs have the form "<source ID>#<chunk number>".
+func (p *Pipeline) Chunk(sources ...Source) []index.Document {
+	var docs []index.Document
+	for _, src := range sources {
+		splitter := p.Splitter
+		if splitter == nil {
+			splitter = SplitterFor(src.ID)
+		}
+
+		for i, seg := range splitter.Split(src.Text) {
+			meta := make(map[string]string, len(src.Metadata)+7)
+			for k, v := range src.Metadata {
+				meta[k] = v
+			}
+			meta[MetaSource] = src.ID
+			meta[MetaChunk] = strconv.Itoa(i)
+			meta[MetaStart] = strconv.Itoa(seg.Start)
+			meta[MetaEnd] = strconv.Itoa(seg.End)
+			meta[MetaStartLine] = strconv.Itoa(strings.Count(src.Text[:seg.Start], "\n") + 1)
+			meta[MetaEndLine] = strconv.Itoa(strings.Count(src.Text[:seg.End], "\n") + 1)
+			if seg.Heading != "" {
+				meta[MetaHeading] = seg.Heading
+			}
+
+			docs = append(docs, index.Document{
+				ID:       fmt.Sprintf("%s#%d", src.ID, i),
+				Text:     seg.Text,
+				Metadata: meta,
+			})
+		}
+	}
+	return docs
+}
+
+// Ingest chunks, embeds and indexes sources, returning the number of chunks
+// indexed. Embedding batches run concurrently; the first error cancels the
+// remaining work. Chunks are only written to the index once all of them
+// have been embedded, so an embedding failure leaves the index unchanged.
+// Chunks are then written batch by batch: if the index rejects a batch,
+// the earlier batches stay written and their count is returned with the
+// error.
+//
+// Re-ingesting a source replaces its chunks by ID. If the source now has
+// fewer chunks, the old higher-numbered ones are deleted when the Indexer
+// is a Pruner, and are otherwise left in the index.
+func (p *Pipeline) Ingest(ctx context.Context, sources ...Source) (int, error) {
+	docs := p.Chunk(sources...)
+	if len(docs) == 0 {
+		p.prune(sources, docs)
+		return 0, nil
+	}
+
+	batchSize := p.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(docs)
+	}
+
+	if p.Embedder != nil {
+		if err := p.embed(ctx, docs, batchSize); err != nil {
+			return 0, err
+		}
+	}
+
+	for start := 0; start < len(docs); start += batchSize {
+		end := min(start+batchSize, len(docs))
+		if err := p.Indexer.Upsert(docs[start:end]...); err != nil {
+			return start, fmt.Errorf("index chunks: %w", err)
+		}
+	}
+	p.prune(sources, docs)
+	return len(docs), nil
+}
+
+// prune deletes the chunks of sources numbered past those in docs, if the
+// Indexer supports it. Chunk numbers are contiguous, so it stops at the
+// first missing one.
+func (p *Pipeline) prune(sources []Source, docs []index.Document) {
+	pruner, ok := p.Indexer.(Pruner)
+	if !ok {
+		return
+	}
+	chunks := make(map[string]int, len(sources))
+	for _, doc := range docs {
+		chunks[doc.Metadata[MetaSource]]++
+	}
+	var stale []string
+	for _, src := range sources {
+		for i := chunks[src.ID]; ; i++ {
+			id := fmt.Sprintf("%s#%d", src.ID, i)
+			if _, ok := pruner.Get(id); !ok {
+				break
+			}
+			stale = append(stale, id)
+		}
+	}
+	if len(stale) > 0 {
+		pruner.Delete(stale...)
+	}
+}
+
+// embed fills in the vectors of docs, embedding up to Concurrency batches
+// at a time.
+func (p *Pipeline) embed(ctx context.Context, docs []index.Document, batchSize int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for start := 0; start < len(docs); start += batchSize {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fail(ctx.Err())
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		batch := docs[start:min(start+batchSize, len(docs))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			texts := make([]string, len(batch))
+			for i, doc := range batch {
+				texts[i] = doc.Text
+			}
+
+			vectors, err := p.Embedder.Embed(ctx, texts)
+			if err != nil {
+				fail(fmt.Errorf("embed chunks: %w", err))
+				return
+			}
+			if len(vectors) != len(batch) {
+				fail(fmt.Errorf("expected %d embeddings, got %d", len(batch), len(vectors)))
+				return
+			}
+			for i := range batch {
+				batch[i].Vector = vectors[i]
+			}
+		}()
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// ReadFile reads the file at path as a Source whose ID is the path.
+func ReadFile(path string) (Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Source{}, err
+	}
+	return Source{ID: path, Text: string(data)}, nil
+}
+
+// ReadDir reads every regular file under root whose extension is in exts
+// (for example ".md"), or every file if exts is empty.
+func ReadDir(root string, exts ...string) ([]Source, error) {
+	var sources []Source
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(exts) > 0 && !hasExt(path, exts) {
+			return nil
+		}
+		src, err := ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, src)
+		return nil
+	})
+	return sources, err
+}
+
+// hasExt reports whether path has one of exts, ignoring case.
+func hasExt(path string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if strings.ToLower(e) == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/index"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// countingEmbedder records how many batches it embedded.
+type countingEmbedder struct {
+	llm.Embedder
+	batches int32
+	fail    bool
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	atomic.AddInt32(&c.batches, 1)
+	if c.fail {
+		return nil, errors.New("embedding service down")
+	}
+	return c.Embedder.Embed(ctx, texts)
+}
+
+func TestPipeline_Chunk(t *testing.T) {
+	pipeline := NewPipeline(index.NewBM25())
+
+	docs := pipeline.Chunk(Source{
+		ID:       "guide.md",
+		Text:     "# Guide\nHello.\n\n## Setup\nInstall it.",
+		Metadata: map[string]string{"owner": "docs"},
+	})
+
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d", len(docs))
+	}
+
+	meta := docs[1].Metadata
+	if docs[1].ID != "guide.md#1" || meta[MetaSource] != "guide.md" || meta[MetaChunk] != "1" {
+		t.Errorf("Unexpected chunk identity %s %v", docs[1].ID, meta)
+	}
+	if meta[MetaHeading] != "Guide > Setup" || meta[MetaStartLine] != "4" || meta[MetaEndLine] != "5" {
+		t.Errorf("Unexpected provenance %v", meta)
+	}
+	if meta["owner"] != "docs" {
+		t.Error("Expected source metadata to be copied to chunks")
+	}
+}
+
+func TestPipeline_IngestBM25(t *testing.T) {
+	idx := index.NewBM25()
+	n, err := NewPipeline(idx).Ingest(context.Background(),
+		Source{ID: "a.txt", Text: "Goroutines are cheap. Channels connect them."},
+		Source{ID: "b.txt", Text: "Rust has a borrow checker."},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 2 || idx.Len() != 2 {
+		t.Errorf("Expected 2 chunks indexed, got %d (index has %d)", n, idx.Len())
+	}
+
+	results := idx.Search("channels", 1)
+	if len(results) != 1 || results[0].Metadata[MetaSource] != "a.txt" {
+		t.Errorf("Expected chunk from a.txt, got %v", results)
+	}
+}
+
+func TestPipeline_IngestVectors(t *testing.T) {
+	idx := index.NewHNSW(index.Cosine)
+	embedder := &countingEmbedder{Embedder: llm.NewHashEmbedder(32)}
+
+	var sources []Source
+	for i := 0; i < 10; i++ {
+		sources = append(sources, Source{ID: filepath.Join("docs", string(rune('a'+i))+".txt"), Text: "Sentence one. Sentence two."})
+	}
+
+	n, err := NewPipeline(idx).
+		WithSplitter(SentenceSplitter{MaxSize: 15}).
+		WithEmbedder(embedder).
+		WithBatchSize(3).
+		WithConcurrency(2).
+		Ingest(context.Background(), sources...)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if n != 20 || idx.Len() != 20 {
+		t.Errorf("Expected 20 chunks indexed, got %d (index has %d)", n, idx.Len())
+	}
+	if embedder.batches != 7 {
+		t.Errorf("Expected 7 embedding batches, got %d", embedder.batches)
+	}
+}
+
+func TestPipeline_EmbedError(t *testing.T) {
+	idx := index.NewFlat(index.Cosine)
+	embedder := &countingEmbedder{Embedder: llm.NewHashEmbedder(8), fail: true}
+
+	_, err := NewPipeline(idx).WithEmbedder(embedder).WithBatchSize(1).
+		Ingest(context.Background(), Source{ID: "a", Text: "One. Two. Three."})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if idx.Len() != 0 {
+		t.Errorf("Expected index to be unchanged, got %d documents", idx.Len())
+	}
+}
+
+func TestPipeline_ReingestPrunesStaleChunks(t *testing.T) {
+	idx := index.NewBM25()
+	pipeline := NewPipeline(idx).WithSplitter(SentenceSplitter{MaxSize: 10})
+	ctx := context.Background()
+
+	if _, err := pipeline.Ingest(ctx, Source{ID: "a", Text: "One. Two. Three."}, Source{ID: "b", Text: "Other."}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pipeline.Ingest(ctx, Source{ID: "a", Text: "One."}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := idx.Get("a#1"); ok {
+		t.Error("Expected chunks past the new end of the source to be deleted")
+	}
+	if _, ok := idx.Get("a#0"); !ok || idx.Len() != 2 {
+		t.Errorf("Expected a#0 and the other source kept, got %d documents", idx.Len())
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.md"), []byte("# A"), 0o644)
+	os.WriteFile(filepath.Join(dir, "b.go"), []byte("package b"), 0o644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "sub", "c.md"), []byte("# C"), 0o644)
+
+	sources, err := ReadDir(dir, ".md")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sources) != 2 {
+		t.Errorf("Expected 2 Markdown sources, got %d", len(sources))
+	}
+}
@@ -0,0 +1,319 @@
+package ingest
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Segment is a span of a source text produced by a Splitter.
+type Segment struct {
+	Text string
+	// Start and End are byte offsets of Text in the source.
+	Start int
+	End   int
+	// Heading is the path of enclosing Markdown headings, such as
+	// "Install > Linux", or empty.
+	Heading string
+}
+
+// Splitter divides text into segments suitable for embedding and indexing.
+type Splitter interface {
+	Split(text string) []Segment
+}
+
+// FixedSplitter splits text into chunks of at most Size bytes, with
+// consecutive chunks sharing about Overlap bytes. Chunk ends are moved back
+// to whitespace where possible so that words are not cut in half.
+type FixedSplitter struct {
+	Size    int
+	Overlap int
+}
+
+// Split implements the Splitter interface.
+func (s FixedSplitter) Split(text string) []Segment {
+	size := s.Size
+	if size <= 0 {
+		size = 1000
+	}
+	overlap := s.Overlap
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var segments []Segment
+	start := 0
+	for start < len(text) {
+		end := min(start+size, len(text))
+		if end < len(text) {
+			if i := strings.LastIndexAny(text[start:end], " \t\n"); i > size/2 {
+				end = start + i
+			}
+			for end > start && !utf8.RuneStart(text[end]) {
+				end--
+			}
+			if end == start {
+				end = min(start+size, len(text))
+			}
+		}
+
+		if seg, ok := trimSegment(text, start, end); ok {
+			segments = append(segments, seg)
+		}
+		if end >= len(text) {
+			break
+		}
+
+		next := end - overlap
+		if next <= start {
+			next = end
+		}
+		for next < len(text) && !utf8.RuneStart(text[next]) {
+			next++
+		}
+		start = next
+	}
+	return segments
+}
+
+// sentenceEnd matches the end of a sentence or a paragraph break.
+var sentenceEnd = regexp.MustCompile(`[.!?]+["')\]]*\s+|\n\s*\n`)
+
+// SentenceSplitter groups whole sentences into chunks of at most MaxSize
+// bytes. Consecutive chunks repeat the last Overlap sentences of the
+// previous chunk. Sentences longer than MaxSize are split with a
+// FixedSplitter.
+type SentenceSplitter struct {
+	MaxSize int
+	Overlap int
+}
+
+// Split implements the Splitter interface.
+func (s SentenceSplitter) Split(text string) []Segment {
+	maxSize := s.MaxSize
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+	overlap := max(s.Overlap, 0)
+
+	var sentences []Segment
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		sentences = appendSentence(sentences, text, start, loc[1], maxSize)
+		start = loc[1]
+	}
+	sentences = appendSentence(sentences, text, start, len(text), maxSize)
+
+	var segments []Segment
+	for i := 0; i < len(sentences); {
+		j := i + 1
+		for j < len(sentences) && sentences[j].End-sentences[i].Start <= maxSize {
+			j++
+		}
+		if seg, ok := trimSegment(text, sentences[i].Start, sentences[j-1].End); ok {
+			segments = append(segments, seg)
+		}
+		if j >= len(sentences) {
+			break
+		}
+		i = max(j-overlap, i+1)
+	}
+	return segments
+}
+
+// appendSentence appends the sentence text[start:end], split further if it
+// exceeds maxSize.
+func appendSentence(sentences []Segment, text string, start, end, maxSize int) []Segment {
+	seg, ok := trimSegment(text, start, end)
+	if !ok {
+		return sentences
+	}
+	if seg.End-seg.Start <= maxSize {
+		return append(sentences, seg)
+	}
+	for _, part := range (FixedSplitter{Size: maxSize}).Split(seg.Text) {
+		part.Start += seg.Start
+		part.End += seg.Start
+		sentences = append(sentences, part)
+	}
+	return sentences
+}
+
+// headingLine matches an ATX Markdown heading.
+var headingLine = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// MarkdownSplitter splits Markdown at headings, so that each chunk belongs
+// to a single section and records its heading path. Sections longer than
+// MaxSize are split further with a SentenceSplitter. Headings inside fenced
+// code blocks are ignored.
+type MarkdownSplitter struct {
+	MaxSize int
+}
+
+// Split implements the Splitter interface.
+func (s MarkdownSplitter) Split(text string) []Segment {
+	var segments []Segment
+	var path []string
+	sectionStart := 0
+	heading := ""
+	inFence := false
+
+	flush := func(end int) {
+		section, ok := trimSegment(text, sectionStart, end)
+		if !ok {
+			return
+		}
+		for _, seg := range (SentenceSplitter{MaxSize: s.MaxSize}).Split(section.Text) {
+			seg.Start += section.Start
+			seg.End += section.Start
+			seg.Heading = heading
+			segments = append(segments, seg)
+		}
+	}
+
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(strings.TrimSpace(trimmed), "```") {
+			inFence = !inFence
+		}
+
+		if m := headingLine.FindStringSubmatch(trimmed); m != nil && !inFence {
+			flush(offset)
+			level := len(m[1])
+			if len(path) >= level {
+				path = path[:level-1]
+			}
+			for len(path) < level-1 {
+				path = append(path, "")
+			}
+			path = append(path, m[2])
+			heading = joinHeadings(path)
+			sectionStart = offset
+		}
+		offset += len(line)
+	}
+	flush(len(text))
+	return segments
+}
+
+// joinHeadings joins the non-empty headings in path.
+func joinHeadings(path []string) string {
+	var parts []string
+	for _, h := range path {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+// CodeSplitter splits source code into chunks of at most MaxSize bytes,
+// preferring to break before top-level declarations: non-indented lines
+// that follow a blank line. Blocks longer than MaxSize are split by lines.
+type CodeSplitter struct {
+	MaxSize int
+}
+
+// Split implements the Splitter interface.
+func (s CodeSplitter) Split(text string) []Segment {
+	maxSize := s.MaxSize
+	if maxSize <= 0 {
+		maxSize = 1500
+	}
+
+	// Find block boundaries.
+	var bounds []int
+	offset := 0
+	prevBlank := true
+	for _, line := range strings.SplitAfter(text, "\n") {
+		content := strings.TrimRight(line, "\r\n")
+		blank := strings.TrimSpace(content) == ""
+		if prevBlank && !blank && !strings.HasPrefix(content, " ") && !strings.HasPrefix(content, "\t") {
+			bounds = append(bounds, offset)
+		}
+		prevBlank = blank
+		offset += len(line)
+	}
+	bounds = append(bounds, len(text))
+	if bounds[0] != 0 {
+		bounds = append([]int{0}, bounds...)
+	}
+
+	// Merge blocks up to maxSize, splitting oversized blocks by lines.
+	var segments []Segment
+	start, prev := bounds[0], bounds[0]
+	for _, b := range bounds[1:] {
+		if b-start <= maxSize {
+			prev = b
+			continue
+		}
+		if prev > start {
+			if seg, ok := trimSegment(text, start, prev); ok {
+				segments = append(segments, seg)
+			}
+			start = prev
+		}
+		if b-start > maxSize {
+			segments = append(segments, splitLines(text, start, b, maxSize)...)
+			start = b
+		}
+		prev = b
+	}
+	if prev > start {
+		if seg, ok := trimSegment(text, start, prev); ok {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+// splitLines splits text[start:end] into runs of whole lines of at most
+// maxSize bytes. A single line longer than maxSize becomes its own chunk.
+func splitLines(text string, start, end, maxSize int) []Segment {
+	var segments []Segment
+	chunkStart := start
+	offset := start
+	for _, line := range strings.SplitAfter(text[start:end], "\n") {
+		if offset+len(line)-chunkStart > maxSize && offset > chunkStart {
+			if seg, ok := trimSegment(text, chunkStart, offset); ok {
+				segments = append(segments, seg)
+			}
+			chunkStart = offset
+		}
+		offset += len(line)
+	}
+	if seg, ok := trimSegment(text, chunkStart, end); ok {
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+// SplitterFor returns a splitter suited to the file at path, chosen by
+// extension: MarkdownSplitter for Markdown, CodeSplitter for common source
+// files and SentenceSplitter otherwise.
+func SplitterFor(path string) Splitter {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return MarkdownSplitter{}
+	case ".go", ".py", ".js", ".ts", ".java", ".rs", ".c", ".h", ".cc", ".cpp", ".rb", ".php", ".kt", ".swift", ".scala", ".cs", ".sh":
+		return CodeSplitter{}
+	default:
+		return SentenceSplitter{}
+	}
+}
+
+// trimSegment returns text[start:end] with surrounding whitespace removed,
+// or false if nothing remains.
+func trimSegment(text string, start, end int) (Segment, bool) {
+	raw := text[start:end]
+	trimmedLeft := strings.TrimLeft(raw, " \t\r\n")
+	start += len(raw) - len(trimmedLeft)
+	trimmed := strings.TrimRight(trimmedLeft, " \t\r\n")
+	end = start + len(trimmed)
+	if trimmed == "" {
+		return Segment{}, false
+	}
+	return Segment{Text: trimmed, Start: start, End: end}, true
+}
@@ -0,0 +1,149 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// checkOffsets verifies that each segment's text matches its offsets.
+func checkOffsets(t *testing.T, text string, segments []Segment) {
+	t.Helper()
+	for i, seg := range segments {
+		if text[seg.Start:seg.End] != seg.Text {
+			t.Errorf("Segment %d offsets [%d:%d] do not match its text %q", i, seg.Start, seg.End, seg.Text)
+		}
+	}
+}
+
+func TestFixedSplitter(t *testing.T) {
+	text := strings.Repeat("word ", 100)
+	segments := FixedSplitter{Size: 50, Overlap: 10}.Split(text)
+
+	if len(segments) < 10 {
+		t.Fatalf("Expected at least 10 segments, got %d", len(segments))
+	}
+	for _, seg := range segments {
+		if len(seg.Text) > 50 {
+			t.Errorf("Segment exceeds size: %d bytes", len(seg.Text))
+		}
+		if strings.HasPrefix(seg.Text, "ord") {
+			t.Errorf("Expected segments to start on word boundaries, got %q", seg.Text)
+		}
+	}
+	for i := 1; i < len(segments); i++ {
+		if segments[i].Start >= segments[i-1].End {
+			t.Errorf("Expected segment %d to overlap the previous one", i)
+		}
+	}
+	checkOffsets(t, text, segments)
+}
+
+func TestFixedSplitter_Unicode(t *testing.T) {
+	text := strings.Repeat("é", 30)
+	segments := FixedSplitter{Size: 7}.Split(text)
+
+	var joined string
+	for _, seg := range segments {
+		joined += seg.Text
+	}
+	if joined != text {
+		t.Errorf("Expected segments to reassemble the text without splitting runes")
+	}
+}
+
+func TestSentenceSplitter(t *testing.T) {
+	text := "Go is fast. Go is simple! Is Go fun? Yes.\n\nNew paragraph here."
+	segments := SentenceSplitter{MaxSize: 30}.Split(text)
+
+	want := []string{"Go is fast. Go is simple!", "Is Go fun? Yes.", "New paragraph here."}
+	if len(segments) != len(want) {
+		t.Fatalf("Expected %d segments, got %d: %v", len(want), len(segments), segments)
+	}
+	for i, w := range want {
+		if segments[i].Text != w {
+			t.Errorf("Segment %d = %q, want %q", i, segments[i].Text, w)
+		}
+	}
+	checkOffsets(t, text, segments)
+}
+
+func TestSentenceSplitter_Overlap(t *testing.T) {
+	text := "One. Two. Three. Four."
+	segments := SentenceSplitter{MaxSize: 10, Overlap: 1}.Split(text)
+
+	if len(segments) < 2 || !strings.HasPrefix(segments[1].Text, "Two.") {
+		t.Errorf("Expected second segment to repeat the last sentence, got %v", segments)
+	}
+
+	// A negative overlap is treated as none rather than skipping sentences.
+	negative := SentenceSplitter{MaxSize: 10, Overlap: -2}.Split(text)
+	none := SentenceSplitter{MaxSize: 10}.Split(text)
+	if !reflect.DeepEqual(negative, none) {
+		t.Errorf("Expected every sentence kept with a negative overlap, got %v", negative)
+	}
+}
+
+func TestMarkdownSplitter(t *testing.T) {
+	text := "# Guide\nIntro text.\n\n## Install\nRun go get.\n\n```\n# not a heading\n```\n\n### Linux\nUse apt.\n\n# FAQ\nAsk us."
+	segments := MarkdownSplitter{MaxSize: 200}.Split(text)
+
+	want := []struct {
+		heading string
+		prefix  string
+	}{
+		{"Guide", "# Guide"},
+		{"Guide > Install", "## Install"},
+		{"Guide > Install > Linux", "### Linux"},
+		{"FAQ", "# FAQ"},
+	}
+	if len(segments) != len(want) {
+		t.Fatalf("Expected %d segments, got %d: %v", len(want), len(segments), segments)
+	}
+	for i, w := range want {
+		if segments[i].Heading != w.heading || !strings.HasPrefix(segments[i].Text, w.prefix) {
+			t.Errorf("Segment %d = %q under %q, want prefix %q under %q", i, segments[i].Text, segments[i].Heading, w.prefix, w.heading)
+		}
+	}
+	if !strings.Contains(segments[1].Text, "# not a heading") {
+		t.Error("Expected headings in code fences to stay in their section")
+	}
+	checkOffsets(t, text, segments)
+}
+
+func TestCodeSplitter(t *testing.T) {
+	text := "package main\n\nimport \"fmt\"\n\nfunc a() {\n\tfmt.Println(1)\n\n\tfmt.Println(2)\n}\n\nfunc b() {\n\tfmt.Println(3)\n}\n"
+	segments := CodeSplitter{MaxSize: 50}.Split(text)
+
+	for _, seg := range segments {
+		if len(seg.Text) > 50 {
+			t.Errorf("Segment exceeds size: %q", seg.Text)
+		}
+	}
+
+	var funcA, funcB bool
+	for _, seg := range segments {
+		if strings.HasPrefix(seg.Text, "func a()") && strings.HasSuffix(seg.Text, "}") {
+			funcA = true
+		}
+		if strings.HasPrefix(seg.Text, "func b()") {
+			funcB = true
+		}
+	}
+	if !funcA || !funcB {
+		t.Errorf("Expected functions to be kept whole, got %v", segments)
+	}
+	checkOffsets(t, text, segments)
+}
+
+func TestSplitterFor(t *testing.T) {
+	if _, ok := SplitterFor("docs/README.md").(MarkdownSplitter); !ok {
+		t.Error("Expected MarkdownSplitter for .md files")
+	}
+	if _, ok := SplitterFor("main.go").(CodeSplitter); !ok {
+		t.Error("Expected CodeSplitter for .go files")
+	}
+	if _, ok := SplitterFor("notes.txt").(SentenceSplitter); !ok {
+		t.Error("Expected SentenceSplitter for other files")
+	}
+}
@@ -68,6 +68,13 @@ func (c *AnthropicClient) GenerateWithOptions(ctx context.Context, prompt string
 		model = c.defaultOpts.Model
 	}
 
+	return observe(ctx, "anthropic", model, prompt, opts, func(ctx context.Context) (callResult, error) {
+		return c.message(ctx, model, prompt, opts)
+	})
+}
+
+// message sends a single Messages API request.
+func (c *AnthropicClient) message(ctx context.Context, model, prompt string, opts *GenerateOptions) (callResult, error) {
 	reqBody := map[string]interface{}{
 		"model":     model,
 		"messages": []map[string]string{
@@ -83,12 +90,12 @@ func (c *AnthropicClient) GenerateWithOptions(ctx context.Context, prompt string
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return callResult{}, fmt.Errorf("marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/messages", bytes.NewReader(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return callResult{}, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -97,28 +104,42 @@ func (c *AnthropicClient) GenerateWithOptions(ctx context.Context, prompt string
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("execute request: %w", err)
+		return callResult{}, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return callResult{}, &ErrAPI{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var response struct {
+		ID      string `json:"id"`
+		Model   string `json:"model"`
 		Content []struct {
 			Text string `json:"text"`
 		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("decode response: %w", err)
+		return callResult{}, fmt.Errorf("decode response: %w", err)
 	}
 
 	if len(response.Content) == 0 {
-		return "", fmt.Errorf("no content in response")
+		return callResult{}, fmt.Errorf("no content in response")
 	}
 
-	return response.Content[0].Text, nil
+	return callResult{
+		Text:         response.Content[0].Text,
+		ID:           response.ID,
+		Model:        response.Model,
+		FinishReason: response.StopReason,
+		InputTokens:  response.Usage.InputTokens,
+		OutputTokens: response.Usage.OutputTokens,
+	}, nil
 }
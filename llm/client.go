@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"fmt"
+	"strconv"
 )
 
 // Client is the interface for LLM providers.
@@ -41,3 +42,19 @@ type ErrClientNotConfigured struct {
 func (e *ErrClientNotConfigured) Error() string {
 	return fmt.Sprintf("LLM client not configured: %s", e.Provider)
 }
+
+// ErrAPI is returned when a provider responds with a non-OK status.
+type ErrAPI struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ErrAPI) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// ErrorType reports the status code as the error type of failed requests
+// in metrics.
+func (e *ErrAPI) ErrorType() string {
+	return strconv.Itoa(e.StatusCode)
+}
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Embedder is the interface for providers that turn text into vectors.
+type Embedder interface {
+	// Embed returns one vector per input text, in the same order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedOptions provides configuration for embedding requests.
+type EmbedOptions struct {
+	// Model is the embedding model name.
+	Model string
+	// Dimensions requests vectors of this size from models that support
+	// shortening. Zero uses the model's native size.
+	Dimensions int
+	// BatchSize is the maximum number of texts sent per request.
+	BatchSize int
+}
+
+// embedInBatches splits texts into batches of at most batchSize, embeds each
+// with embed and concatenates the results in order.
+func embedInBatches(ctx context.Context, texts []string, batchSize int, embed func(context.Context, []string) ([][]float32, error)) ([][]float32, error) {
+	if batchSize <= 0 {
+		batchSize = len(texts)
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		end := min(start+batchSize, len(texts))
+		batch, err := embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(batch))
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// CosineSimilarity returns the cosine similarity of a and b.
+// It returns 0 if the vectors differ in length or either is all zeros.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{1, 0}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected 1.0 for identical vectors, got %f", got)
+	}
+
+	if got := CosineSimilarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("Expected 0.0 for orthogonal vectors, got %f", got)
+	}
+
+	if got := CosineSimilarity([]float32{1, 0}, []float32{1}); got != 0 {
+		t.Errorf("Expected 0.0 for mismatched lengths, got %f", got)
+	}
+}
+
+func TestHashEmbedder_Deterministic(t *testing.T) {
+	embedder := NewHashEmbedder(64)
+	ctx := context.Background()
+
+	first, err := embedder.Embed(ctx, []string{"hello world", ""})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, _ := NewHashEmbedder(64).Embed(ctx, []string{"Hello, world!"})
+
+	if len(first[0]) != 64 {
+		t.Fatalf("Expected 64 dimensions, got %d", len(first[0]))
+	}
+
+	if sim := CosineSimilarity(first[0], second[0]); math.Abs(sim-1) > 1e-6 {
+		t.Errorf("Expected identical vectors for same words, got similarity %f", sim)
+	}
+
+	for _, v := range first[1] {
+		if v != 0 {
+			t.Fatal("Expected zero vector for empty text")
+		}
+	}
+}
+
+func TestOpenAIClient_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("Expected /embeddings, got %s", r.URL.Path)
+		}
+
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "custom-embed" {
+			t.Errorf("Expected model 'custom-embed', got '%s'", req.Model)
+		}
+
+		// Return embeddings out of order to check reordering by index.
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key").
+		WithBaseURL(server.URL).
+		WithEmbeddingModel("custom-embed")
+
+	vectors, err := client.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Expected vectors ordered by index, got %v", vectors)
+	}
+}
+
+func TestOpenAIClient_Embed_NotConfigured(t *testing.T) {
+	var embedder Embedder = NewOpenAIClient("")
+
+	if _, err := embedder.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Error("Expected error for missing API key, got nil")
+	}
+}
+
+func TestOpenAIClient_Embed_Batching(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var req struct {
+			Input      []string `json:"input"`
+			Dimensions int      `json:"dimensions"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Dimensions != 2 {
+			t.Errorf("Expected dimensions 2, got %d", req.Dimensions)
+		}
+
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var resp struct {
+			Data []item `json:"data"`
+		}
+		for i, text := range req.Input {
+			resp.Data = append(resp.Data, item{Index: i, Embedding: []float32{float32(len(text)), 0}})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key").
+		WithBaseURL(server.URL).
+		WithEmbedOptions(&EmbedOptions{Model: "m", Dimensions: 2, BatchSize: 2})
+
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	vectors, err := client.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 batched requests, got %d", requests)
+	}
+
+	for i, text := range texts {
+		if vectors[i][0] != float32(len(text)) {
+			t.Errorf("Expected vector %d to match text %q, got %v", i, text, vectors[i])
+		}
+	}
+}
+
+func TestOpenAIClient_Embed_BadIndexes(t *testing.T) {
+	responses := map[string]string{
+		"duplicate":    `{"data":[{"index":0,"embedding":[1]},{"index":0,"embedding":[2]}]}`,
+		"out of range": `{"data":[{"index":0,"embedding":[1]},{"index":2,"embedding":[2]}]}`,
+		"missing":      `{"data":[{"index":0,"embedding":[1]}]}`,
+	}
+	for name, body := range responses {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		client := NewOpenAIClient("key").WithBaseURL(server.URL)
+		if _, err := client.Embed(context.Background(), []string{"a", "b"}); err == nil {
+			t.Errorf("Expected an error for a %s index, got nil", name)
+		}
+		server.Close()
+	}
+}
+
+func TestOpenAIClient_WithEmbedOptions(t *testing.T) {
+	opts := &EmbedOptions{Dimensions: 2, BatchSize: 2}
+	client := NewOpenAIClient("key").WithEmbedOptions(opts).WithEmbeddingModel("m")
+
+	if opts.Model != "" {
+		t.Errorf("Expected the caller's options to be copied, got model %q", opts.Model)
+	}
+	if client.embedOpts.Model != "m" || client.embedOpts.Dimensions != 2 {
+		t.Errorf("Unexpected options %+v", client.embedOpts)
+	}
+
+	client.WithEmbedOptions(nil)
+	if *client.embedOpts != *defaultOpenAIEmbedOptions() {
+		t.Errorf("Expected nil to restore the defaults, got %+v", client.embedOpts)
+	}
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// HashEmbedder is a deterministic, offline Embedder based on feature hashing.
+// Each lowercased word is hashed into one of Dimensions buckets with a
+// hash-derived sign, and the result is L2-normalized. Texts that share words
+// get similar vectors, which is enough for tests and small offline corpora.
+type HashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder creates a hashing embedder producing vectors of the given size.
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Dimensions returns the size of the vectors produced.
+func (e *HashEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Embed implements the Embedder interface.
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+// embed computes the hashed vector for a single text.
+func (e *HashEmbedder) embed(text string) []float32 {
+	vec := make([]float32, e.dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		h := fnv.New64a()
+		h.Write([]byte(word))
+		sum := h.Sum64()
+		idx := sum % uint64(e.dimensions)
+		if sum>>63 == 1 {
+			vec[idx]--
+		} else {
+			vec[idx]++
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec
+}
@@ -9,14 +9,16 @@ import (
 // MockClient is a test-friendly implementation of the Client interface.
 // It returns predictable responses based on the prompt.
 type MockClient struct {
-	responses map[string]string
+	responses       map[string]string
 	defaultResponse string
+	embeddings      map[string][]float32
+	embeddingDims   int
 }
 
 // NewMockClient creates a new mock client.
 func NewMockClient() *MockClient {
 	return &MockClient{
-		responses: make(map[string]string),
+		responses:       make(map[string]string),
 		defaultResponse: "This is a mock response.",
 	}
 }
@@ -33,6 +35,22 @@ func (m *MockClient) WithDefaultResponse(response string) *MockClient {
 	return m
 }
 
+// WithEmbedding sets a specific vector returned by Embed for a given text.
+func (m *MockClient) WithEmbedding(text string, vector []float32) *MockClient {
+	if m.embeddings == nil {
+		m.embeddings = make(map[string][]float32)
+	}
+	m.embeddings[text] = vector
+	return m
+}
+
+// WithEmbeddingDimensions sets the size of vectors generated by Embed for
+// texts without a configured embedding.
+func (m *MockClient) WithEmbeddingDimensions(dimensions int) *MockClient {
+	m.embeddingDims = dimensions
+	return m
+}
+
 // Generate implements the Client interface.
 func (m *MockClient) Generate(ctx context.Context, prompt string) (string, error) {
 	// Check for exact match
@@ -78,3 +96,20 @@ func (m *MockClient) GenerateWithOptions(ctx context.Context, prompt string, opt
 	// No response configured
 	return "", fmt.Errorf("no response configured for prompt: %s", prompt)
 }
+
+// Embed implements the Embedder interface.
+// Texts with a configured embedding return it; all others get a
+// deterministic hashed vector, so equal texts always embed equally.
+func (m *MockClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, err := NewHashEmbedder(m.embeddingDims).Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, text := range texts {
+		if vector, ok := m.embeddings[text]; ok {
+			vectors[i] = vector
+		}
+	}
+	return vectors, nil
+}
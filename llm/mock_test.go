@@ -101,3 +101,31 @@ func TestDefaultGenerateOptions(t *testing.T) {
 		t.Errorf("Expected max tokens 1000, got %d", opts.MaxTokens)
 	}
 }
+
+func TestMockClient_Embed(t *testing.T) {
+	client := NewMockClient().
+		WithEmbeddingDimensions(8).
+		WithEmbedding("fixed", []float32{1, 2, 3})
+
+	var embedder Embedder = client
+	ctx := context.Background()
+
+	vectors, err := embedder.Embed(ctx, []string{"fixed", "hello", "hello"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(vectors[0]) != 3 || vectors[0][2] != 3 {
+		t.Errorf("Expected configured embedding, got %v", vectors[0])
+	}
+
+	if len(vectors[1]) != 8 {
+		t.Errorf("Expected 8 dimensions, got %d", len(vectors[1]))
+	}
+
+	for i := range vectors[1] {
+		if vectors[1][i] != vectors[2][i] {
+			t.Fatal("Expected deterministic vectors for equal texts")
+		}
+	}
+}
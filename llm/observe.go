@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"time"
+
+	"github.com/supadev-ai/go-dspy/tracing"
+)
+
+// callResult is the outcome of a single model call made by a provider.
+type callResult struct {
+	Text         string
+	ID           string
+	Model        string
+	FinishReason string
+	InputTokens  int
+	OutputTokens int
+}
+
+// observe runs call, a request to system's model, inside a chat span and
+// records its metrics and usage.
+func observe(ctx context.Context, system, model, prompt string, opts *GenerateOptions, call func(context.Context) (callResult, error)) (string, error) {
+	req := tracing.ChatRequest{
+		System:        system,
+		Model:         model,
+		Temperature:   opts.Temperature,
+		MaxTokens:     opts.MaxTokens,
+		StopSequences: opts.Stop,
+		Prompt:        prompt,
+	}
+	start := time.Now()
+	ctx, span := tracing.StartChat(ctx, req)
+
+	res, err := call(ctx)
+
+	resp := tracing.ChatResponse{
+		ID:           res.ID,
+		Model:        res.Model,
+		InputTokens:  res.InputTokens,
+		OutputTokens: res.OutputTokens,
+		Completion:   res.Text,
+	}
+	if res.FinishReason != "" {
+		resp.FinishReasons = []string{res.FinishReason}
+	}
+	elapsed := time.Since(start)
+	span.End(resp, err)
+	tracing.RecordChat(ctx, req, resp, elapsed, err)
+
+	model = res.Model
+	if model == "" {
+		model = req.Model
+	}
+	UsageFromContext(ctx).Add(Usage{
+		Model:        model,
+		InputTokens:  res.InputTokens,
+		OutputTokens: res.OutputTokens,
+		Duration:     elapsed,
+	})
+	return res.Text, err
+}
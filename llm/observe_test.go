@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/supadev-ai/go-dspy/tracing"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		tracing.SetTracerProvider(nil)
+		tracing.SetCaptureContent(false)
+	})
+	return recorder
+}
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestOpenAIClient_GenerateSpan(t *testing.T) {
+	recorder := recordSpans(t)
+	tracing.SetCaptureContent(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o-mini-2024-07-18",
+			"choices":[{"message":{"content":"Paris"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":9,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key").WithBaseURL(server.URL)
+	out, err := client.GenerateWithOptions(context.Background(), "Capital of France?", &GenerateOptions{
+		Model:       "gpt-4o-mini",
+		Temperature: 0,
+		MaxTokens:   5,
+	})
+	if err != nil || out != "Paris" {
+		t.Fatalf("Expected Paris, got %q (%v)", out, err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "chat gpt-4o-mini" {
+		t.Fatalf("Expected one chat span, got %v", spans)
+	}
+	a := spanAttrs(spans[0])
+	if a[tracing.AttrGenAISystem].AsString() != "openai" ||
+		a[tracing.AttrGenAIResponseID].AsString() != "chatcmpl-1" ||
+		a[tracing.AttrGenAIResponseModel].AsString() != "gpt-4o-mini-2024-07-18" ||
+		a[tracing.AttrGenAIResponseFinishReasons].AsStringSlice()[0] != "stop" ||
+		a[tracing.AttrGenAIUsageInputTokens].AsInt64() != 9 ||
+		a[tracing.AttrGenAIUsageOutputTokens].AsInt64() != 1 {
+		t.Errorf("Unexpected attributes %v", spans[0].Attributes())
+	}
+	if len(spans[0].Events()) != 2 {
+		t.Errorf("Expected captured prompt and completion, got %v", spans[0].Events())
+	}
+}
+
+func TestAnthropicClient_GenerateSpan(t *testing.T) {
+	recorder := recordSpans(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"msg_1","model":"claude-3-haiku-20240307","stop_reason":"end_turn",
+			"content":[{"type":"text","text":"Paris"}],
+			"usage":{"input_tokens":11,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient("key").WithBaseURL(server.URL)
+	if _, err := client.GenerateWithOptions(context.Background(), "Capital of France?", &GenerateOptions{
+		Model:     "claude-3-haiku-20240307",
+		MaxTokens: 5,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	a := spanAttrs(recorder.Ended()[0])
+	if a[tracing.AttrGenAISystem].AsString() != "anthropic" ||
+		a[tracing.AttrGenAIResponseFinishReasons].AsStringSlice()[0] != "end_turn" ||
+		a[tracing.AttrGenAIUsageInputTokens].AsInt64() != 11 ||
+		a[tracing.AttrGenAIUsageOutputTokens].AsInt64() != 2 {
+		t.Errorf("Unexpected attributes %v", recorder.Ended()[0].Attributes())
+	}
+	if len(recorder.Ended()[0].Events()) != 0 {
+		t.Error("Expected no content captured by default")
+	}
+}
+
+func TestOpenAIClient_GenerateSpanError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key").WithBaseURL(server.URL)
+	if _, err := client.Generate(context.Background(), "hi"); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if status := recorder.Ended()[0].Status(); status.Code != codes.Error {
+		t.Errorf("Expected error status, got %v", status)
+	}
+}
+
+func TestOpenAIClient_GenerateMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	tracing.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer tracing.SetMeterProvider(nil)
+
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"Paris"}}],"usage":{"prompt_tokens":9,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key").WithBaseURL(server.URL)
+	ctx := tracing.WithLabels(context.Background(), tracing.AttrPredictor.String("QA"))
+	if _, err := client.Generate(ctx, "Capital of France?"); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	_, err := client.Generate(ctx, "Capital of France?")
+	var apiErr *ErrAPI
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected an ErrAPI with status 429, got %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]metricdata.Aggregation)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		found[m.Name] = m.Data
+	}
+
+	requests := found[tracing.MetricLLMRequests].(metricdata.Sum[int64]).DataPoints
+	if len(requests) != 1 || requests[0].Value != 2 {
+		t.Fatalf("Expected 2 requests, got %v", requests)
+	}
+	if v, _ := requests[0].Attributes.Value(tracing.AttrPredictor); v.AsString() != "QA" {
+		t.Errorf("Expected the predictor label, got %v", requests[0].Attributes)
+	}
+	errs := found[tracing.MetricLLMErrors].(metricdata.Sum[int64]).DataPoints
+	if v, _ := errs[0].Attributes.Value(tracing.AttrErrorType); len(errs) != 1 || v.AsString() != "429" {
+		t.Errorf("Expected one error of type 429, got %v", errs)
+	}
+}
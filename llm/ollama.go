@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// OllamaEmbedder implements the Embedder interface for an Ollama-compatible
+// local server, using its /api/embed endpoint.
+type OllamaEmbedder struct {
+	baseURL    string
+	httpClient *http.Client
+	opts       *EmbedOptions
+}
+
+// ollamaBatchSize is the default number of texts per request.
+const ollamaBatchSize = 32
+
+// NewOllamaEmbedder creates an embedder for the given model served by a
+// local Ollama instance.
+func NewOllamaEmbedder(model string) *OllamaEmbedder {
+	return &OllamaEmbedder{
+		baseURL: "http://localhost:11434",
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		opts: &EmbedOptions{
+			Model:     model,
+			BatchSize: ollamaBatchSize,
+		},
+	}
+}
+
+// WithBaseURL sets a custom base URL.
+func (e *OllamaEmbedder) WithBaseURL(url string) *OllamaEmbedder {
+	e.baseURL = url
+	return e
+}
+
+// WithTimeout sets a custom HTTP timeout.
+func (e *OllamaEmbedder) WithTimeout(timeout time.Duration) *OllamaEmbedder {
+	e.httpClient.Timeout = timeout
+	return e
+}
+
+// WithEmbedOptions sets the embedding options. The options are copied; an
+// empty Model keeps the current model, and nil restores the default batch
+// size.
+func (e *OllamaEmbedder) WithEmbedOptions(opts *EmbedOptions) *OllamaEmbedder {
+	copied := EmbedOptions{BatchSize: ollamaBatchSize}
+	if opts != nil {
+		copied = *opts
+	}
+	if copied.Model == "" {
+		copied.Model = e.opts.Model
+	}
+	e.opts = &copied
+	return e
+}
+
+// Embed implements the Embedder interface.
+// Texts are sent in batches of at most EmbedOptions.BatchSize.
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.opts.Model == "" {
+		return nil, &ErrClientNotConfigured{Provider: "Ollama"}
+	}
+
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	return embedInBatches(ctx, texts, e.opts.BatchSize, e.embedBatch)
+}
+
+// embedBatch embeds a single batch of texts.
+func (e *OllamaEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody := map[string]interface{}{
+		"model": e.opts.Model,
+		"input": texts,
+	}
+
+	if e.opts.Dimensions > 0 {
+		reqBody["dimensions"] = e.opts.Dimensions
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+"/api/embed", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &ErrAPI{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return response.Embeddings, nil
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllamaEmbedder_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("Expected /api/embed, got %s", r.URL.Path)
+		}
+
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" {
+			t.Errorf("Expected model 'nomic-embed-text', got '%s'", req.Model)
+		}
+
+		embeddings := make([][]float32, len(req.Input))
+		for i := range req.Input {
+			embeddings[i] = []float32{float32(i), 1}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": embeddings})
+	}))
+	defer server.Close()
+
+	embedder := NewOllamaEmbedder("nomic-embed-text").WithBaseURL(server.URL)
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(vectors) != 3 || vectors[2][0] != 2 {
+		t.Errorf("Expected 3 vectors in order, got %v", vectors)
+	}
+}
+
+func TestOllamaEmbedder_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	embedder := NewOllamaEmbedder("missing").WithBaseURL(server.URL)
+
+	if _, err := embedder.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Error("Expected error for server failure, got nil")
+	}
+}
+
+func TestOllamaEmbedder_NoModel(t *testing.T) {
+	if _, err := NewOllamaEmbedder("").Embed(context.Background(), []string{"a"}); err == nil {
+		t.Error("Expected error without a model, got nil")
+	}
+}
+
+func TestOllamaEmbedder_WithEmbedOptions(t *testing.T) {
+	opts := &EmbedOptions{BatchSize: 4}
+	embedder := NewOllamaEmbedder("nomic-embed-text").WithEmbedOptions(opts)
+	opts.BatchSize = 1
+
+	if embedder.opts.Model != "nomic-embed-text" || embedder.opts.BatchSize != 4 {
+		t.Errorf("Expected copied options keeping the model, got %+v", embedder.opts)
+	}
+
+	embedder.WithEmbedOptions(nil)
+	if embedder.opts.Model != "nomic-embed-text" || embedder.opts.BatchSize != ollamaBatchSize {
+		t.Errorf("Expected nil to restore the defaults, got %+v", embedder.opts)
+	}
+}
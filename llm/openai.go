@@ -12,10 +12,11 @@ import (
 
 // OpenAIClient implements the Client interface for OpenAI's API.
 type OpenAIClient struct {
-	apiKey     string
-	baseURL    string
-	httpClient *http.Client
+	apiKey      string
+	baseURL     string
+	httpClient  *http.Client
 	defaultOpts *GenerateOptions
+	embedOpts   *EmbedOptions
 }
 
 // NewOpenAIClient creates a new OpenAI client with the given API key.
@@ -31,6 +32,16 @@ func NewOpenAIClient(apiKey string) *OpenAIClient {
 			MaxTokens:   1000,
 			Model:       "gpt-3.5-turbo",
 		},
+		embedOpts: defaultOpenAIEmbedOptions(),
+	}
+}
+
+// defaultOpenAIEmbedOptions returns the options used by Embed unless
+// WithEmbedOptions is called.
+func defaultOpenAIEmbedOptions() *EmbedOptions {
+	return &EmbedOptions{
+		Model:     "text-embedding-3-small",
+		BatchSize: 100,
 	}
 }
 
@@ -52,6 +63,28 @@ func (c *OpenAIClient) WithDefaultOptions(opts *GenerateOptions) *OpenAIClient {
 	return c
 }
 
+// WithEmbeddingModel sets the model used by Embed.
+func (c *OpenAIClient) WithEmbeddingModel(model string) *OpenAIClient {
+	c.embedOpts.Model = model
+	return c
+}
+
+// WithEmbedOptions sets the options used by Embed. The options are
+// copied; an empty Model keeps the current model, and nil restores the
+// defaults.
+func (c *OpenAIClient) WithEmbedOptions(opts *EmbedOptions) *OpenAIClient {
+	if opts == nil {
+		c.embedOpts = defaultOpenAIEmbedOptions()
+		return c
+	}
+	copied := *opts
+	if copied.Model == "" {
+		copied.Model = c.embedOpts.Model
+	}
+	c.embedOpts = &copied
+	return c
+}
+
 // Generate implements the Client interface.
 func (c *OpenAIClient) Generate(ctx context.Context, prompt string) (string, error) {
 	return c.GenerateWithOptions(ctx, prompt, c.defaultOpts)
@@ -68,6 +101,13 @@ func (c *OpenAIClient) GenerateWithOptions(ctx context.Context, prompt string, o
 		model = c.defaultOpts.Model
 	}
 
+	return observe(ctx, "openai", model, prompt, opts, func(ctx context.Context) (callResult, error) {
+		return c.chat(ctx, model, prompt, opts)
+	})
+}
+
+// chat sends a single chat completion request.
+func (c *OpenAIClient) chat(ctx context.Context, model, prompt string, opts *GenerateOptions) (callResult, error) {
 	reqBody := map[string]interface{}{
 		"model": model,
 		"messages": []map[string]string{
@@ -83,12 +123,12 @@ func (c *OpenAIClient) GenerateWithOptions(ctx context.Context, prompt string, o
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return callResult{}, fmt.Errorf("marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return callResult{}, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -96,30 +136,126 @@ func (c *OpenAIClient) GenerateWithOptions(ctx context.Context, prompt string, o
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("execute request: %w", err)
+		return callResult{}, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return callResult{}, &ErrAPI{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var response struct {
+		ID      string `json:"id"`
+		Model   string `json:"model"`
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("decode response: %w", err)
+		return callResult{}, fmt.Errorf("decode response: %w", err)
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+		return callResult{}, fmt.Errorf("no choices in response")
+	}
+
+	return callResult{
+		Text:         response.Choices[0].Message.Content,
+		ID:           response.ID,
+		Model:        response.Model,
+		FinishReason: response.Choices[0].FinishReason,
+		InputTokens:  response.Usage.PromptTokens,
+		OutputTokens: response.Usage.CompletionTokens,
+	}, nil
+}
+
+// Embed implements the Embedder interface using the /embeddings endpoint.
+// Texts are sent in batches of at most EmbedOptions.BatchSize.
+func (c *OpenAIClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if c.apiKey == "" {
+		return nil, &ErrClientNotConfigured{Provider: "OpenAI"}
+	}
+
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	return embedInBatches(ctx, texts, c.embedOpts.BatchSize, c.embedBatch)
+}
+
+// embedBatch embeds a single batch of texts.
+func (c *OpenAIClient) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody := map[string]interface{}{
+		"model": c.embedOpts.Model,
+		"input": texts,
+	}
+
+	if c.embedOpts.Dimensions > 0 {
+		reqBody["dimensions"] = c.embedOpts.Dimensions
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/embeddings", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &ErrAPI{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Data))
+	}
+
+	// Each index must appear exactly once; with as many embeddings as
+	// texts, that leaves none missing.
+	vectors := make([][]float32, len(texts))
+	seen := make([]bool, len(texts))
+	for _, d := range response.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		if seen[d.Index] {
+			return nil, fmt.Errorf("duplicate embedding index %d", d.Index)
+		}
+		seen[d.Index] = true
+		vectors[d.Index] = d.Embedding
 	}
 
-	return response.Choices[0].Message.Content, nil
+	return vectors, nil
 }
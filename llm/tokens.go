@@ -0,0 +1,11 @@
+package llm
+
+import "unicode/utf8"
+
+// EstimateTokens returns a rough token count for text, assuming about four
+// characters per token as is typical for English with BPE tokenizers.
+// Use it for budgeting, not billing.
+func EstimateTokens(text string) int {
+	n := utf8.RuneCountInString(text)
+	return (n + 3) / 4
+}
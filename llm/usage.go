@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Usage is the token usage and time spent in model calls.
+type Usage struct {
+	// Model is the model that served the most recent call.
+	Model        string        `json:"model,omitempty"`
+	InputTokens  int           `json:"input_tokens"`
+	OutputTokens int           `json:"output_tokens"`
+	Calls        int           `json:"calls"`
+	Duration     time.Duration `json:"duration"`
+}
+
+// UsageTracker accumulates the Usage of the model calls made under a
+// context. Trackers nest: usage added to a tracker is also added to the
+// tracker that was on the context it was created from. It is safe for
+// concurrent use.
+type UsageTracker struct {
+	mu     sync.Mutex
+	usage  Usage
+	parent *UsageTracker
+}
+
+type usageKey struct{}
+
+// TrackUsage returns a context whose model calls add their usage to the
+// returned tracker, and to any tracker already on ctx. The built-in clients
+// report usage this way; other clients can call UsageFromContext and Add.
+func TrackUsage(ctx context.Context) (context.Context, *UsageTracker) {
+	t := &UsageTracker{parent: UsageFromContext(ctx)}
+	return context.WithValue(ctx, usageKey{}, t), t
+}
+
+// UsageFromContext returns the tracker attached to ctx, or nil if there is
+// none.
+func UsageFromContext(ctx context.Context) *UsageTracker {
+	t, _ := ctx.Value(usageKey{}).(*UsageTracker)
+	return t
+}
+
+// Add adds the usage of one or more calls to t and its parents. Calls
+// defaults to 1 when zero.
+func (t *UsageTracker) Add(u Usage) {
+	if u.Calls == 0 {
+		u.Calls = 1
+	}
+	for ; t != nil; t = t.parent {
+		t.add(u)
+	}
+}
+
+func (t *UsageTracker) add(u Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u.Model != "" {
+		t.usage.Model = u.Model
+	}
+	t.usage.InputTokens += u.InputTokens
+	t.usage.OutputTokens += u.OutputTokens
+	t.usage.Calls += u.Calls
+	t.usage.Duration += u.Duration
+}
+
+// Usage returns the usage accumulated so far. A nil tracker has none.
+func (t *UsageTracker) Usage() Usage {
+	if t == nil {
+		return Usage{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUsageTracker(t *testing.T) {
+	ctx, tracker := TrackUsage(context.Background())
+	if UsageFromContext(ctx) != tracker {
+		t.Fatal("Expected the tracker on the context")
+	}
+
+	tracker.Add(Usage{Model: "a", InputTokens: 3, OutputTokens: 1})
+	tracker.Add(Usage{InputTokens: 2, OutputTokens: 2})
+
+	got := tracker.Usage()
+	if got.Model != "a" || got.InputTokens != 5 || got.OutputTokens != 3 || got.Calls != 2 {
+		t.Errorf("Unexpected usage %+v", got)
+	}
+
+	// Clients may report usage without a tracker.
+	var none *UsageTracker
+	none.Add(Usage{InputTokens: 1})
+	if UsageFromContext(context.Background()) != nil || none.Usage() != (Usage{}) {
+		t.Error("Expected no usage without a tracker")
+	}
+}
+
+func TestUsageTracker_Nested(t *testing.T) {
+	ctx, pipeline := TrackUsage(context.Background())
+	inner, call := TrackUsage(ctx)
+	if UsageFromContext(inner) != call {
+		t.Fatal("Expected the innermost tracker on the context")
+	}
+
+	call.Add(Usage{InputTokens: 4, OutputTokens: 1})
+	pipeline.Add(Usage{InputTokens: 1})
+
+	if got := call.Usage(); got.InputTokens != 4 || got.Calls != 1 {
+		t.Errorf("Unexpected inner usage %+v", got)
+	}
+	if got := pipeline.Usage(); got.InputTokens != 5 || got.OutputTokens != 1 || got.Calls != 2 {
+		t.Errorf("Expected the inner usage forwarded to the outer tracker, got %+v", got)
+	}
+}
+
+func TestAnthropicClient_ReportsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"claude-3-haiku-20240307","content":[{"text":"hi"}],
+			"usage":{"input_tokens":7,"output_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	ctx, tracker := TrackUsage(context.Background())
+	client := NewAnthropicClient("key").WithBaseURL(server.URL)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Generate(ctx, "hello"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := tracker.Usage()
+	if got.Model != "claude-3-haiku-20240307" || got.InputTokens != 14 || got.OutputTokens != 4 || got.Calls != 2 || got.Duration <= 0 {
+		t.Errorf("Unexpected usage %+v", got)
+	}
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// ErrConflict is returned when a conditional write finds a key at a
+// different version than expected.
+var ErrConflict = errors.New("version conflict")
+
+// txnAttempts bounds how many times Update and Transact retry on conflict.
+const txnAttempts = 32
+
+// Write is a single change applied by VersionedStore.Commit.
+type Write struct {
+	Key   string
+	Value interface{}
+	// TTL overrides the store's default TTL when positive.
+	TTL time.Duration
+	// Delete removes the key instead of storing Value.
+	Delete bool
+}
+
+// VersionedStore is a Store whose entries carry versions, allowing
+// optimistic concurrency control. Every write to a key gives it a new
+// version, greater than any version the store has handed out before.
+// Version zero means the key does not exist or has expired.
+type VersionedStore interface {
+	Store
+
+	// GetVersion returns the value stored at key along with its version.
+	GetVersion(ctx context.Context, key string) (value interface{}, version uint64, err error)
+
+	// CompareAndSwap stores value at key only if the key is currently at
+	// version, and returns the new version. Pass version zero to require
+	// that the key does not exist. It returns an error wrapping ErrConflict
+	// if the versions differ.
+	CompareAndSwap(ctx context.Context, key string, version uint64, value interface{}) (uint64, error)
+
+	// Commit atomically applies writes if every key in expect is at the
+	// given version, and otherwise applies nothing and returns an error
+	// wrapping ErrConflict.
+	Commit(ctx context.Context, expect map[string]uint64, writes []Write) error
+}
+
+// PutIfAbsent stores value at key unless a live value is already there,
+// and reports whether it was stored.
+func PutIfAbsent(ctx context.Context, s VersionedStore, key string, value interface{}) (bool, error) {
+	_, err := s.CompareAndSwap(ctx, key, 0, value)
+	if errors.Is(err, ErrConflict) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// UpdateFunc computes a new value from the current one. found is false if
+// the key does not exist. Returning an error aborts the update.
+type UpdateFunc func(old interface{}, found bool) (interface{}, error)
+
+// Update atomically replaces the value at key with fn's result and returns
+// it. fn may be called several times if other writers modify the key
+// concurrently, so it should have no side effects.
+func Update(ctx context.Context, s VersionedStore, key string, fn UpdateFunc) (interface{}, error) {
+	for attempt := 0; attempt < txnAttempts; attempt++ {
+		old, version, err := s.GetVersion(ctx, key)
+		if err != nil && !isMissing(err) {
+			return nil, err
+		}
+
+		value, err := fn(old, version != 0)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = s.CompareAndSwap(ctx, key, version, value)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrConflict) {
+			return nil, err
+		}
+		runtime.Gosched()
+	}
+	return nil, fmt.Errorf("update %s: %w after %d attempts", key, ErrConflict, txnAttempts)
+}
+
+// Transact runs fn in an optimistic transaction over several keys. Reads
+// made through the Txn are recorded, writes are buffered, and both are
+// committed atomically when fn returns nil. If another writer changed a key
+// that fn read, the transaction is retried with fresh reads, so fn should
+// have no side effects. An error returned by fn aborts the transaction.
+func Transact(ctx context.Context, s VersionedStore, fn func(tx *Txn) error) error {
+	for attempt := 0; attempt < txnAttempts; attempt++ {
+		tx := &Txn{
+			store:  s,
+			reads:  make(map[string]uint64),
+			writes: make(map[string]int),
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		err := s.Commit(ctx, tx.reads, tx.ops)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+		runtime.Gosched()
+	}
+	return fmt.Errorf("transaction: %w after %d attempts", ErrConflict, txnAttempts)
+}
+
+// Txn is a transaction in progress, passed to the function given to
+// Transact. Reads see the transaction's own buffered writes.
+type Txn struct {
+	store  VersionedStore
+	reads  map[string]uint64
+	ops    []Write
+	writes map[string]int // index into ops by key
+}
+
+// Get returns the value at key, as written earlier in the transaction or
+// else as stored, and records the version read.
+func (tx *Txn) Get(ctx context.Context, key string) (interface{}, error) {
+	if i, ok := tx.writes[key]; ok {
+		if tx.ops[i].Delete {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return tx.ops[i].Value, nil
+	}
+
+	value, version, err := tx.store.GetVersion(ctx, key)
+	if err != nil && !isMissing(err) {
+		return nil, err
+	}
+	if _, ok := tx.reads[key]; !ok {
+		tx.reads[key] = version
+	}
+	return value, err
+}
+
+// Put buffers a write of value to key.
+func (tx *Txn) Put(key string, value interface{}) {
+	tx.write(Write{Key: key, Value: value})
+}
+
+// PutWithTTL buffers a write of value to key that expires after ttl.
+func (tx *Txn) PutWithTTL(key string, value interface{}, ttl time.Duration) {
+	tx.write(Write{Key: key, Value: value, TTL: ttl})
+}
+
+// Delete buffers the deletion of key.
+func (tx *Txn) Delete(key string) {
+	tx.write(Write{Key: key, Delete: true})
+}
+
+// write buffers w, replacing any earlier write to the same key.
+func (tx *Txn) write(w Write) {
+	if i, ok := tx.writes[w.Key]; ok {
+		tx.ops[i] = w
+		return
+	}
+	tx.writes[w.Key] = len(tx.ops)
+	tx.ops = append(tx.ops, w)
+}
+
+// isMissing reports whether err means the key has no live value.
+func isMissing(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrExpired)
+}
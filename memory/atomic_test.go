@@ -0,0 +1,285 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInMemoryStore_Versions(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	if _, v, err := store.GetVersion(ctx, "k"); !errors.Is(err, ErrNotFound) || v != 0 {
+		t.Fatalf("Expected version 0 for a missing key, got %d (%v)", v, err)
+	}
+
+	store.Put(ctx, "k", "a")
+	_, v1, _ := store.GetVersion(ctx, "k")
+	store.Put(ctx, "k", "b")
+	_, v2, _ := store.GetVersion(ctx, "k")
+	if v1 == 0 || v2 <= v1 {
+		t.Errorf("Expected increasing versions, got %d then %d", v1, v2)
+	}
+
+	// Recreating a deleted key must not reuse its old version.
+	store.Delete(ctx, "k")
+	store.Put(ctx, "k", "c")
+	if _, v3, _ := store.GetVersion(ctx, "k"); v3 <= v2 {
+		t.Errorf("Expected a fresh version after delete, got %d", v3)
+	}
+}
+
+func TestInMemoryStore_CompareAndSwap(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	v1, err := store.CompareAndSwap(ctx, "k", 0, "a")
+	if err != nil {
+		t.Fatalf("Expected create with version 0 to succeed, got %v", err)
+	}
+	if _, err := store.CompareAndSwap(ctx, "k", 0, "b"); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict creating an existing key, got %v", err)
+	}
+
+	v2, err := store.CompareAndSwap(ctx, "k", v1, "b")
+	if err != nil || v2 <= v1 {
+		t.Fatalf("Expected swap at current version, got %d (%v)", v2, err)
+	}
+	if _, err := store.CompareAndSwap(ctx, "k", v1, "c"); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for a stale version, got %v", err)
+	}
+	if v, _ := store.Get(ctx, "k"); v != "b" {
+		t.Errorf("Expected failed swaps to leave the value, got %v", v)
+	}
+}
+
+func TestInMemoryStore_PutIfAbsent(t *testing.T) {
+	clock := newFakeClock()
+	store := NewInMemoryStore()
+	store.now = clock.Now
+	ctx := context.Background()
+
+	if ok, err := store.PutIfAbsent(ctx, "k", "a"); !ok || err != nil {
+		t.Fatalf("Expected first PutIfAbsent to store, got %v (%v)", ok, err)
+	}
+	if ok, err := store.PutIfAbsent(ctx, "k", "b"); ok || err != nil {
+		t.Errorf("Expected second PutIfAbsent to be refused, got %v (%v)", ok, err)
+	}
+
+	// An expired key counts as absent.
+	store.PutWithTTL(ctx, "t", "old", time.Minute)
+	clock.Advance(2 * time.Minute)
+	if ok, _ := store.PutIfAbsent(ctx, "t", "new"); !ok {
+		t.Error("Expected PutIfAbsent to replace an expired key")
+	}
+}
+
+func TestInMemoryStore_UpdateConcurrent(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	increment := func(old interface{}, found bool) (interface{}, error) {
+		if !found {
+			return 1, nil
+		}
+		return old.(int) + 1, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := store.Update(ctx, "counter", increment); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := store.Get(ctx, "counter"); v != 400 {
+		t.Errorf("Expected no lost updates, got %v", v)
+	}
+}
+
+func TestInMemoryStore_UpdateAbort(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	store.Put(ctx, "k", "a")
+
+	abort := errors.New("abort")
+	_, err := store.Update(ctx, "k", func(interface{}, bool) (interface{}, error) {
+		return nil, abort
+	})
+	if !errors.Is(err, abort) {
+		t.Errorf("Expected fn's error, got %v", err)
+	}
+	if v, _ := store.Get(ctx, "k"); v != "a" {
+		t.Errorf("Expected aborted update to leave the value, got %v", v)
+	}
+}
+
+func TestTransact_Transfer(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	store.Put(ctx, "alice", 100)
+	store.Put(ctx, "bob", 0)
+
+	transfer := func(tx *Txn) error {
+		a, err := tx.Get(ctx, "alice")
+		if err != nil {
+			return err
+		}
+		b, err := tx.Get(ctx, "bob")
+		if err != nil {
+			return err
+		}
+		tx.Put("alice", a.(int)-1)
+		tx.Put("bob", b.(int)+1)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				if err := store.Transact(ctx, transfer); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	a, _ := store.Get(ctx, "alice")
+	b, _ := store.Get(ctx, "bob")
+	if a != 0 || b != 100 {
+		t.Errorf("Expected all transfers applied atomically, got alice=%v bob=%v", a, b)
+	}
+}
+
+func TestTransact_ReadYourWritesAndConflict(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	store.Put(ctx, "gone", "x")
+
+	attempts := 0
+	err := store.Transact(ctx, func(tx *Txn) error {
+		attempts++
+		if _, err := tx.Get(ctx, "k"); attempts == 1 && !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected missing key inside transaction, got %v", err)
+		}
+		tx.Put("k", "v")
+		if v, _ := tx.Get(ctx, "k"); v != "v" {
+			t.Errorf("Expected to read own write, got %v", v)
+		}
+		tx.Delete("gone")
+		if _, err := tx.Get(ctx, "gone"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected own delete to hide the key, got %v", err)
+		}
+
+		// Simulate a concurrent writer on the first attempt.
+		if attempts == 1 {
+			store.Put(ctx, "k", "other")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected one retry after a conflict, got %d attempts", attempts)
+	}
+	if v, _ := store.Get(ctx, "k"); v != "v" {
+		t.Errorf("Expected committed write, got %v", v)
+	}
+	if _, err := store.Get(ctx, "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected committed delete, got %v", err)
+	}
+}
+
+func TestInMemoryStore_CommitIsAllOrNothing(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	store.Put(ctx, "a", 1)
+	_, va, _ := store.GetVersion(ctx, "a")
+
+	err := store.Commit(ctx, map[string]uint64{"a": va, "b": 42}, []Write{
+		{Key: "a", Value: 2},
+		{Key: "b", Value: 2},
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected ErrConflict, got %v", err)
+	}
+	if v, _ := store.Get(ctx, "a"); v != 1 {
+		t.Errorf("Expected no writes applied, got a=%v", v)
+	}
+}
+
+func TestInMemoryStore_CommitKeepsItsOwnWrites(t *testing.T) {
+	store := NewBoundedStore(3, LFU)
+	ctx := context.Background()
+	for _, key := range []string{"x", "y", "z"} {
+		store.Put(ctx, key, key)
+		store.Get(ctx, key)
+	}
+
+	// Under LFU the first new write is the least used entry when the
+	// second is inserted.
+	err := store.Commit(ctx, nil, []Write{{Key: "a", Value: 1}, {Key: "b", Value: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if _, err := store.Get(ctx, key); err != nil {
+			t.Errorf("Expected %s kept, got %v", key, err)
+		}
+	}
+	if store.Len() != 3 || store.Stats().Evictions != 2 {
+		t.Errorf("Expected 2 evictions to make room, got %d entries and %+v", store.Len(), store.Stats())
+	}
+
+	err = store.Commit(ctx, nil, []Write{{Key: "c", Value: 1}, {Key: "d", Value: 1}, {Key: "e", Value: 1}, {Key: "f", Value: 1}})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Expected ErrTooLarge for more writes than fit, got %v", err)
+	}
+	if _, err := store.Get(ctx, "a"); err != nil {
+		t.Errorf("Expected nothing evicted by a failed commit, got %v", err)
+	}
+}
+
+func TestNamespace_Versioned(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	ns := store.Namespace("n")
+
+	if ok, err := PutIfAbsent(ctx, ns, "k", "a"); !ok || err != nil {
+		t.Fatalf("Expected PutIfAbsent through namespace, got %v (%v)", ok, err)
+	}
+	if v, _ := store.Get(ctx, "n:k"); v != "a" {
+		t.Errorf("Expected prefixed key, got %v", v)
+	}
+	err := Transact(ctx, ns, func(tx *Txn) error {
+		tx.Put("other", "b")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := store.Get(ctx, "n:other"); v != "b" {
+		t.Errorf("Expected transaction write under the namespace, got %v", v)
+	}
+
+	if _, err := NewNamespace(plainStore{NewInMemoryStore()}, "n").CompareAndSwap(ctx, "k", 0, 1); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for an unversioned base, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Codec converts values to and from bytes for stores that persist them.
+//
+// Stores decode into an interface{}, so the concrete types a codec returns
+// determine what Get yields: JSONCodec produces generic JSON values
+// (map[string]interface{}, []interface{}, float64, string, bool), while
+// GobCodec restores the original types as long as they are registered with
+// gob.Register.
+type Codec interface {
+	// Name identifies the codec, for example in persisted metadata.
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes values as JSON.
+type JSONCodec struct{}
+
+// Name implements the Codec interface.
+func (JSONCodec) Name() string { return "json" }
+
+// Marshal implements the Codec interface.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements the Codec interface.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec encodes values with encoding/gob. Values are encoded as
+// interfaces, so their concrete types must be registered with gob.Register,
+// except for basic types such as string, int and float64.
+type GobCodec struct{}
+
+// Name implements the Codec interface.
+func (GobCodec) Name() string { return "gob" }
+
+// Marshal implements the Codec interface.
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal implements the Codec interface. v may point to an interface{}
+// or to a variable of the encoded value's concrete type.
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	var decoded interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return err
+	}
+	return assign(v, decoded)
+}
+
+// assign stores value into the variable pointed to by target.
+func assign(target, value interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", target)
+	}
+	dst := ptr.Elem()
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	src := reflect.ValueOf(value)
+	if !src.Type().AssignableTo(dst.Type()) {
+		return fmt.Errorf("cannot decode %T into %s", value, dst.Type())
+	}
+	dst.Set(src)
+	return nil
+}
+
+// decodeError reports that the stored value of key could not be decoded
+// into the requested type.
+func decodeError(key string, err error) error {
+	return fmt.Errorf("decode %s: %w: %w", key, ErrTypeMismatch, err)
+}
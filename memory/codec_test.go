@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+type codecRecord struct {
+	Name  string
+	Count int
+}
+
+func init() {
+	gob.Register(codecRecord{})
+}
+
+func TestJSONCodec(t *testing.T) {
+	codec := JSONCodec{}
+	data, err := codec.Marshal(codecRecord{Name: "a", Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var generic interface{}
+	if err := codec.Unmarshal(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"Name": "a", "Count": float64(2)}
+	if !reflect.DeepEqual(generic, want) {
+		t.Errorf("Expected %v, got %v", want, generic)
+	}
+
+	var typed codecRecord
+	if err := codec.Unmarshal(data, &typed); err != nil || typed.Count != 2 {
+		t.Errorf("Expected typed decode, got %+v (%v)", typed, err)
+	}
+}
+
+func TestGobCodec(t *testing.T) {
+	codec := GobCodec{}
+	data, err := codec.Marshal(codecRecord{Name: "a", Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var generic interface{}
+	if err := codec.Unmarshal(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if generic != (codecRecord{Name: "a", Count: 2}) {
+		t.Errorf("Expected original type, got %#v", generic)
+	}
+
+	var typed codecRecord
+	if err := codec.Unmarshal(data, &typed); err != nil || typed.Name != "a" {
+		t.Errorf("Expected typed decode, got %+v (%v)", typed, err)
+	}
+
+	var wrong string
+	if err := codec.Unmarshal(data, &wrong); err == nil {
+		t.Error("Expected error decoding into the wrong type")
+	}
+}
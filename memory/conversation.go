@@ -0,0 +1,254 @@
+package memory
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+func init() {
+	gob.Register(ConversationRecord{})
+}
+
+// ConversationRecord is the stored form of a Conversation: a summary of
+// turns that have been folded away, followed by the remaining turns.
+type ConversationRecord struct {
+	Summary string         `json:"summary,omitempty"`
+	Turns   []dspy.Message `json:"turns"`
+}
+
+// Summarizer folds turns that have fallen out of a conversation's window
+// into its running summary, which is empty at first.
+type Summarizer interface {
+	Summarize(ctx context.Context, summary string, turns []dspy.Message) (string, error)
+}
+
+// SummarizerFunc adapts a function to the Summarizer interface.
+type SummarizerFunc func(ctx context.Context, summary string, turns []dspy.Message) (string, error)
+
+// Summarize implements the Summarizer interface.
+func (f SummarizerFunc) Summarize(ctx context.Context, summary string, turns []dspy.Message) (string, error) {
+	return f(ctx, summary, turns)
+}
+
+// errStale aborts a conversation update whose input has changed.
+var errStale = errors.New("conversation changed concurrently")
+
+// Conversation is a multi-turn chat history persisted under one key of a
+// Store. History returns a window of the most recent turns, bounded by
+// count and by tokens, ready to pass to a Predictor as a dspy.History
+// field. With a Summarizer, turns that leave the window are folded into a
+// summary as they are appended, so the stored record stays small.
+//
+// Appends use Update when the store is a VersionedStore, so concurrent
+// writers do not lose turns.
+type Conversation struct {
+	records     *Typed[ConversationRecord]
+	key         string
+	maxTurns    int
+	maxTokens   int
+	countTokens func(string) int
+	summarizer  Summarizer
+}
+
+// NewConversation returns the conversation stored at key in store.
+func NewConversation(store Store, key string) *Conversation {
+	return &Conversation{
+		records:     NewTyped[ConversationRecord](store).WithCodec(JSONCodec{}),
+		key:         key,
+		countTokens: llm.EstimateTokens,
+	}
+}
+
+// WithMaxTurns limits the window to the n most recent turns. Zero means
+// unlimited.
+func (c *Conversation) WithMaxTurns(n int) *Conversation {
+	c.maxTurns = n
+	return c
+}
+
+// WithMaxTokens limits the window, including the summary, to about n
+// tokens. The most recent turn is always included. Zero means unlimited.
+func (c *Conversation) WithMaxTokens(n int) *Conversation {
+	c.maxTokens = n
+	return c
+}
+
+// WithTokenCounter sets the function used to count tokens. The default is
+// llm.EstimateTokens.
+func (c *Conversation) WithTokenCounter(count func(string) int) *Conversation {
+	c.countTokens = count
+	return c
+}
+
+// WithSummarizer sets the hook that folds turns leaving the window into the
+// summary.
+func (c *Conversation) WithSummarizer(s Summarizer) *Conversation {
+	c.summarizer = s
+	return c
+}
+
+// Key returns the key the conversation is stored under.
+func (c *Conversation) Key() string {
+	return c.key
+}
+
+// Append adds turns to the end of the conversation, then summarizes turns
+// that no longer fit the window if a Summarizer is set.
+func (c *Conversation) Append(ctx context.Context, turns ...dspy.Message) error {
+	err := c.update(ctx, func(rec ConversationRecord) (ConversationRecord, error) {
+		all := make([]dspy.Message, 0, len(rec.Turns)+len(turns))
+		all = append(all, rec.Turns...)
+		return ConversationRecord{Summary: rec.Summary, Turns: append(all, turns...)}, nil
+	})
+	if err != nil {
+		return err
+	}
+	if c.summarizer == nil {
+		return nil
+	}
+	return c.Summarize(ctx)
+}
+
+// AppendTurn adds a single turn with the given role and content.
+func (c *Conversation) AppendTurn(ctx context.Context, role, content string) error {
+	return c.Append(ctx, dspy.Message{Role: role, Content: content})
+}
+
+// Record returns the stored conversation. A conversation that has never
+// been written is empty.
+func (c *Conversation) Record(ctx context.Context) (ConversationRecord, error) {
+	rec, err := c.records.Get(ctx, c.key)
+	if isMissing(err) {
+		return ConversationRecord{}, nil
+	}
+	return rec, err
+}
+
+// Turns returns every stored turn, oldest first. Turns already folded into
+// the summary are not included.
+func (c *Conversation) Turns(ctx context.Context) ([]dspy.Message, error) {
+	rec, err := c.Record(ctx)
+	return rec.Turns, err
+}
+
+// History returns the window of recent turns. If older turns have been
+// summarized, the summary comes first as a system message.
+func (c *Conversation) History(ctx context.Context) (dspy.History, error) {
+	rec, err := c.Record(ctx)
+	if err != nil {
+		return dspy.History{}, err
+	}
+
+	var messages []dspy.Message
+	if rec.Summary != "" {
+		messages = append(messages, summaryMessage(rec.Summary))
+	}
+	messages = append(messages, rec.Turns[c.windowStart(rec):]...)
+	return dspy.NewHistory(messages...), nil
+}
+
+// Summarize folds the turns outside the window into the summary using the
+// Summarizer, and removes them from the store. It does nothing if no
+// Summarizer is set or every turn fits the window.
+func (c *Conversation) Summarize(ctx context.Context) error {
+	if c.summarizer == nil {
+		return nil
+	}
+	rec, err := c.Record(ctx)
+	if err != nil {
+		return err
+	}
+	start := c.windowStart(rec)
+	if start == 0 {
+		return nil
+	}
+
+	older := rec.Turns[:start]
+	summary, err := c.summarizer.Summarize(ctx, rec.Summary, older)
+	if err != nil {
+		return err
+	}
+
+	err = c.update(ctx, func(cur ConversationRecord) (ConversationRecord, error) {
+		// Another writer may have summarized these turns already.
+		if cur.Summary != rec.Summary || len(cur.Turns) < start || !sameMessages(cur.Turns[:start], older) {
+			return cur, errStale
+		}
+		rest := make([]dspy.Message, len(cur.Turns)-start)
+		copy(rest, cur.Turns[start:])
+		return ConversationRecord{Summary: summary, Turns: rest}, nil
+	})
+	if errors.Is(err, errStale) {
+		return nil
+	}
+	return err
+}
+
+// Clear deletes the conversation.
+func (c *Conversation) Clear(ctx context.Context) error {
+	return c.records.Delete(ctx, c.key)
+}
+
+// update applies fn to the stored record, atomically if the store is
+// versioned and with a plain read and write otherwise.
+func (c *Conversation) update(ctx context.Context, fn func(ConversationRecord) (ConversationRecord, error)) error {
+	_, err := c.records.Update(ctx, c.key, func(old ConversationRecord, _ bool) (ConversationRecord, error) {
+		return fn(old)
+	})
+	if !errors.Is(err, ErrNotSupported) {
+		return err
+	}
+
+	rec, err := c.Record(ctx)
+	if err != nil {
+		return err
+	}
+	if rec, err = fn(rec); err != nil {
+		return err
+	}
+	return c.records.Put(ctx, c.key, rec)
+}
+
+// windowStart returns the index of the oldest turn in the window.
+func (c *Conversation) windowStart(rec ConversationRecord) int {
+	tokens := 0
+	if rec.Summary != "" {
+		tokens = c.countTokens(summaryMessage(rec.Summary).Content)
+	}
+
+	start := len(rec.Turns)
+	for start > 0 {
+		if c.maxTurns > 0 && len(rec.Turns)-start >= c.maxTurns {
+			break
+		}
+		t := c.countTokens(rec.Turns[start-1].Content)
+		if c.maxTokens > 0 && tokens+t > c.maxTokens && start < len(rec.Turns) {
+			break
+		}
+		tokens += t
+		start--
+	}
+	return start
+}
+
+// summaryMessage presents a summary as the first message of a history.
+func summaryMessage(summary string) dspy.Message {
+	return dspy.Message{Role: dspy.RoleSystem, Content: "Summary of the earlier conversation: " + summary}
+}
+
+// sameMessages reports whether a and b hold the same messages.
+func sameMessages(a, b []dspy.Message) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+func contents(messages []dspy.Message) []string {
+	out := make([]string, len(messages))
+	for i, m := range messages {
+		out[i] = m.Content
+	}
+	return out
+}
+
+func TestConversation_AppendAndWindowByCount(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	conv := NewConversation(store, "session:1").WithMaxTurns(2)
+
+	conv.AppendTurn(ctx, dspy.RoleUser, "one")
+	conv.AppendTurn(ctx, dspy.RoleAssistant, "two")
+	conv.AppendTurn(ctx, dspy.RoleUser, "three")
+
+	turns, err := conv.Turns(ctx)
+	if err != nil || len(turns) != 3 {
+		t.Fatalf("Expected all turns stored without a summarizer, got %v (%v)", turns, err)
+	}
+	history, _ := conv.History(ctx)
+	if got := strings.Join(contents(history.Messages), ","); got != "two,three" {
+		t.Errorf("Expected the two most recent turns, got %s", got)
+	}
+}
+
+func TestConversation_WindowByTokens(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	conv := NewConversation(store, "c").
+		WithTokenCounter(func(s string) int { return len(s) }).
+		WithMaxTokens(8)
+
+	conv.Append(ctx,
+		dspy.Message{Role: dspy.RoleUser, Content: "aaaa"},
+		dspy.Message{Role: dspy.RoleAssistant, Content: "bbb"},
+		dspy.Message{Role: dspy.RoleUser, Content: "cccc"},
+	)
+	history, _ := conv.History(ctx)
+	if got := strings.Join(contents(history.Messages), ","); got != "bbb,cccc" {
+		t.Errorf("Expected turns within 8 tokens, got %s", got)
+	}
+
+	// The latest turn is kept even when it alone exceeds the budget.
+	conv.AppendTurn(ctx, dspy.RoleAssistant, "a very long answer")
+	history, _ = conv.History(ctx)
+	if history.Len() != 1 {
+		t.Errorf("Expected only the latest turn, got %v", history.Messages)
+	}
+}
+
+func TestConversation_Summarizer(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	var calls [][]string
+	summarizer := SummarizerFunc(func(ctx context.Context, summary string, turns []dspy.Message) (string, error) {
+		calls = append(calls, contents(turns))
+		return strings.TrimPrefix(summary+"+"+strings.Join(contents(turns), "+"), "+"), nil
+	})
+	conv := NewConversation(store, "c").WithMaxTurns(2).WithSummarizer(summarizer)
+
+	for _, turn := range []string{"t1", "t2", "t3", "t4"} {
+		if err := conv.AppendTurn(ctx, dspy.RoleUser, turn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec, _ := conv.Record(ctx)
+	if rec.Summary != "t1+t2" || strings.Join(contents(rec.Turns), ",") != "t3,t4" {
+		t.Errorf("Expected older turns folded into the summary, got %+v", rec)
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected one summarizer call per overflowing append, got %v", calls)
+	}
+
+	history, _ := conv.History(ctx)
+	if history.Len() != 3 || history.Messages[0].Role != dspy.RoleSystem || !strings.Contains(history.Messages[0].Content, "t1+t2") {
+		t.Errorf("Expected summary first in history, got %v", history.Messages)
+	}
+}
+
+func TestConversation_ConcurrentAppends(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	conv := NewConversation(store, "c")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := conv.AppendTurn(ctx, dspy.RoleUser, fmt.Sprint(i)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if turns, _ := conv.Turns(ctx); len(turns) != 10 {
+		t.Errorf("Expected no lost turns, got %d", len(turns))
+	}
+}
+
+func TestConversation_PersistentStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.log")
+	store, err := OpenFileStore(path, JSONCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	conv := NewConversation(store, "c")
+	conv.AppendTurn(ctx, dspy.RoleUser, "hello")
+	conv.AppendTurn(ctx, dspy.RoleAssistant, "hi there")
+	store.Close()
+
+	reopened, err := OpenFileStore(path, JSONCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	history, err := NewConversation(reopened, "c").History(ctx)
+	if err != nil || history.String() != "User: hello\nAssistant: hi there" {
+		t.Errorf("Expected history to survive a restart, got %q (%v)", history.String(), err)
+	}
+
+	if err := NewConversation(reopened, "c").Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if turns, _ := NewConversation(reopened, "c").Turns(ctx); len(turns) != 0 {
+		t.Errorf("Expected cleared conversation, got %v", turns)
+	}
+}
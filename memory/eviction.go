@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"container/heap"
+	"reflect"
+	"unsafe"
+)
+
+// EvictionPolicy selects which entry a bounded store evicts first.
+type EvictionPolicy int
+
+const (
+	// LRU evicts the least recently used entry.
+	LRU EvictionPolicy = iota
+	// LFU evicts the least frequently used entry, breaking ties by recency.
+	LFU
+)
+
+// String returns the name of the policy.
+func (p EvictionPolicy) String() string {
+	switch p {
+	case LRU:
+		return "lru"
+	case LFU:
+		return "lfu"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionReason says why an entry was removed by the store itself.
+type EvictionReason int
+
+const (
+	// EvictedCapacity means the entry was evicted to stay within bounds.
+	EvictedCapacity EvictionReason = iota
+	// EvictedExpired means the entry's TTL elapsed.
+	EvictedExpired
+)
+
+// String returns the name of the reason.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictedCapacity:
+		return "capacity"
+	case EvictedExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionFunc is called with each entry a store evicts. It is called
+// without the store's lock held, so it may use the store.
+type EvictionFunc func(key string, value interface{}, reason EvictionReason)
+
+// Stats are cumulative counters describing a store's cache behavior.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+	Entries     int
+	// Bytes is the approximate size of all entries. It is only tracked
+	// when the store has a byte limit.
+	Bytes int64
+}
+
+// HitRate returns the fraction of lookups that found a live entry.
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// evictionHeap orders entries so that the next one to evict is on top.
+type evictionHeap struct {
+	policy  EvictionPolicy
+	entries []*entry
+}
+
+func (h *evictionHeap) Len() int { return len(h.entries) }
+
+func (h *evictionHeap) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	if h.policy == LFU && a.freq != b.freq {
+		return a.freq < b.freq
+	}
+	return a.tick < b.tick
+}
+
+func (h *evictionHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index = i
+	h.entries[j].index = j
+}
+
+func (h *evictionHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *evictionHeap) Pop() interface{} {
+	n := len(h.entries)
+	e := h.entries[n-1]
+	h.entries[n-1] = nil
+	h.entries = h.entries[:n-1]
+	e.index = -1
+	return e
+}
+
+var _ heap.Interface = (*evictionHeap)(nil)
+
+// ApproximateSize estimates the memory held by v in bytes by walking it
+// with reflection. Shared pointers are counted once. It is meant for
+// enforcing rough byte limits, not for exact accounting.
+func ApproximateSize(v interface{}) int64 {
+	if v == nil {
+		return 0
+	}
+	return sizeOf(reflect.ValueOf(v), make(map[uintptr]bool))
+}
+
+// sizeOf returns the approximate size of v, tracking visited pointers in seen.
+func sizeOf(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0
+	case reflect.String:
+		return int64(unsafe.Sizeof("")) + int64(v.Len())
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return int64(unsafe.Sizeof(uintptr(0)))
+		}
+		seen[v.Pointer()] = true
+		return int64(unsafe.Sizeof(uintptr(0))) + sizeOf(v.Elem(), seen)
+	case reflect.Interface:
+		return 2*int64(unsafe.Sizeof(uintptr(0))) + sizeOf(v.Elem(), seen)
+	case reflect.Slice:
+		if v.IsNil() {
+			return int64(v.Type().Size())
+		}
+		if seen[v.Pointer()] {
+			return int64(v.Type().Size())
+		}
+		seen[v.Pointer()] = true
+		fallthrough
+	case reflect.Array:
+		size := int64(v.Type().Size())
+		if v.Kind() == reflect.Slice {
+			size += int64(v.Len()) * int64(v.Type().Elem().Size())
+		}
+		if hasPointers(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				size += sizeOf(v.Index(i), seen) - int64(v.Type().Elem().Size())
+			}
+		}
+		return size
+	case reflect.Map:
+		size := int64(v.Type().Size())
+		if v.IsNil() || seen[v.Pointer()] {
+			return size
+		}
+		seen[v.Pointer()] = true
+		iter := v.MapRange()
+		for iter.Next() {
+			size += sizeOf(iter.Key(), seen) + sizeOf(iter.Value(), seen)
+		}
+		return size
+	case reflect.Struct:
+		size := int64(v.Type().Size())
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if hasPointers(f.Type()) {
+				size += sizeOf(f, seen) - int64(f.Type().Size())
+			}
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
+
+// hasPointers reports whether values of t may reference other memory.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Struct, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
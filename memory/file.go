@@ -0,0 +1,556 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileMagic starts every FileStore log and snapshot.
+var fileMagic = []byte("GDSPYMEM1\n")
+
+// Log record operations.
+const (
+	opPut    byte = 1
+	opDelete byte = 2
+)
+
+// ErrCorrupt is returned when a store file has a damaged record that is
+// followed by more data, so it cannot be a torn final write.
+var ErrCorrupt = errors.New("memory store file is corrupt")
+
+// crcTable is used to checksum log records.
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// fileEntry is the latest encoded value of a key.
+type fileEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// FileStore is a durable Store backed by an append-only log file.
+//
+// Every write appends a checksummed record and, unless disabled with
+// WithSync, fsyncs the file before returning, so acknowledged writes
+// survive crashes. On open the log is replayed; a torn record at the end,
+// left by a crash mid-write, is discarded, while a damaged record anywhere
+// else fails the open with ErrCorrupt. A failed write is truncated away so
+// that later records stay readable. The log is compacted, by
+// rewriting only live entries to a new file and renaming it into place,
+// once it holds more dead records than live ones.
+//
+// Values are encoded with a Codec, and Get returns the decoded value, so
+// what is read back depends on the codec, both before and after a restart.
+// All keys and encoded values are kept in memory. A FileStore must not be
+// opened by more than one process at a time.
+type FileStore struct {
+	mu      sync.RWMutex
+	path    string
+	file    *os.File
+	codec   Codec
+	sync    bool
+	minDead int
+	entries map[string]fileEntry
+	dead    int
+	now     func() time.Time
+	closed  bool
+	// broken is set when a failed write could not be rolled back; writes
+	// fail with it until compaction rewrites the log.
+	broken error
+}
+
+// OpenFileStore opens the store logged at path, creating it if needed.
+func OpenFileStore(path string, codec Codec) (*FileStore, error) {
+	s := &FileStore{
+		path:    path,
+		codec:   codec,
+		sync:    true,
+		minDead: 1000,
+		entries: make(map[string]fileEntry),
+		now:     time.Now,
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	valid, err := s.replay(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	if valid == 0 {
+		if _, err := f.Write(fileMagic); err != nil {
+			f.Close()
+			return nil, err
+		}
+		valid = int64(len(fileMagic))
+	}
+	// Drop any torn record left by a crash.
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s.file = f
+	return s, nil
+}
+
+// WithSync sets whether each write is fsynced before returning. Disabling
+// it trades durability of the latest writes for throughput.
+func (s *FileStore) WithSync(sync bool) *FileStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sync = sync
+	return s
+}
+
+// WithCompactionThreshold sets the minimum number of dead records before
+// automatic compaction is considered. Zero disables automatic compaction.
+func (s *FileStore) WithCompactionThreshold(n int) *FileStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minDead = n
+	return s
+}
+
+// Put implements the Store interface.
+func (s *FileStore) Put(ctx context.Context, key string, value interface{}) error {
+	return s.PutWithTTL(ctx, key, value, 0)
+}
+
+// PutWithTTL implements the TTLStore interface.
+func (s *FileStore) PutWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := s.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := fileEntry{data: data}
+	if ttl > 0 {
+		e.expiresAt = s.now().Add(ttl)
+	}
+	if err := s.appendLocked(opPut, key, e); err != nil {
+		return err
+	}
+	if _, ok := s.entries[key]; ok {
+		s.dead++
+	}
+	s.entries[key] = e
+	return s.maybeCompactLocked()
+}
+
+// Get implements the Store interface.
+func (s *FileStore) Get(ctx context.Context, key string) (interface{}, error) {
+	var value interface{}
+	if err := s.GetInto(ctx, key, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetInto decodes the value of key into the variable pointed to by target,
+// letting the codec produce a concrete type instead of a generic one.
+func (s *FileStore) GetInto(ctx context.Context, key string, target interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	e, ok := s.entries[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if !e.expiresAt.IsZero() && !s.now().Before(e.expiresAt) {
+		return fmt.Errorf("%w: %s", ErrExpired, key)
+	}
+	if err := s.codec.Unmarshal(e.data, target); err != nil {
+		return decodeError(key, err)
+	}
+	return nil
+}
+
+// Delete implements the Store interface.
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+	if err := s.appendLocked(opDelete, key, fileEntry{}); err != nil {
+		return err
+	}
+	delete(s.entries, key)
+	// Both the old value and the tombstone are now dead.
+	s.dead += 2
+	return s.maybeCompactLocked()
+}
+
+// Clear implements the Store interface.
+func (s *FileStore) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]fileEntry)
+	return s.compactLocked()
+}
+
+// Len returns the number of stored keys, including expired keys that have
+// not been compacted away.
+func (s *FileStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// Compact rewrites the log with only live, unexpired entries.
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compactLocked()
+}
+
+// Snapshot writes all live entries to w in the log format. The snapshot
+// can be loaded with Restore, or opened directly as a store file.
+func (s *FileStore) Snapshot(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.writeLiveLocked(w)
+}
+
+// Restore replaces the contents of the store with a snapshot read from r.
+// The snapshot is fully validated before anything is replaced.
+func (s *FileStore) Restore(r io.Reader) error {
+	restored := &FileStore{entries: make(map[string]fileEntry), now: s.now}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	valid, err := restored.replay(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	if valid != int64(len(data)) {
+		return fmt.Errorf("restore: snapshot is truncated or corrupt at byte %d", valid)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = restored.entries
+	return s.compactLocked()
+}
+
+// Close closes the log file. The store cannot be used afterwards.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.file.Close()
+}
+
+// errClosed is returned when a closed FileStore is written to.
+var errClosed = errors.New("file store is closed")
+
+// appendLocked writes one record to the log. If the write fails, the log
+// is truncated back to where the record started. The caller must hold the
+// write lock.
+func (s *FileStore) appendLocked(op byte, key string, e fileEntry) error {
+	if s.closed {
+		return errClosed
+	}
+	if s.broken != nil {
+		return s.broken
+	}
+	offset, err := s.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("write log: %w", err)
+	}
+	if _, err := s.file.Write(encodeRecord(op, key, e)); err != nil {
+		s.rollbackLocked(offset)
+		return fmt.Errorf("write log: %w", err)
+	}
+	if s.sync {
+		if err := s.file.Sync(); err != nil {
+			s.rollbackLocked(offset)
+			return fmt.Errorf("sync log: %w", err)
+		}
+	}
+	return nil
+}
+
+// rollbackLocked removes a partially written record starting at offset.
+// If that fails, the store refuses further writes. The caller must hold
+// the write lock.
+func (s *FileStore) rollbackLocked(offset int64) {
+	err := s.file.Truncate(offset)
+	if err == nil {
+		_, err = s.file.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		s.broken = fmt.Errorf("log damaged by a failed write: %w", err)
+	}
+}
+
+// maybeCompactLocked compacts the log once dead records reach the
+// threshold and outnumber live ones. The caller must hold the write lock.
+func (s *FileStore) maybeCompactLocked() error {
+	if s.minDead <= 0 || s.dead < s.minDead || s.dead <= len(s.entries) {
+		return nil
+	}
+	return s.compactLocked()
+}
+
+// compactLocked atomically replaces the log with one holding only live
+// entries. The caller must hold the write lock.
+func (s *FileStore) compactLocked() error {
+	if s.closed {
+		return errClosed
+	}
+
+	now := s.now()
+	for key, e := range s.entries {
+		if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".compact-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := s.writeLiveLocked(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if info, err := s.file.Stat(); err == nil {
+		// Keep the permissions of the log being replaced.
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		tmp.Close()
+		return err
+	}
+	syncDir(filepath.Dir(s.path))
+
+	s.file.Close()
+	s.file = tmp
+	s.dead = 0
+	s.broken = nil
+	return nil
+}
+
+// writeLiveLocked writes the magic header and a put record for every live
+// entry. The caller must hold the lock.
+func (s *FileStore) writeLiveLocked(w io.Writer) error {
+	if _, err := w.Write(fileMagic); err != nil {
+		return err
+	}
+	now := s.now()
+	for key, e := range s.entries {
+		if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+			continue
+		}
+		if _, err := w.Write(encodeRecord(opPut, key, e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay applies the records read from r, which holds size bytes, and
+// returns the offset just past the last intact record. An empty input
+// returns zero. A damaged record that reaches the end of the input, with
+// no intact record anywhere after its start, is treated as a torn final
+// write and ignored. Any other damaged record, including one whose
+// corrupted length runs past the end of the input over intact records, is
+// ErrCorrupt.
+func (s *FileStore) replay(r io.ReaderAt, size int64) (int64, error) {
+	br := bufio.NewReader(io.NewSectionReader(r, 0, size))
+	magic := make([]byte, len(fileMagic))
+	n, err := io.ReadFull(br, magic)
+	if n == 0 && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+		return 0, nil
+	}
+	if err != nil || !bytes.Equal(magic, fileMagic) {
+		return 0, errors.New("not a memory store file")
+	}
+
+	offset := int64(len(fileMagic))
+	for {
+		op, key, e, n, err := decodeRecord(br, size-offset)
+		switch {
+		case err == io.EOF:
+			return offset, nil
+		case err != nil && (errors.Is(err, io.ErrUnexpectedEOF) || offset+n == size) && !intactRecordAfter(r, offset+1, size):
+			// Nothing can follow the record: a torn final write.
+			return offset, nil
+		case err != nil:
+			return 0, fmt.Errorf("%w: record at byte %d: %v", ErrCorrupt, offset, err)
+		}
+		offset += n
+
+		if _, ok := s.entries[key]; ok {
+			s.dead++
+		}
+		switch op {
+		case opPut:
+			s.entries[key] = e
+		case opDelete:
+			delete(s.entries, key)
+			s.dead++
+		}
+	}
+}
+
+// encodeRecord returns the log record for an operation. The layout is
+//
+//	length uint32 | crc32 uint32 | op byte | expires int64 | key length uvarint | key | value
+//
+// where length and crc cover everything after them.
+func encodeRecord(op byte, key string, e fileEntry) []byte {
+	var expires int64
+	if !e.expiresAt.IsZero() {
+		expires = e.expiresAt.UnixNano()
+	}
+
+	body := make([]byte, 0, 1+8+binary.MaxVarintLen64+len(key)+len(e.data))
+	body = append(body, op)
+	body = binary.LittleEndian.AppendUint64(body, uint64(expires))
+	body = binary.AppendUvarint(body, uint64(len(key)))
+	body = append(body, key...)
+	body = append(body, e.data...)
+
+	record := make([]byte, 8, 8+len(body))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(body)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.Checksum(body, crcTable))
+	return append(record, body...)
+}
+
+// decodeRecord reads one record from r, which has remaining bytes left,
+// and returns it with its size in bytes. It returns io.EOF at the end of
+// the input and io.ErrUnexpectedEOF for a record cut short by it. For a
+// damaged record, size is the length its header claims.
+func decodeRecord(r io.Reader, remaining int64) (op byte, key string, e fileEntry, size int64, err error) {
+	var header [8]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	length := binary.LittleEndian.Uint32(header[0:4])
+	sum := binary.LittleEndian.Uint32(header[4:8])
+	size = 8 + int64(length)
+
+	// Check the untrusted length before allocating for it.
+	if size > remaining {
+		err = io.ErrUnexpectedEOF
+		return
+	}
+	body := make([]byte, length)
+	if _, err = io.ReadFull(r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	if crc32.Checksum(body, crcTable) != sum {
+		err = errors.New("checksum mismatch")
+		return
+	}
+	if len(body) < 9 {
+		err = errors.New("record too short")
+		return
+	}
+
+	op = body[0]
+	expires := int64(binary.LittleEndian.Uint64(body[1:9]))
+	keyLen, n := binary.Uvarint(body[9:])
+	if n <= 0 || uint64(len(body)-9-n) < keyLen {
+		err = errors.New("bad key length")
+		return
+	}
+	rest := body[9+n:]
+	key = string(rest[:keyLen])
+	e.data = rest[keyLen:]
+	if expires != 0 {
+		e.expiresAt = time.Unix(0, expires)
+	}
+	return op, key, e, size, nil
+}
+
+// intactRecordAfter reports whether an intact record starts at any offset
+// from from onwards in r, which holds size bytes. It is only consulted for
+// a damaged record reaching the end of the input, which after a crash is
+// at most one record long.
+func intactRecordAfter(r io.ReaderAt, from, size int64) bool {
+	for offset := from; offset < size; offset++ {
+		sr := io.NewSectionReader(r, offset, size-offset)
+		if _, _, _, _, err := decodeRecord(sr, size-offset); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// syncDir fsyncs a directory so that a rename within it is durable.
+// Errors are ignored because not every platform supports it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestFileStore(t *testing.T, path string, codec Codec) *FileStore {
+	t.Helper()
+	store, err := OpenFileStore(path, codec)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestFileStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.Put(ctx, "a", codecRecord{Name: "a", Count: 1})
+	store.Put(ctx, "b", "two")
+	store.Put(ctx, "a", codecRecord{Name: "a", Count: 2})
+	store.Delete(ctx, "b")
+	store.Close()
+
+	reopened := openTestFileStore(t, path, GobCodec{})
+	v, err := reopened.Get(ctx, "a")
+	if err != nil || v != (codecRecord{Name: "a", Count: 2}) {
+		t.Errorf("Expected latest value of a, got %v (%v)", v, err)
+	}
+	if _, err := reopened.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected deleted key to stay deleted, got %v", err)
+	}
+}
+
+func TestFileStore_JSONCodec(t *testing.T) {
+	store := openTestFileStore(t, filepath.Join(t.TempDir(), "memory.log"), JSONCodec{})
+	ctx := context.Background()
+
+	store.Put(ctx, "turn", map[string]string{"role": "user"})
+	v, err := store.Get(ctx, "turn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := v.(map[string]interface{}); !ok || m["role"] != "user" {
+		t.Errorf("Expected decoded JSON object, got %#v", v)
+	}
+}
+
+func TestFileStore_TornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.Put(ctx, "a", "1")
+	store.Put(ctx, "b", "2")
+	store.Close()
+
+	// Simulate a crash partway through the last record.
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openTestFileStore(t, path, GobCodec{})
+	if v, err := reopened.Get(ctx, "a"); err != nil || v != "1" {
+		t.Errorf("Expected intact record to survive, got %v (%v)", v, err)
+	}
+	if _, err := reopened.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected torn record to be dropped, got %v", err)
+	}
+
+	// New writes go after the last intact record.
+	reopened.Put(ctx, "c", "3")
+	reopened.Close()
+	again := openTestFileStore(t, path, GobCodec{})
+	if v, err := again.Get(ctx, "c"); err != nil || v != "3" {
+		t.Errorf("Expected write after recovery to persist, got %v (%v)", v, err)
+	}
+}
+
+func TestFileStore_CorruptRecordInMiddle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.Put(ctx, "a", "1")
+	store.Put(ctx, "b", "2")
+	store.Close()
+
+	// Flip a bit in the body of the first record, which is followed by
+	// the intact second one.
+	data, _ := os.ReadFile(path)
+	data[len(fileMagic)+10] ^= 1
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := OpenFileStore(path, GobCodec{}); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Expected ErrCorrupt, got %v", err)
+	}
+	if after, _ := os.ReadFile(path); len(after) != len(data) {
+		t.Error("Expected a failed open to leave the file untouched")
+	}
+}
+
+func TestFileStore_CorruptLengthInMiddle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.Put(ctx, "a", "1")
+	store.Put(ctx, "b", "2")
+	store.Put(ctx, "c", "3")
+	store.Close()
+
+	// Make the first record claim a body running past the end of the
+	// file, over the intact records after it.
+	data, _ := os.ReadFile(path)
+	data[len(fileMagic)+2] = 0xff
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := OpenFileStore(path, GobCodec{}); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Expected ErrCorrupt, got %v", err)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, data) {
+		t.Error("Expected a failed open to leave the file untouched")
+	}
+}
+
+func TestFileStore_OversizedTornHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.Put(ctx, "a", "1")
+	store.Close()
+
+	// A final header claiming a 4 GiB body must not be allocated.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	f.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	f.Close()
+
+	reopened := openTestFileStore(t, path, GobCodec{})
+	if v, err := reopened.Get(ctx, "a"); err != nil || v != "1" {
+		t.Errorf("Expected the torn header to be dropped, got %v (%v)", v, err)
+	}
+}
+
+func TestFileStore_FailedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.Put(ctx, "a", "1")
+
+	// Swap in a read-only handle so that writes, and the rollback, fail.
+	good := store.file
+	readOnly, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readOnly.Seek(0, io.SeekEnd)
+	store.file = readOnly
+	if err := store.Put(ctx, "b", "2"); err == nil {
+		t.Fatal("Expected the write to fail")
+	}
+	store.file = good
+	if err := store.Put(ctx, "c", "3"); err == nil {
+		t.Error("Expected writes to be refused after a failed rollback")
+	}
+	readOnly.Close()
+
+	// Compaction rewrites the log and makes the store writable again.
+	if err := store.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, "c", "3"); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened := openTestFileStore(t, path, GobCodec{})
+	if reopened.Len() != 2 {
+		t.Errorf("Expected a and c after reopening, got %d entries", reopened.Len())
+	}
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{}).WithSync(false).WithCompactionThreshold(10)
+	for i := 0; i < 100; i++ {
+		store.Put(ctx, "counter", i)
+	}
+	info, _ := os.Stat(path)
+	if info.Size() > 1000 {
+		t.Errorf("Expected log to be compacted, got %d bytes", info.Size())
+	}
+
+	store.Close()
+	reopened := openTestFileStore(t, path, GobCodec{})
+	if v, err := reopened.Get(ctx, "counter"); err != nil || v != 99 {
+		t.Errorf("Expected 99 after compaction, got %v (%v)", v, err)
+	}
+}
+
+func TestFileStore_CompactionKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	store := openTestFileStore(t, path, GobCodec{})
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Put(context.Background(), "a", "1")
+	if err := store.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("Expected mode 0640 after compaction, got %v", info.Mode().Perm())
+	}
+}
+
+func TestFileStore_TTL(t *testing.T) {
+	clock := newFakeClock()
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.now = clock.Now
+	store.PutWithTTL(ctx, "session", "x", time.Minute)
+	store.Put(ctx, "profile", "y")
+
+	clock.Advance(time.Minute)
+	if _, err := store.Get(ctx, "session"); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+
+	if err := store.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if store.Len() != 1 {
+		t.Errorf("Expected expired entry to be compacted away, got %d entries", store.Len())
+	}
+}
+
+func TestFileStore_SnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	src := openTestFileStore(t, filepath.Join(dir, "src.log"), GobCodec{})
+	src.Put(ctx, "a", "1")
+	src.Put(ctx, "b", "2")
+
+	var snap bytes.Buffer
+	if err := src.Snapshot(&snap); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := openTestFileStore(t, filepath.Join(dir, "dst.log"), GobCodec{})
+	dst.Put(ctx, "stale", "x")
+	if err := dst.Restore(bytes.NewReader(snap.Bytes())); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if dst.Len() != 2 {
+		t.Errorf("Expected 2 entries after restore, got %d", dst.Len())
+	}
+	if v, err := dst.Get(ctx, "b"); err != nil || v != "2" {
+		t.Errorf("Expected restored value, got %v (%v)", v, err)
+	}
+
+	corrupt := snap.Bytes()[:snap.Len()-1]
+	if err := dst.Restore(bytes.NewReader(corrupt)); err == nil {
+		t.Error("Expected error restoring a truncated snapshot")
+	}
+	if dst.Len() != 2 {
+		t.Error("Expected failed restore to leave the store unchanged")
+	}
+}
+
+func TestFileStore_Clear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.log")
+	ctx := context.Background()
+
+	store := openTestFileStore(t, path, GobCodec{})
+	store.Put(ctx, "a", "1")
+	if err := store.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened := openTestFileStore(t, path, GobCodec{})
+	if reopened.Len() != 0 {
+		t.Errorf("Expected empty store after Clear, got %d entries", reopened.Len())
+	}
+}
+
+func TestOpenFileStore_NotAStoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.txt")
+	os.WriteFile(path, []byte("hello world"), 0o644)
+
+	if _, err := OpenFileStore(path, GobCodec{}); err == nil {
+		t.Error("Expected error opening a non-store file")
+	}
+}
@@ -1,65 +1,402 @@
 package memory
 
 import (
+	"container/heap"
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrTooLarge is returned when a single value exceeds a store's byte limit.
+var ErrTooLarge = errors.New("value exceeds store size limit")
+
+// entry is a stored value with its expiry time and eviction bookkeeping.
+type entry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+	version   uint64
+
+	// Maintained only for bounded stores.
+	size  int64
+	freq  uint64
+	tick  uint64
+	index int
+}
+
+// expired reports whether e has expired at now.
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// eviction is an entry removed by the store, pending its callback.
+type eviction struct {
+	key    string
+	value  interface{}
+	reason EvictionReason
+}
+
 // InMemoryStore is a thread-safe in-memory implementation of Store.
+//
+// Entries may be given a TTL, either per key with PutWithTTL or for every
+// Put with WithDefaultTTL. Expired entries are removed lazily when they are
+// read, and periodically by a janitor goroutine started with StartJanitor.
+//
+// The store can be bounded by entry count and approximate byte size, in
+// which case it evicts entries using an LRU or LFU policy. Configure bounds
+// before use.
+//
+// Entries are versioned: InMemoryStore implements VersionedStore, so
+// concurrent writers can use CompareAndSwap, Update and Transact instead
+// of blind Puts. It also implements Watcher, publishing every change to
+// subscribers without ever blocking writers.
 type InMemoryStore struct {
-	mu    sync.RWMutex
-	store map[string]interface{}
+	mu         sync.RWMutex
+	store      map[string]*entry
+	defaultTTL time.Duration
+	now        func() time.Time
+	version    uint64
+
+	policy     EvictionPolicy
+	maxEntries int
+	maxBytes   int64
+	sizeFunc   func(key string, value interface{}) int64
+	onEvict    EvictionFunc
+	order      evictionHeap
+	bytes      int64
+	tick       uint64
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+
+	janitor     janitor
+	watchers    broadcaster
+	watchBuffer int
 }
 
-// NewInMemoryStore creates a new in-memory store.
+// NewInMemoryStore creates a new, unbounded in-memory store.
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
-		store: make(map[string]interface{}),
+		store:       make(map[string]*entry),
+		now:         time.Now,
+		watchBuffer: defaultWatchBuffer,
 	}
 }
 
-// Put implements the Store interface.
-func (s *InMemoryStore) Put(ctx context.Context, key string, value interface{}) error {
+// NewBoundedStore creates an in-memory store holding at most maxEntries
+// entries, evicting according to policy.
+func NewBoundedStore(maxEntries int, policy EvictionPolicy) *InMemoryStore {
+	return NewInMemoryStore().WithEvictionPolicy(policy).WithMaxEntries(maxEntries)
+}
+
+// WithDefaultTTL sets the TTL applied by Put. Zero disables expiry.
+func (s *InMemoryStore) WithDefaultTTL(ttl time.Duration) *InMemoryStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultTTL = ttl
+	return s
+}
+
+// WithMaxEntries limits the number of entries. Zero means unlimited.
+func (s *InMemoryStore) WithMaxEntries(n int) *InMemoryStore {
+	s.configure(func() { s.maxEntries = n })
+	return s
+}
+
+// WithMaxBytes limits the approximate total size of keys and values, as
+// measured by the store's size function. Zero means unlimited.
+func (s *InMemoryStore) WithMaxBytes(n int64) *InMemoryStore {
+	s.configure(func() { s.maxBytes = n })
+	return s
+}
+
+// WithEvictionPolicy sets the eviction policy. The default is LRU.
+func (s *InMemoryStore) WithEvictionPolicy(policy EvictionPolicy) *InMemoryStore {
+	s.configure(func() { s.policy = policy })
+	return s
+}
+
+// WithSizeFunc sets the function measuring entries for WithMaxBytes.
+// The default adds the key length to ApproximateSize of the value.
+func (s *InMemoryStore) WithSizeFunc(size func(key string, value interface{}) int64) *InMemoryStore {
+	s.configure(func() { s.sizeFunc = size })
+	return s
+}
+
+// WithEvictionCallback sets a function called for every entry removed
+// because of capacity limits or expiry. Explicit deletes do not trigger it.
+func (s *InMemoryStore) WithEvictionCallback(fn EvictionFunc) *InMemoryStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEvict = fn
+	return s
+}
+
+// WithWatchBuffer sets how many events each watcher may fall behind before
+// it overflows. The default is 256.
+func (s *InMemoryStore) WithWatchBuffer(n int) *InMemoryStore {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	
+	s.watchBuffer = n
+	return s
+}
+
+// configure applies a change to the bounds and re-indexes existing entries.
+func (s *InMemoryStore) configure(change func()) {
+	s.mu.Lock()
+	change()
+	s.order = evictionHeap{policy: s.policy}
+	s.bytes = 0
+	if s.bounded() {
+		for _, e := range s.store {
+			e.size = s.sizeOf(e.key, e.value)
+			s.bytes += e.size
+			heap.Push(&s.order, e)
+		}
+	}
+	evicted := s.evictLocked(0, 0)
+	s.mu.Unlock()
+	s.notify(evicted)
+}
+
+// Put implements the Store interface. The value expires after the store's
+// default TTL, if any.
+func (s *InMemoryStore) Put(ctx context.Context, key string, value interface{}) error {
+	s.mu.RLock()
+	ttl := s.defaultTTL
+	s.mu.RUnlock()
+	return s.PutWithTTL(ctx, key, value, ttl)
+}
+
+// PutWithTTL implements the TTLStore interface. In a bounded store it may
+// evict other entries, and it returns ErrTooLarge if the value alone
+// exceeds the byte limit.
+func (s *InMemoryStore) PutWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	s.mu.Lock()
+
 	select {
 	case <-ctx.Done():
+		s.mu.Unlock()
 		return ctx.Err()
 	default:
-		s.store[key] = value
-		return nil
 	}
+
+	evicted, err := s.insertLocked(s.newEntry(key, value, ttl))
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.notify(evicted)
+	return nil
 }
 
-// Get implements the Store interface.
+// Get implements the Store interface. It returns an error wrapping
+// ErrNotFound for unknown keys and ErrExpired for keys whose TTL has
+// elapsed but that have not been swept yet.
 func (s *InMemoryStore) Get(ctx context.Context, key string) (interface{}, error) {
+	value, _, err := s.GetVersion(ctx, key)
+	return value, err
+}
+
+// GetVersion implements the VersionedStore interface. It returns the same
+// errors as Get.
+func (s *InMemoryStore) GetVersion(ctx context.Context, key string) (interface{}, uint64, error) {
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	default:
+	}
+
+	// Unbounded stores serve live entries under the read lock.
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-	
+	e, ok := s.store[key]
+	if ok && !s.bounded() && !e.expired(s.now()) {
+		value, version := e.value, e.version
+		s.mu.RUnlock()
+		s.hits.Add(1)
+		return value, version, nil
+	}
+	s.mu.RUnlock()
+	if !ok {
+		s.misses.Add(1)
+		return nil, 0, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	s.mu.Lock()
+	e, ok = s.store[key]
+	if !ok {
+		s.mu.Unlock()
+		s.misses.Add(1)
+		return nil, 0, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if e.expired(s.now()) {
+		s.removeLocked(e)
+		s.publishLocked(EventExpire, e)
+		s.mu.Unlock()
+		s.misses.Add(1)
+		s.expirations.Add(1)
+		s.notify([]eviction{{key: e.key, value: e.value, reason: EvictedExpired}})
+		return nil, 0, fmt.Errorf("%w: %s", ErrExpired, key)
+	}
+	s.touchLocked(e)
+	value, version := e.value, e.version
+	s.mu.Unlock()
+
+	s.hits.Add(1)
+	return value, version, nil
+}
+
+// CompareAndSwap implements the VersionedStore interface. The value
+// expires after the store's default TTL, if any.
+func (s *InMemoryStore) CompareAndSwap(ctx context.Context, key string, version uint64, value interface{}) (uint64, error) {
+	s.mu.Lock()
+
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		s.mu.Unlock()
+		return 0, ctx.Err()
 	default:
-		value, ok := s.store[key]
-		if !ok {
-			return nil, fmt.Errorf("key not found: %s", key)
+	}
+
+	if current := s.versionLocked(key); current != version {
+		s.mu.Unlock()
+		return 0, fmt.Errorf("%w: %s is at version %d, not %d", ErrConflict, key, current, version)
+	}
+	e := s.newEntry(key, value, s.defaultTTL)
+	evicted, err := s.insertLocked(e)
+	s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	s.notify(evicted)
+	return e.version, nil
+}
+
+// Commit implements the VersionedStore interface. In a bounded store
+// room for all of the writes is made before any is applied, so no write
+// evicts another from the same commit, and writes that would not fit
+// together in an empty store fail the whole commit with ErrTooLarge.
+func (s *InMemoryStore) Commit(ctx context.Context, expect map[string]uint64, writes []Write) error {
+	s.mu.Lock()
+
+	select {
+	case <-ctx.Done():
+		s.mu.Unlock()
+		return ctx.Err()
+	default:
+	}
+
+	for key, version := range expect {
+		if current := s.versionLocked(key); current != version {
+			s.mu.Unlock()
+			return fmt.Errorf("%w: %s is at version %d, not %d", ErrConflict, key, current, version)
+		}
+	}
+	if s.maxBytes > 0 {
+		for _, w := range writes {
+			if size := s.sizeOf(w.Key, w.Value); !w.Delete && size > s.maxBytes {
+				s.mu.Unlock()
+				return fmt.Errorf("%w: %s is %d bytes", ErrTooLarge, w.Key, size)
+			}
+		}
+	}
+
+	var evicted []eviction
+	if s.bounded() {
+		var err error
+		if evicted, err = s.reserveLocked(writes); err != nil {
+			s.mu.Unlock()
+			return err
 		}
-		return value, nil
 	}
+	for _, w := range writes {
+		if w.Delete {
+			if e, ok := s.store[w.Key]; ok {
+				s.removeLocked(e)
+				s.publishLocked(EventDelete, e)
+			}
+			continue
+		}
+		ttl := w.TTL
+		if ttl <= 0 {
+			ttl = s.defaultTTL
+		}
+		e := s.newEntry(w.Key, w.Value, ttl)
+		e.size = s.sizeOf(e.key, e.value)
+		s.placeLocked(e)
+	}
+	s.mu.Unlock()
+
+	s.notify(evicted)
+	return nil
+}
+
+// PutIfAbsent stores value at key unless a live value is already there,
+// and reports whether it was stored.
+func (s *InMemoryStore) PutIfAbsent(ctx context.Context, key string, value interface{}) (bool, error) {
+	return PutIfAbsent(ctx, s, key, value)
+}
+
+// Update atomically replaces the value at key with fn's result. See the
+// package-level Update.
+func (s *InMemoryStore) Update(ctx context.Context, key string, fn UpdateFunc) (interface{}, error) {
+	return Update(ctx, s, key, fn)
+}
+
+// Transact runs fn in an optimistic multi-key transaction. See the
+// package-level Transact.
+func (s *InMemoryStore) Transact(ctx context.Context, fn func(tx *Txn) error) error {
+	return Transact(ctx, s, fn)
+}
+
+// TTL returns the time remaining before key expires, or zero if it never
+// expires.
+func (s *InMemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	e, ok := s.store[key]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	now := s.now()
+	if e.expired(now) {
+		return 0, fmt.Errorf("%w: %s", ErrExpired, key)
+	}
+	if e.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return e.expiresAt.Sub(now), nil
 }
 
 // Delete implements the Store interface.
 func (s *InMemoryStore) Delete(ctx context.Context, key string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		delete(s.store, key)
+		if e, ok := s.store[key]; ok {
+			s.removeLocked(e)
+			s.publishLocked(EventDelete, e)
+		}
 		return nil
 	}
 }
@@ -68,12 +405,355 @@ func (s *InMemoryStore) Delete(ctx context.Context, key string) error {
 func (s *InMemoryStore) Clear(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		s.store = make(map[string]interface{})
+		if s.watchers.active() {
+			for _, e := range s.store {
+				s.publishLocked(EventDelete, e)
+			}
+		}
+		s.store = make(map[string]*entry)
+		s.order = evictionHeap{policy: s.policy}
+		s.bytes = 0
 		return nil
 	}
 }
+
+// Scan implements the Scanner interface.
+func (s *InMemoryStore) Scan(ctx context.Context, prefix, cursor string, limit int) ([]string, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	default:
+	}
+
+	now := s.now()
+	var keys []string
+	for key, e := range s.store {
+		if strings.HasPrefix(key, prefix) && key >= cursor && !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	if limit <= 0 || len(keys) <= limit {
+		return keys, "", nil
+	}
+	keys = keys[:limit]
+	// The cursor is the least key after the page, so that it is never
+	// empty, even when the page ends with the key "".
+	return keys, keys[limit-1] + "\x00", nil
+}
+
+// List returns every live key starting with prefix, in lexicographic order.
+func (s *InMemoryStore) List(ctx context.Context, prefix string) ([]string, error) {
+	return List(ctx, s, prefix)
+}
+
+// Namespace returns a view of the store whose keys are prefixed with name.
+func (s *InMemoryStore) Namespace(name string) *NamespacedStore {
+	return NewNamespace(s, name)
+}
+
+// DeletePrefix deletes every entry whose key starts with prefix and returns
+// how many were deleted.
+func (s *InMemoryStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	deleted := 0
+	for key, e := range s.store {
+		if strings.HasPrefix(key, prefix) {
+			s.removeLocked(e)
+			s.publishLocked(EventDelete, e)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Len returns the number of stored entries, including expired entries that
+// have not been swept yet.
+func (s *InMemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.store)
+}
+
+// Stats returns the store's cache counters.
+func (s *InMemoryStore) Stats() Stats {
+	s.mu.RLock()
+	entries, bytes := len(s.store), s.bytes
+	s.mu.RUnlock()
+
+	return Stats{
+		Hits:        s.hits.Load(),
+		Misses:      s.misses.Load(),
+		Evictions:   s.evictions.Load(),
+		Expirations: s.expirations.Load(),
+		Entries:     entries,
+		Bytes:       bytes,
+	}
+}
+
+// DeleteExpired removes all expired entries and returns how many were
+// removed.
+func (s *InMemoryStore) DeleteExpired() int {
+	s.mu.Lock()
+	now := s.now()
+	var expired []eviction
+	for _, e := range s.store {
+		if e.expired(now) {
+			s.removeLocked(e)
+			s.publishLocked(EventExpire, e)
+			expired = append(expired, eviction{key: e.key, value: e.value, reason: EvictedExpired})
+		}
+	}
+	s.mu.Unlock()
+
+	s.expirations.Add(uint64(len(expired)))
+	s.notify(expired)
+	return len(expired)
+}
+
+// StartJanitor starts a goroutine that calls DeleteExpired every interval,
+// or every DefaultJanitorInterval if interval is not positive, replacing
+// any janitor already running. Stop it with StopJanitor or Close.
+func (s *InMemoryStore) StartJanitor(interval time.Duration) {
+	s.janitor.start(interval, func() { s.DeleteExpired() })
+}
+
+// StopJanitor stops the janitor goroutine, if running, and waits for it to
+// exit. Called from an eviction callback during a sweep, it returns
+// without waiting and the goroutine exits after the sweep.
+func (s *InMemoryStore) StopJanitor() {
+	s.janitor.halt()
+}
+
+// Watch implements the Watcher interface. Expiry events are published when
+// expired entries are swept or read, not at the instant they expire.
+func (s *InMemoryStore) Watch(ctx context.Context, prefix string) (<-chan Event, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	buffer := s.watchBuffer
+	s.mu.RUnlock()
+	return s.watchers.subscribe(ctx, prefix, buffer), nil
+}
+
+// Close stops the janitor. The store remains usable.
+func (s *InMemoryStore) Close() error {
+	s.StopJanitor()
+	return nil
+}
+
+// bounded reports whether the store enforces limits. The caller must hold
+// the lock.
+func (s *InMemoryStore) bounded() bool {
+	return s.maxEntries > 0 || s.maxBytes > 0
+}
+
+// sizeOf measures an entry for the byte limit.
+func (s *InMemoryStore) sizeOf(key string, value interface{}) int64 {
+	if s.sizeFunc != nil {
+		return s.sizeFunc(key, value)
+	}
+	return int64(len(key)) + ApproximateSize(value)
+}
+
+// newEntry creates an entry for key that expires after ttl, if positive.
+func (s *InMemoryStore) newEntry(key string, value interface{}, ttl time.Duration) *entry {
+	e := &entry{key: key, value: value, index: -1}
+	if ttl > 0 {
+		e.expiresAt = s.now().Add(ttl)
+	}
+	return e
+}
+
+// versionLocked returns the version of the live entry at key, or zero.
+// The caller must hold the lock.
+func (s *InMemoryStore) versionLocked(key string) uint64 {
+	e, ok := s.store[key]
+	if !ok || e.expired(s.now()) {
+		return 0
+	}
+	return e.version
+}
+
+// insertLocked stores e with a new version, replacing any entry with the
+// same key and first evicting other entries to make room for it. The new
+// entry is never evicted itself, which would otherwise always happen under
+// LFU. The caller must hold the write lock.
+func (s *InMemoryStore) insertLocked(e *entry) ([]eviction, error) {
+	var evicted []eviction
+	if s.bounded() {
+		e.size = s.sizeOf(e.key, e.value)
+		if s.maxBytes > 0 && e.size > s.maxBytes {
+			return nil, fmt.Errorf("%w: %s is %d bytes", ErrTooLarge, e.key, e.size)
+		}
+		extra := 1
+		if old, ok := s.store[e.key]; ok {
+			s.unlinkLocked(old)
+			extra = 0
+		}
+		evicted = s.evictLocked(extra, e.size)
+	}
+	s.placeLocked(e)
+	return evicted, nil
+}
+
+// reserveLocked evicts entries until writes fit in the store, before any of
+// them is applied. The entries the writes replace or delete are taken out
+// of the eviction order so that they are not counted or evicted twice. The
+// caller must hold the write lock.
+func (s *InMemoryStore) reserveLocked(writes []Write) ([]eviction, error) {
+	touched := make(map[string]bool, len(writes))
+	final := make(map[string]int64, len(writes))
+	for _, w := range writes {
+		touched[w.Key] = true
+		if w.Delete {
+			delete(final, w.Key)
+		} else {
+			final[w.Key] = s.sizeOf(w.Key, w.Value)
+		}
+	}
+	var bytes int64
+	for _, size := range final {
+		bytes += size
+	}
+	if (s.maxEntries > 0 && len(final) > s.maxEntries) || (s.maxBytes > 0 && bytes > s.maxBytes) {
+		return nil, fmt.Errorf("%w: commit writes %d entries of %d bytes", ErrTooLarge, len(final), bytes)
+	}
+
+	extra := 0
+	for key := range touched {
+		_, put := final[key]
+		if e, ok := s.store[key]; ok {
+			s.unlinkLocked(e)
+			if !put {
+				extra--
+			}
+		} else if put {
+			extra++
+		}
+	}
+	return s.evictLocked(extra, bytes), nil
+}
+
+// placeLocked stores e with a new version, replacing any entry with the
+// same key, without evicting anything. The caller must hold the write lock
+// and, in a bounded store, have set e.size and made room for it.
+func (s *InMemoryStore) placeLocked(e *entry) {
+	s.version++
+	e.version = s.version
+	if old, ok := s.store[e.key]; ok {
+		e.freq = old.freq
+		s.removeLocked(old)
+	}
+	s.store[e.key] = e
+	if s.bounded() {
+		s.bytes += e.size
+		s.touchLocked(e)
+		heap.Push(&s.order, e)
+	}
+	s.publishLocked(EventPut, e)
+}
+
+// removeLocked deletes e. The caller must hold the write lock.
+func (s *InMemoryStore) removeLocked(e *entry) {
+	delete(s.store, e.key)
+	if s.bounded() {
+		s.unlinkLocked(e)
+	}
+}
+
+// unlinkLocked takes e out of the eviction order and the byte count, so
+// that it cannot be evicted while it is replaced. The caller must hold the
+// write lock.
+func (s *InMemoryStore) unlinkLocked(e *entry) {
+	if e.index >= 0 {
+		heap.Remove(&s.order, e.index)
+		s.bytes -= e.size
+	}
+}
+
+// publishLocked notifies watchers of a change to e. Calling it under the
+// write lock keeps events in the order the changes were made.
+func (s *InMemoryStore) publishLocked(typ EventType, e *entry) {
+	s.watchers.publish(Event{Type: typ, Key: e.key, Value: e.value, Version: e.version})
+}
+
+// touchLocked records an access to e. The caller must hold the write lock.
+func (s *InMemoryStore) touchLocked(e *entry) {
+	if !s.bounded() {
+		return
+	}
+	s.tick++
+	e.tick = s.tick
+	e.freq++
+	if e.index >= 0 {
+		heap.Fix(&s.order, e.index)
+	}
+}
+
+// evictLocked removes entries until the store would stay within its bounds
+// after adding extraEntries entries of extraBytes bytes.
+// The caller must hold the write lock.
+func (s *InMemoryStore) evictLocked(extraEntries int, extraBytes int64) []eviction {
+	if !s.bounded() {
+		return nil
+	}
+
+	var evicted []eviction
+	now := s.now()
+	for s.order.Len() > 0 && ((s.maxEntries > 0 && len(s.store)+extraEntries > s.maxEntries) ||
+		(s.maxBytes > 0 && s.bytes+extraBytes > s.maxBytes)) {
+		e := s.order.entries[0]
+		s.removeLocked(e)
+
+		reason := EvictedCapacity
+		if e.expired(now) {
+			reason = EvictedExpired
+			s.expirations.Add(1)
+			s.publishLocked(EventExpire, e)
+		} else {
+			s.evictions.Add(1)
+			s.publishLocked(EventEvict, e)
+		}
+		evicted = append(evicted, eviction{key: e.key, value: e.value, reason: reason})
+	}
+	return evicted
+}
+
+// notify calls the eviction callback for each evicted entry. It must be
+// called without the lock held.
+func (s *InMemoryStore) notify(evicted []eviction) {
+	if len(evicted) == 0 {
+		return
+	}
+	s.mu.RLock()
+	fn := s.onEvict
+	s.mu.RUnlock()
+	if fn == nil {
+		return
+	}
+	for _, ev := range evicted {
+		fn(ev.key, ev.value, ev.reason)
+	}
+}
@@ -2,7 +2,11 @@ package memory
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewInMemoryStore(t *testing.T) {
@@ -52,7 +56,7 @@ func TestInMemoryStore_Delete(t *testing.T) {
 	value := "test-value"
 
 	store.Put(ctx, key, value)
-	
+
 	err := store.Delete(ctx, key)
 	if err != nil {
 		t.Fatalf("Expected no error on Delete, got %v", err)
@@ -139,3 +143,272 @@ func TestInMemoryStore_ContextCancellation(t *testing.T) {
 		t.Error("Expected error on cancelled context, got nil")
 	}
 }
+
+// fakeClock is a manually advanced clock for TTL tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestInMemoryStore_Get_ErrNotFound(t *testing.T) {
+	store := NewInMemoryStore()
+
+	_, err := store.Get(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestInMemoryStore_PutWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	store := NewInMemoryStore()
+	store.now = clock.Now
+	ctx := context.Background()
+
+	store.PutWithTTL(ctx, "short", "a", time.Minute)
+	store.PutWithTTL(ctx, "forever", "b", 0)
+
+	if ttl, err := store.TTL(ctx, "short"); err != nil || ttl != time.Minute {
+		t.Errorf("Expected TTL of 1m, got %v (%v)", ttl, err)
+	}
+
+	clock.Advance(time.Minute)
+
+	_, err := store.Get(ctx, "short")
+	if !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+	// Expired entries are removed when read.
+	_, err = store.Get(ctx, "short")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after lazy expiry, got %v", err)
+	}
+
+	if v, err := store.Get(ctx, "forever"); err != nil || v != "b" {
+		t.Errorf("Expected value without TTL to remain, got %v (%v)", v, err)
+	}
+}
+
+func TestInMemoryStore_DefaultTTL(t *testing.T) {
+	clock := newFakeClock()
+	store := NewInMemoryStore().WithDefaultTTL(time.Second)
+	store.now = clock.Now
+	ctx := context.Background()
+
+	store.Put(ctx, "a", 1)
+	store.PutWithTTL(ctx, "b", 2, time.Hour)
+	clock.Advance(2 * time.Second)
+
+	if removed := store.DeleteExpired(); removed != 1 {
+		t.Errorf("Expected 1 expired entry removed, got %d", removed)
+	}
+	if store.Len() != 1 {
+		t.Errorf("Expected 1 entry left, got %d", store.Len())
+	}
+}
+
+func TestInMemoryStore_Janitor(t *testing.T) {
+	store := NewInMemoryStore()
+	defer store.Close()
+	ctx := context.Background()
+
+	store.PutWithTTL(ctx, "a", 1, time.Millisecond)
+	store.StartJanitor(5 * time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for store.Len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected janitor to sweep expired entry")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	store.StopJanitor()
+	store.StopJanitor() // stopping twice is a no-op
+	store.PutWithTTL(ctx, "b", 2, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if store.Len() != 1 {
+		t.Error("Expected no sweeping after StopJanitor")
+	}
+}
+
+func TestInMemoryStore_JanitorNonPositiveInterval(t *testing.T) {
+	store := NewInMemoryStore()
+	defer store.Close()
+
+	// Both fall back to DefaultJanitorInterval instead of panicking.
+	store.StartJanitor(0)
+	store.StartJanitor(-time.Second)
+	store.StopJanitor()
+}
+
+func TestInMemoryStore_StopJanitorFromCallback(t *testing.T) {
+	var store *InMemoryStore
+	stopped := make(chan struct{})
+	store = NewInMemoryStore().WithEvictionCallback(func(key string, value interface{}, reason EvictionReason) {
+		store.Close()
+		close(stopped)
+	})
+	store.PutWithTTL(context.Background(), "a", 1, time.Millisecond)
+	store.StartJanitor(5 * time.Millisecond)
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Close from a sweep's eviction callback to return")
+	}
+	store.StopJanitor()
+}
+
+func TestInMemoryStore_LRUEviction(t *testing.T) {
+	var evicted []string
+	store := NewBoundedStore(2, LRU).WithEvictionCallback(func(key string, value interface{}, reason EvictionReason) {
+		if reason != EvictedCapacity {
+			t.Errorf("Expected capacity eviction, got %v", reason)
+		}
+		evicted = append(evicted, key)
+	})
+	ctx := context.Background()
+
+	store.Put(ctx, "a", 1)
+	store.Put(ctx, "b", 2)
+	store.Get(ctx, "a") // b is now least recently used
+	store.Put(ctx, "c", 3)
+
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Fatalf("Expected b to be evicted, got %v", evicted)
+	}
+	if _, err := store.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected evicted key to be gone, got %v", err)
+	}
+
+	stats := store.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if stats.HitRate() != 0.5 {
+		t.Errorf("Expected hit rate 0.5, got %v", stats.HitRate())
+	}
+}
+
+func TestInMemoryStore_LFUEviction(t *testing.T) {
+	store := NewBoundedStore(2, LFU)
+	ctx := context.Background()
+
+	store.Put(ctx, "a", 1)
+	store.Put(ctx, "b", 2)
+	store.Get(ctx, "a")
+	store.Get(ctx, "a")
+	store.Get(ctx, "b")
+	store.Put(ctx, "c", 3) // b (2 uses) loses to a (3 uses)
+	store.Get(ctx, "c")
+	store.Get(ctx, "c")
+	store.Get(ctx, "c")
+	store.Put(ctx, "d", 4) // a (3 uses) loses to c (4 uses)
+
+	for key, want := range map[string]bool{"a": false, "b": false, "c": true, "d": true} {
+		_, err := store.Get(ctx, key)
+		if (err == nil) != want {
+			t.Errorf("Key %s: expected present=%v, got err %v", key, want, err)
+		}
+	}
+}
+
+func TestInMemoryStore_MaxBytes(t *testing.T) {
+	store := NewInMemoryStore().
+		WithSizeFunc(func(key string, value interface{}) int64 { return int64(len(value.(string))) }).
+		WithMaxBytes(10)
+	ctx := context.Background()
+
+	store.Put(ctx, "a", "12345")
+	store.Put(ctx, "b", "1234")
+	store.Put(ctx, "c", "123")
+
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a to be evicted, got %v", err)
+	}
+	if stats := store.Stats(); stats.Bytes != 7 || stats.Entries != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if err := store.Put(ctx, "huge", "12345678901"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected oversized put to leave the store unchanged, got %d entries", store.Len())
+	}
+}
+
+func TestInMemoryStore_BoundsOnExistingEntries(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		store.Put(ctx, string(rune('a'+i)), i)
+	}
+
+	store.WithMaxEntries(3)
+	if store.Len() != 3 {
+		t.Errorf("Expected store trimmed to 3 entries, got %d", store.Len())
+	}
+}
+
+func TestInMemoryStore_ExpiredEvictionReason(t *testing.T) {
+	clock := newFakeClock()
+	reasons := map[string]EvictionReason{}
+	store := NewBoundedStore(10, LRU).WithEvictionCallback(func(key string, value interface{}, reason EvictionReason) {
+		reasons[key] = reason
+	})
+	store.now = clock.Now
+	ctx := context.Background()
+
+	store.PutWithTTL(ctx, "a", 1, time.Second)
+	clock.Advance(time.Second)
+	store.DeleteExpired()
+
+	if reasons["a"] != EvictedExpired {
+		t.Errorf("Expected expiry callback, got %v", reasons)
+	}
+	if stats := store.Stats(); stats.Expirations != 1 || stats.Evictions != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestApproximateSize(t *testing.T) {
+	type record struct {
+		Name string
+		Tags []string
+	}
+
+	small := ApproximateSize(record{Name: "a"})
+	large := ApproximateSize(record{Name: strings.Repeat("a", 1000), Tags: []string{"x", "y"}})
+	if large-small < 1000 {
+		t.Errorf("Expected size to grow with contents, got %d and %d", small, large)
+	}
+
+	// Cycles terminate.
+	type node struct{ Next *node }
+	n := &node{}
+	n.Next = n
+	if ApproximateSize(n) <= 0 {
+		t.Error("Expected positive size for cyclic value")
+	}
+	if ApproximateSize(nil) != 0 {
+		t.Error("Expected zero size for nil")
+	}
+}
@@ -0,0 +1,466 @@
+// Package sqltest provides an in-memory database/sql driver for testing
+// memory.SQLStore without a real database.
+//
+// It understands only the statements SQLStore issues, in each of its
+// dialects: CREATE TABLE and CREATE INDEX, INSERT with ON CONFLICT or
+// ON DUPLICATE KEY upserts, SELECT and DELETE with conditions joined by
+// AND, and both ? and $n placeholders. Column types are accepted and
+// ignored. Transactions are serialized and roll back by restoring a copy
+// of the data.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Open opens a new, empty in-memory database. The driver is not
+// registered with database/sql.
+func Open() (*sql.DB, error) {
+	return sql.OpenDB(&connector{db: &database{
+		tables:  make(map[string]*table),
+		indexes: make(map[string]bool),
+	}}), nil
+}
+
+// connector connects to one database.
+type connector struct {
+	db *database
+}
+
+// Connect implements the driver.Connector interface.
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+// Driver implements the driver.Connector interface.
+func (c *connector) Driver() driver.Driver { return c }
+
+// Open implements the driver.Driver interface. Every name opens a
+// connection to the connector's database.
+func (c *connector) Open(string) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+// database is a set of tables. txMu serializes transactions against each
+// other and against statements outside transactions.
+type database struct {
+	txMu    sync.Mutex
+	mu      sync.Mutex
+	tables  map[string]*table
+	indexes map[string]bool
+}
+
+// table holds rows as maps from lowercase column name to value.
+type table struct {
+	columns    []string
+	primaryKey []string
+	rows       []map[string]driver.Value
+}
+
+// snapshot is a copy of a database's contents, restored on rollback.
+type snapshot struct {
+	tables  map[string]*table
+	indexes map[string]bool
+}
+
+// conn is a connection to a database.
+type conn struct {
+	db    *database
+	saved *snapshot
+}
+
+// Prepare implements the driver.Conn interface.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	st, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, st: st}, nil
+}
+
+// Close implements the driver.Conn interface.
+func (c *conn) Close() error {
+	if c.saved != nil {
+		c.Rollback()
+	}
+	return nil
+}
+
+// Begin implements the driver.Conn interface.
+func (c *conn) Begin() (driver.Tx, error) {
+	if c.saved != nil {
+		return nil, errors.New("sqltest: transaction already open")
+	}
+	c.db.txMu.Lock()
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.saved = &snapshot{
+		tables:  make(map[string]*table, len(c.db.tables)),
+		indexes: make(map[string]bool, len(c.db.indexes)),
+	}
+	for name, t := range c.db.tables {
+		copied := &table{columns: t.columns, primaryKey: t.primaryKey}
+		for _, row := range t.rows {
+			copied.rows = append(copied.rows, copyRow(row))
+		}
+		c.saved.tables[name] = copied
+	}
+	for name := range c.db.indexes {
+		c.saved.indexes[name] = true
+	}
+	return c, nil
+}
+
+// Commit implements the driver.Tx interface.
+func (c *conn) Commit() error {
+	if c.saved == nil {
+		return errors.New("sqltest: no transaction")
+	}
+	c.saved = nil
+	c.db.txMu.Unlock()
+	return nil
+}
+
+// Rollback implements the driver.Tx interface.
+func (c *conn) Rollback() error {
+	if c.saved == nil {
+		return errors.New("sqltest: no transaction")
+	}
+	c.db.mu.Lock()
+	c.db.tables, c.db.indexes = c.saved.tables, c.saved.indexes
+	c.db.mu.Unlock()
+	c.saved = nil
+	c.db.txMu.Unlock()
+	return nil
+}
+
+// run executes a statement with the database locked.
+func (c *conn) run(st statement, args []driver.Value) (*result, error) {
+	if c.saved == nil {
+		c.db.txMu.Lock()
+		defer c.db.txMu.Unlock()
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return st.exec(c.db, args)
+}
+
+// stmt is a prepared statement.
+type stmt struct {
+	conn *conn
+	st   statement
+}
+
+// Close implements the driver.Stmt interface.
+func (s *stmt) Close() error { return nil }
+
+// NumInput implements the driver.Stmt interface. The number of
+// placeholders is not checked.
+func (s *stmt) NumInput() int { return -1 }
+
+// Exec implements the driver.Stmt interface.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := s.conn.run(s.st, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(res.affected), nil
+}
+
+// Query implements the driver.Stmt interface.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	res, err := s.conn.run(s.st, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: res.columns, data: res.rows}, nil
+}
+
+// result is the outcome of executing a statement.
+type result struct {
+	affected int64
+	columns  []string
+	rows     [][]driver.Value
+}
+
+// rows iterates over a query result.
+type rows struct {
+	columns []string
+	data    [][]driver.Value
+	pos     int
+}
+
+// Columns implements the driver.Rows interface.
+func (r *rows) Columns() []string { return r.columns }
+
+// Close implements the driver.Rows interface.
+func (r *rows) Close() error { return nil }
+
+// Next implements the driver.Rows interface.
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	copy(dest, r.data[r.pos])
+	r.pos++
+	return nil
+}
+
+// statement is a parsed SQL statement.
+type statement interface {
+	exec(db *database, args []driver.Value) (*result, error)
+}
+
+// createTable is CREATE TABLE [IF NOT EXISTS] name (columns..., PRIMARY KEY (...)).
+type createTable struct {
+	name        string
+	ifNotExists bool
+	columns     []string
+	primaryKey  []string
+}
+
+func (s *createTable) exec(db *database, args []driver.Value) (*result, error) {
+	if _, ok := db.tables[s.name]; ok {
+		if s.ifNotExists {
+			return &result{}, nil
+		}
+		return nil, fmt.Errorf("table %s already exists", s.name)
+	}
+	db.tables[s.name] = &table{columns: s.columns, primaryKey: s.primaryKey}
+	return &result{}, nil
+}
+
+// createIndex is CREATE INDEX [IF NOT EXISTS] name ON table (columns).
+// Indexes are recorded by name but not maintained.
+type createIndex struct {
+	name        string
+	table       string
+	ifNotExists bool
+}
+
+func (s *createIndex) exec(db *database, args []driver.Value) (*result, error) {
+	if _, ok := db.tables[s.table]; !ok {
+		return nil, fmt.Errorf("no such table: %s", s.table)
+	}
+	if db.indexes[s.name] {
+		if s.ifNotExists {
+			return &result{}, nil
+		}
+		return nil, fmt.Errorf("index %s already exists", s.name)
+	}
+	db.indexes[s.name] = true
+	return &result{}, nil
+}
+
+// assignment is col = expr in an upsert's update clause.
+type assignment struct {
+	column string
+	value  expr
+}
+
+// insert is INSERT INTO t (cols) VALUES (exprs) with an optional upsert.
+type insert struct {
+	table   string
+	columns []string
+	values  []expr
+	upsert  bool
+	updates []assignment
+}
+
+func (s *insert) exec(db *database, args []driver.Value) (*result, error) {
+	t, ok := db.tables[s.table]
+	if !ok {
+		return nil, fmt.Errorf("no such table: %s", s.table)
+	}
+	if len(s.columns) != len(s.values) {
+		return nil, fmt.Errorf("%d columns but %d values", len(s.columns), len(s.values))
+	}
+
+	row := make(map[string]driver.Value, len(t.columns))
+	for _, col := range t.columns {
+		row[col] = nil
+	}
+	ev := &env{args: args}
+	for i, col := range s.columns {
+		if _, ok := row[col]; !ok {
+			return nil, fmt.Errorf("table %s has no column %s", s.table, col)
+		}
+		v, err := s.values[i].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		row[col] = v
+	}
+
+	for _, existing := range t.rows {
+		if len(t.primaryKey) == 0 || !sameKey(existing, row, t.primaryKey) {
+			continue
+		}
+		if !s.upsert {
+			return nil, fmt.Errorf("UNIQUE constraint failed: %s.%s", s.table, strings.Join(t.primaryKey, ", "))
+		}
+		uenv := &env{args: args, row: existing, excluded: row}
+		values := make([]driver.Value, len(s.updates))
+		for i, a := range s.updates {
+			v, err := a.value.eval(uenv)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		for i, a := range s.updates {
+			existing[a.column] = values[i]
+		}
+		return &result{affected: 1}, nil
+	}
+
+	t.rows = append(t.rows, row)
+	return &result{affected: 1}, nil
+}
+
+// selectStmt is SELECT exprs FROM t [WHERE]. A query whose expressions
+// use MAX returns a single row aggregating the matched rows.
+type selectStmt struct {
+	table string
+	exprs []expr
+	names []string
+	where []predicate
+}
+
+func (s *selectStmt) exec(db *database, args []driver.Value) (*result, error) {
+	t, ok := db.tables[s.table]
+	if !ok {
+		return nil, fmt.Errorf("no such table: %s", s.table)
+	}
+	var matched []map[string]driver.Value
+	for _, row := range t.rows {
+		ok, err := matches(row, s.where, args)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+
+	envs := make([]*env, len(matched))
+	for i, row := range matched {
+		envs[i] = &env{args: args, row: row}
+	}
+	if isAggregate(s.exprs) {
+		envs = []*env{{args: args, group: matched}}
+	}
+
+	res := &result{columns: s.names}
+	for _, ev := range envs {
+		out := make([]driver.Value, len(s.exprs))
+		for i, e := range s.exprs {
+			v, err := e.eval(ev)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		res.rows = append(res.rows, out)
+	}
+	return res, nil
+}
+
+// deleteStmt is DELETE FROM t [WHERE].
+type deleteStmt struct {
+	table string
+	where []predicate
+}
+
+func (s *deleteStmt) exec(db *database, args []driver.Value) (*result, error) {
+	t, ok := db.tables[s.table]
+	if !ok {
+		return nil, fmt.Errorf("no such table: %s", s.table)
+	}
+	kept := t.rows[:0]
+	var deleted int64
+	for _, row := range t.rows {
+		match, err := matches(row, s.where, args)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			deleted++
+		} else {
+			kept = append(kept, row)
+		}
+	}
+	t.rows = kept
+	return &result{affected: deleted}, nil
+}
+
+// matches reports whether row satisfies all predicates.
+func matches(row map[string]driver.Value, where []predicate, args []driver.Value) (bool, error) {
+	ev := &env{args: args, row: row}
+	for _, p := range where {
+		ok, err := p.test(ev)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func copyRow(row map[string]driver.Value) map[string]driver.Value {
+	c := make(map[string]driver.Value, len(row))
+	for k, v := range row {
+		c[k] = v
+	}
+	return c
+}
+
+// sameKey reports whether two rows agree on the key columns.
+func sameKey(a, b map[string]driver.Value, keys []string) bool {
+	for _, k := range keys {
+		if c, ok := compare(a[k], b[k]); !ok || c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// compare orders two non-NULL integers or two strings.
+func compare(a, b driver.Value) (int, bool) {
+	if x, ok := a.(int64); ok {
+		y, ok := b.(int64)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	x, xok := toString(a)
+	y, yok := toString(b)
+	if !xok || !yok {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+func toString(v driver.Value) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case []byte:
+		return string(s), true
+	}
+	return "", false
+}
@@ -0,0 +1,105 @@
+package sqltest
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestDriver(t *testing.T) {
+	db, err := Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mustExec := func(query string, args ...interface{}) sql.Result {
+		t.Helper()
+		res, err := db.Exec(query, args...)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return res
+	}
+
+	mustExec(`CREATE TABLE items (id VARCHAR(10) NOT NULL, n BIGINT, PRIMARY KEY (id))`)
+	mustExec(`CREATE TABLE IF NOT EXISTS items (id TEXT)`)
+	mustExec(`CREATE INDEX items_n ON items (n)`)
+	mustExec(`CREATE INDEX IF NOT EXISTS items_n ON items (n)`)
+	if _, err := db.Exec(`CREATE INDEX items_n ON items (n)`); err == nil {
+		t.Error("Expected duplicate index to fail")
+	}
+
+	mustExec(`INSERT INTO items (id, n) VALUES (?, ?)`, "a", 1)
+	mustExec(`INSERT INTO items (id, n) VALUES ($1, $2)`, "b", 2)
+	mustExec(`INSERT INTO items (id, n) VALUES (?, ?)`, "c", nil)
+	if _, err := db.Exec(`INSERT INTO items (id, n) VALUES (?, ?)`, "a", 5); err == nil {
+		t.Error("Expected primary key violation")
+	}
+	mustExec(`INSERT INTO items (id, n) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET n = excluded.n`, "a", 5)
+	mustExec(`INSERT INTO items (id, n) VALUES (?, ?) ON DUPLICATE KEY UPDATE n = VALUES(n)`, "b", 8)
+
+	var n int64
+	if err := db.QueryRow(`SELECT n FROM items WHERE id = ?`, "a").Scan(&n); err != nil || n != 5 {
+		t.Errorf("Expected upserted n 5, got %d (%v)", n, err)
+	}
+	var max int64
+	db.QueryRow(`SELECT COALESCE(MAX(n), 0) FROM items`).Scan(&max)
+	if max != 8 {
+		t.Errorf("Expected max 8, got %d", max)
+	}
+
+	mustExec(`INSERT INTO items (id, n) VALUES (?, ?)`, "a_b", 1)
+	if res := mustExec(`DELETE FROM items WHERE id LIKE ? ESCAPE '!'`, "a!_%"); rowsAffected(res) != 1 {
+		t.Error("Expected the escaped wildcard to match one row")
+	}
+	if res := mustExec(`DELETE FROM items WHERE n IS NOT NULL AND n <= ?`, 5); rowsAffected(res) != 1 {
+		t.Error("Expected one row deleted")
+	}
+}
+
+func TestDriver_Rollback(t *testing.T) {
+	db, err := Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.Exec(`CREATE TABLE t (id TEXT PRIMARY KEY)`)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Exec(`INSERT INTO t (id) VALUES (?)`, "x")
+	tx.Exec(`CREATE INDEX t_id ON t (id)`)
+	tx.Rollback()
+
+	if err := db.QueryRow(`SELECT id FROM t WHERE id = ?`, "x").Scan(new(string)); err != sql.ErrNoRows {
+		t.Errorf("Expected rollback to discard insert, got %v", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX t_id ON t (id)`); err != nil {
+		t.Errorf("Expected rollback to discard the index, got %v", err)
+	}
+}
+
+func TestLikeMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"user:%", "user:1", true},
+		{"user:%", "users", false},
+		{"a_c", "abc", true},
+		{`100!%`, "100%", true},
+		{`100!%`, "1000", false},
+	}
+	for _, tt := range tests {
+		if got := likeMatch(tt.pattern, tt.s, '!'); got != tt.want {
+			t.Errorf("LikeMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func rowsAffected(res sql.Result) int64 {
+	n, _ := res.RowsAffected()
+	return n
+}
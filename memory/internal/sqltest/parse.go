@@ -0,0 +1,647 @@
+package sqltest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// env is the context an expression is evaluated in.
+type env struct {
+	args     []driver.Value
+	row      map[string]driver.Value
+	excluded map[string]driver.Value
+	group    []map[string]driver.Value
+}
+
+// expr is a value expression.
+type expr interface {
+	eval(e *env) (driver.Value, error)
+}
+
+// column references a column of the current row, or of the row being
+// inserted when qualified with "excluded".
+type column struct {
+	qualifier string
+	name      string
+}
+
+func (c *column) eval(e *env) (driver.Value, error) {
+	row := e.row
+	if c.qualifier == "excluded" {
+		row = e.excluded
+	}
+	if row == nil {
+		return nil, fmt.Errorf("column %s used outside a row", c.name)
+	}
+	v, ok := row[c.name]
+	if !ok {
+		return nil, fmt.Errorf("no such column: %s", c.name)
+	}
+	return v, nil
+}
+
+// literal is a constant.
+type literal struct{ value driver.Value }
+
+func (l *literal) eval(e *env) (driver.Value, error) { return l.value, nil }
+
+// param is a placeholder, numbered from zero.
+type param struct{ index int }
+
+func (p *param) eval(e *env) (driver.Value, error) {
+	if p.index >= len(e.args) {
+		return nil, fmt.Errorf("missing argument %d", p.index+1)
+	}
+	return e.args[p.index], nil
+}
+
+// call is a call to COALESCE, MAX or VALUES.
+type call struct {
+	name string
+	args []expr
+}
+
+func (c *call) eval(e *env) (driver.Value, error) {
+	switch c.name {
+	case "coalesce":
+		for _, a := range c.args {
+			v, err := a.eval(e)
+			if err != nil || v != nil {
+				return v, err
+			}
+		}
+		return nil, nil
+	case "values":
+		col, ok := c.args[0].(*column)
+		if !ok || e.excluded == nil {
+			return nil, fmt.Errorf("VALUES must reference a column in an upsert")
+		}
+		return e.excluded[col.name], nil
+	case "max":
+		var best driver.Value
+		for _, row := range e.group {
+			v, err := c.args[0].eval(&env{args: e.args, row: row})
+			if err != nil {
+				return nil, err
+			}
+			if cmp, ok := compare(v, best); best == nil || (ok && cmp > 0) {
+				best = v
+			}
+		}
+		return best, nil
+	default:
+		return nil, fmt.Errorf("unsupported function %s", strings.ToUpper(c.name))
+	}
+}
+
+// isAggregate reports whether any expression calls MAX.
+func isAggregate(exprs []expr) bool {
+	for _, x := range exprs {
+		if c, ok := x.(*call); ok && (c.name == "max" || isAggregate(c.args)) {
+			return true
+		}
+	}
+	return false
+}
+
+// predicate is one condition of a WHERE clause.
+type predicate struct {
+	left   expr
+	op     string
+	right  expr
+	escape byte
+}
+
+func (p *predicate) test(e *env) (bool, error) {
+	l, err := p.left.eval(e)
+	if err != nil {
+		return false, err
+	}
+	if p.op == "IS NOT NULL" {
+		return l != nil, nil
+	}
+	r, err := p.right.eval(e)
+	if err != nil || l == nil || r == nil {
+		return false, err
+	}
+
+	if p.op == "LIKE" {
+		s, ok1 := toString(l)
+		pattern, ok2 := toString(r)
+		if !ok1 || !ok2 {
+			return false, fmt.Errorf("LIKE needs strings, got %T and %T", l, r)
+		}
+		return likeMatch(pattern, s, p.escape), nil
+	}
+
+	c, ok := compare(l, r)
+	if !ok {
+		return false, fmt.Errorf("cannot compare %T and %T", l, r)
+	}
+	if p.op == "=" {
+		return c == 0, nil
+	}
+	return c <= 0, nil
+}
+
+// likeMatch matches s against a LIKE pattern with % and _ wildcards.
+func likeMatch(pattern, s string, escape byte) bool {
+	for len(pattern) > 0 {
+		c := pattern[0]
+		switch {
+		case escape != 0 && c == escape && len(pattern) > 1:
+			if len(s) == 0 || s[0] != pattern[1] {
+				return false
+			}
+			pattern, s = pattern[2:], s[1:]
+		case c == '%':
+			for i := 0; i <= len(s); i++ {
+				if likeMatch(pattern[1:], s[i:], escape) {
+					return true
+				}
+			}
+			return false
+		case c == '_':
+			if len(s) == 0 {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		default:
+			if len(s) == 0 || s[0] != c {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// Token kinds.
+const (
+	tokIdent = iota
+	tokNumber
+	tokString
+	tokParam
+	tokPunct
+)
+
+type token struct {
+	kind  int
+	text  string
+	param int
+}
+
+// tokenize splits a query into tokens. Identifiers are lowercased.
+func tokenize(query string) ([]token, error) {
+	var toks []token
+	next := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '\'':
+			j := strings.IndexByte(query[i+1:], '\'')
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated string in %q", query)
+			}
+			toks = append(toks, token{kind: tokString, text: query[i+1 : i+1+j]})
+			i += j + 2
+		case c == '?':
+			toks = append(toks, token{kind: tokParam, param: next})
+			next++
+			i++
+		case c == '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			n, err := strconv.Atoi(query[i+1 : j])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid placeholder in %q", query)
+			}
+			toks = append(toks, token{kind: tokParam, param: n - 1})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, text: query[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(query) && (query[j] == '_' || unicode.IsLetter(rune(query[j])) || unicode.IsDigit(rune(query[j]))) {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: strings.ToLower(query[i:j])})
+			i = j
+		case strings.HasPrefix(query[i:], "<="):
+			toks = append(toks, token{kind: tokPunct, text: "<="})
+			i += 2
+		case strings.ContainsRune("(),=.", rune(c)):
+			toks = append(toks, token{kind: tokPunct, text: string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q in %q", c, query)
+		}
+	}
+	return toks, nil
+}
+
+// parser is a recursive-descent parser over tokens.
+type parser struct {
+	toks  []token
+	pos   int
+	query string
+}
+
+// parse parses one SQL statement.
+func parse(query string) (statement, error) {
+	toks, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, query: query}
+
+	var st statement
+	switch {
+	case p.accept("create"):
+		st, err = p.create()
+	case p.accept("insert"):
+		st, err = p.insert()
+	case p.accept("select"):
+		st, err = p.selectStmt()
+	case p.accept("delete"):
+		st, err = p.deleteStmt()
+	default:
+		err = p.errorf("unsupported statement")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, p.errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	return st, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("sqltest: %s in %q", fmt.Sprintf(format, args...), p.query)
+}
+
+// peek reports whether the next token is the word or punctuation w.
+func (p *parser) peek(w string) bool {
+	if p.pos >= len(p.toks) {
+		return false
+	}
+	t := p.toks[p.pos]
+	return (t.kind == tokIdent || t.kind == tokPunct) && t.text == w
+}
+
+// accept consumes the next token if it is w.
+func (p *parser) accept(w string) bool {
+	if p.peek(w) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the words ws in order.
+func (p *parser) expect(ws ...string) error {
+	for _, w := range ws {
+		if !p.accept(w) {
+			return p.errorf("expected %s", strings.ToUpper(w))
+		}
+	}
+	return nil
+}
+
+// ident consumes an identifier.
+func (p *parser) ident() (string, error) {
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokIdent {
+		return "", p.errorf("expected identifier")
+	}
+	p.pos++
+	return p.toks[p.pos-1].text, nil
+}
+
+// identList parses "(a, b, ...)".
+func (p *parser) identList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if p.accept(")") {
+			return names, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ifNotExists parses an optional "IF NOT EXISTS".
+func (p *parser) ifNotExists() (bool, error) {
+	if !p.accept("if") {
+		return false, nil
+	}
+	return true, p.expect("not", "exists")
+}
+
+func (p *parser) create() (statement, error) {
+	if p.accept("index") {
+		st := &createIndex{}
+		var err error
+		if st.ifNotExists, err = p.ifNotExists(); err != nil {
+			return nil, err
+		}
+		if st.name, err = p.ident(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("on"); err != nil {
+			return nil, err
+		}
+		if st.table, err = p.ident(); err != nil {
+			return nil, err
+		}
+		_, err = p.identList()
+		return st, err
+	}
+
+	if err := p.expect("table"); err != nil {
+		return nil, err
+	}
+	st := &createTable{}
+	var err error
+	if st.ifNotExists, err = p.ifNotExists(); err != nil {
+		return nil, err
+	}
+	if st.name, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.accept("primary") {
+			if err := p.expect("key"); err != nil {
+				return nil, err
+			}
+			if st.primaryKey, err = p.identList(); err != nil {
+				return nil, err
+			}
+		} else {
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			st.columns = append(st.columns, name)
+			// Skip the type and constraints, noting an inline primary key.
+			depth := 0
+			for p.pos < len(p.toks) && (depth > 0 || (!p.peek(",") && !p.peek(")"))) {
+				switch {
+				case p.peek("("):
+					depth++
+				case p.peek(")"):
+					depth--
+				case p.peek("primary"):
+					st.primaryKey = []string{name}
+				}
+				p.pos++
+			}
+		}
+		if p.accept(")") {
+			return st, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// insert parses an INSERT. Upserts always resolve conflicts on the
+// primary key, which is what SQLStore names in ON CONFLICT.
+func (p *parser) insert() (statement, error) {
+	if err := p.expect("into"); err != nil {
+		return nil, err
+	}
+	st := &insert{}
+	var err error
+	if st.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if st.columns, err = p.identList(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("values"); err != nil {
+		return nil, err
+	}
+	if st.values, err = p.exprList(); err != nil {
+		return nil, err
+	}
+
+	if !p.accept("on") {
+		return st, nil
+	}
+	st.upsert = true
+	switch {
+	case p.accept("conflict"):
+		if _, err := p.identList(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("do", "update", "set"); err != nil {
+			return nil, err
+		}
+	case p.accept("duplicate"):
+		if err := p.expect("key", "update"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, p.errorf("expected CONFLICT or DUPLICATE KEY")
+	}
+
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		st.updates = append(st.updates, assignment{column: name, value: x})
+		if !p.accept(",") {
+			return st, nil
+		}
+	}
+}
+
+func (p *parser) selectStmt() (statement, error) {
+	st := &selectStmt{}
+	for {
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		st.exprs = append(st.exprs, x)
+		if c, ok := x.(*column); ok {
+			st.names = append(st.names, c.name)
+		} else {
+			st.names = append(st.names, fmt.Sprintf("expr%d", len(st.exprs)))
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect("from"); err != nil {
+		return nil, err
+	}
+	var err error
+	if st.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	st.where, err = p.where()
+	return st, err
+}
+
+func (p *parser) deleteStmt() (statement, error) {
+	if err := p.expect("from"); err != nil {
+		return nil, err
+	}
+	st := &deleteStmt{}
+	var err error
+	if st.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	st.where, err = p.where()
+	return st, err
+}
+
+// where parses an optional "WHERE p AND p ...".
+func (p *parser) where() ([]predicate, error) {
+	if !p.accept("where") {
+		return nil, nil
+	}
+	var preds []predicate
+	for {
+		pred, err := p.predicate()
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+		if !p.accept("and") {
+			return preds, nil
+		}
+	}
+}
+
+// predicate parses "x = y", "x <= y", "x IS NOT NULL" or
+// "x LIKE y [ESCAPE 'c']".
+func (p *parser) predicate() (predicate, error) {
+	left, err := p.expr()
+	if err != nil {
+		return predicate{}, err
+	}
+	pred := predicate{left: left}
+
+	switch {
+	case p.accept("is"):
+		pred.op = "IS NOT NULL"
+		return pred, p.expect("not", "null")
+	case p.accept("like"):
+		pred.op = "LIKE"
+		if pred.right, err = p.expr(); err != nil {
+			return pred, err
+		}
+		if p.accept("escape") {
+			if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokString || len(p.toks[p.pos].text) != 1 {
+				return pred, p.errorf("ESCAPE needs a one-character string")
+			}
+			pred.escape = p.toks[p.pos].text[0]
+			p.pos++
+		}
+		return pred, nil
+	case p.accept("="):
+		pred.op = "="
+	case p.accept("<="):
+		pred.op = "<="
+	default:
+		return pred, p.errorf("expected comparison")
+	}
+	pred.right, err = p.expr()
+	return pred, err
+}
+
+// exprList parses "(x, y, ...)".
+func (p *parser) exprList() ([]expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var exprs []expr
+	for {
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, x)
+		if p.accept(")") {
+			return exprs, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// expr parses a number, placeholder, function call or column.
+func (p *parser) expr() (expr, error) {
+	if p.pos >= len(p.toks) {
+		return nil, p.errorf("unexpected end of statement")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+
+	switch t.kind {
+	case tokNumber:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		return &literal{n}, err
+	case tokParam:
+		return &param{index: t.param}, nil
+	case tokIdent:
+	default:
+		return nil, p.errorf("unexpected %q", t.text)
+	}
+
+	if p.peek("(") {
+		c := &call{name: t.text}
+		var err error
+		if c.args, err = p.exprList(); err != nil {
+			return nil, err
+		}
+		if c.name != "coalesce" && len(c.args) != 1 {
+			return nil, p.errorf("%s takes one argument", strings.ToUpper(c.name))
+		}
+		return c, nil
+	}
+	if p.accept(".") {
+		name, err := p.ident()
+		return &column{qualifier: t.text, name: name}, err
+	}
+	return &column{name: t.text}, nil
+}
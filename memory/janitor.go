@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultJanitorInterval is the sweep interval used by StartJanitor when
+// the interval given is not positive.
+const DefaultJanitorInterval = time.Minute
+
+// janitorInterval returns interval, or DefaultJanitorInterval if interval
+// is not positive.
+func janitorInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return DefaultJanitorInterval
+	}
+	return interval
+}
+
+// janitor runs a cleanup function periodically in a goroutine that can be
+// stopped and restarted. The zero value is ready to use.
+type janitor struct {
+	mu  sync.Mutex
+	run *janitorRun
+}
+
+// janitorRun is one janitor goroutine.
+type janitorRun struct {
+	stop     chan struct{}
+	done     chan struct{}
+	sweeping atomic.Bool
+}
+
+// start runs sweep every interval, replacing any running goroutine. An
+// interval that is not positive means DefaultJanitorInterval.
+func (j *janitor) start(interval time.Duration, sweep func()) {
+	interval = janitorInterval(interval)
+	run := &janitorRun{stop: make(chan struct{}), done: make(chan struct{})}
+
+	j.mu.Lock()
+	old := j.run
+	j.run = run
+	j.mu.Unlock()
+	old.halt()
+
+	go func() {
+		defer close(run.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				run.sweeping.Store(true)
+				sweep()
+				run.sweeping.Store(false)
+			case <-run.stop:
+				return
+			}
+		}
+	}()
+}
+
+// halt stops the goroutine, if running, and waits for it to exit. If a
+// sweep is in progress, as when halt is called from an eviction callback
+// run by the sweep, it returns without waiting: the goroutine exits once
+// the sweep finishes, and no further sweep starts.
+func (j *janitor) halt() {
+	j.mu.Lock()
+	run := j.run
+	j.run = nil
+	j.mu.Unlock()
+	run.halt()
+}
+
+// halt stops the run and waits for it to exit unless it is sweeping.
+func (r *janitorRun) halt() {
+	if r == nil {
+		return
+	}
+	close(r.stop)
+	if !r.sweeping.Load() {
+		<-r.done
+	}
+}
@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NamespaceSeparator separates namespace names from keys.
+const NamespaceSeparator = ":"
+
+// prefixDeleter is implemented by stores that can delete all keys with a
+// prefix efficiently.
+type prefixDeleter interface {
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// NamespacedStore is a view of a Store whose keys are transparently
+// prefixed with a namespace, so that tenants, users or sessions can share
+// one store without colliding. Clear removes only the namespace's keys.
+//
+// A NamespacedStore has the methods of every optional interface (TTLStore,
+// Scanner, VersionedStore, Watcher and DecodingStore). Each forwards to the
+// underlying store if it supports the call, and returns ErrNotSupported
+// otherwise.
+type NamespacedStore struct {
+	store  Store
+	prefix string
+}
+
+// NewNamespace returns a view of store under the namespace name.
+// Namespaces nest: NewNamespace(NewNamespace(s, "a"), "b") stores key k
+// as "a:b:k".
+func NewNamespace(store Store, name string) *NamespacedStore {
+	return &NamespacedStore{store: store, prefix: name + NamespaceSeparator}
+}
+
+// Prefix returns the prefix added to keys.
+func (n *NamespacedStore) Prefix() string {
+	return n.prefix
+}
+
+// Namespace returns a nested namespace.
+func (n *NamespacedStore) Namespace(name string) *NamespacedStore {
+	return NewNamespace(n, name)
+}
+
+// Put implements the Store interface.
+func (n *NamespacedStore) Put(ctx context.Context, key string, value interface{}) error {
+	return n.store.Put(ctx, n.prefix+key, value)
+}
+
+// PutWithTTL implements the TTLStore interface. It returns ErrNotSupported
+// if the underlying store has no TTL support.
+func (n *NamespacedStore) PutWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	ts, ok := n.store.(TTLStore)
+	if !ok {
+		return fmt.Errorf("PutWithTTL: %w", ErrNotSupported)
+	}
+	return ts.PutWithTTL(ctx, n.prefix+key, value, ttl)
+}
+
+// Get implements the Store interface.
+func (n *NamespacedStore) Get(ctx context.Context, key string) (interface{}, error) {
+	return n.store.Get(ctx, n.prefix+key)
+}
+
+// GetInto implements the DecodingStore interface. It returns
+// ErrNotSupported if the underlying store does not decode values itself.
+func (n *NamespacedStore) GetInto(ctx context.Context, key string, target interface{}) error {
+	ds, ok := n.store.(DecodingStore)
+	if !ok {
+		return fmt.Errorf("GetInto: %w", ErrNotSupported)
+	}
+	return ds.GetInto(ctx, n.prefix+key, target)
+}
+
+// Delete implements the Store interface.
+func (n *NamespacedStore) Delete(ctx context.Context, key string) error {
+	return n.store.Delete(ctx, n.prefix+key)
+}
+
+// Clear implements the Store interface. It deletes only the keys in the
+// namespace, and returns ErrNotSupported if the underlying store can
+// neither delete by prefix nor enumerate its keys.
+func (n *NamespacedStore) Clear(ctx context.Context) error {
+	_, err := n.DeletePrefix(ctx, "")
+	return err
+}
+
+// DeletePrefix deletes the keys in the namespace starting with prefix and
+// returns how many were deleted.
+func (n *NamespacedStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if pd, ok := n.store.(prefixDeleter); ok {
+		return pd.DeletePrefix(ctx, n.prefix+prefix)
+	}
+
+	sc, ok := n.store.(Scanner)
+	if !ok {
+		return 0, fmt.Errorf("namespace clear: %w", ErrNotSupported)
+	}
+	keys, err := List(ctx, sc, n.prefix+prefix)
+	if err != nil {
+		return 0, err
+	}
+	for i, key := range keys {
+		if err := sc.Delete(ctx, key); err != nil {
+			return i, err
+		}
+	}
+	return len(keys), nil
+}
+
+// Scan implements the Scanner interface, returning keys without the
+// namespace prefix. Cursors are those of the underlying store, so pass
+// back only cursors returned by the namespace. It returns ErrNotSupported
+// if the underlying store cannot enumerate its keys.
+func (n *NamespacedStore) Scan(ctx context.Context, prefix, cursor string, limit int) ([]string, string, error) {
+	sc, ok := n.store.(Scanner)
+	if !ok {
+		return nil, "", fmt.Errorf("scan: %w", ErrNotSupported)
+	}
+
+	keys, next, err := sc.Scan(ctx, n.prefix+prefix, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, n.prefix)
+	}
+	return keys, next, nil
+}
+
+// List returns every key in the namespace starting with prefix.
+func (n *NamespacedStore) List(ctx context.Context, prefix string) ([]string, error) {
+	return List(ctx, n, prefix)
+}
+
+// GetVersion implements the VersionedStore interface. It returns
+// ErrNotSupported if the underlying store is not versioned.
+func (n *NamespacedStore) GetVersion(ctx context.Context, key string) (interface{}, uint64, error) {
+	vs, ok := n.store.(VersionedStore)
+	if !ok {
+		return nil, 0, fmt.Errorf("GetVersion: %w", ErrNotSupported)
+	}
+	return vs.GetVersion(ctx, n.prefix+key)
+}
+
+// CompareAndSwap implements the VersionedStore interface. It returns
+// ErrNotSupported if the underlying store is not versioned.
+func (n *NamespacedStore) CompareAndSwap(ctx context.Context, key string, version uint64, value interface{}) (uint64, error) {
+	vs, ok := n.store.(VersionedStore)
+	if !ok {
+		return 0, fmt.Errorf("CompareAndSwap: %w", ErrNotSupported)
+	}
+	return vs.CompareAndSwap(ctx, n.prefix+key, version, value)
+}
+
+// Commit implements the VersionedStore interface. It returns
+// ErrNotSupported if the underlying store is not versioned.
+func (n *NamespacedStore) Commit(ctx context.Context, expect map[string]uint64, writes []Write) error {
+	vs, ok := n.store.(VersionedStore)
+	if !ok {
+		return fmt.Errorf("Commit: %w", ErrNotSupported)
+	}
+
+	prefixed := make(map[string]uint64, len(expect))
+	for key, version := range expect {
+		prefixed[n.prefix+key] = version
+	}
+	ws := make([]Write, len(writes))
+	for i, w := range writes {
+		w.Key = n.prefix + w.Key
+		ws[i] = w
+	}
+	return vs.Commit(ctx, prefixed, ws)
+}
+
+// Watch implements the Watcher interface, reporting keys without the
+// namespace prefix. It returns ErrNotSupported if the underlying store
+// cannot be watched.
+func (n *NamespacedStore) Watch(ctx context.Context, prefix string) (<-chan Event, error) {
+	w, ok := n.store.(Watcher)
+	if !ok {
+		return nil, fmt.Errorf("watch: %w", ErrNotSupported)
+	}
+	events, err := w.Watch(ctx, n.prefix+prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for ev := range events {
+			ev.Key = strings.TrimPrefix(ev.Key, n.prefix)
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNamespace_IsolatesKeys(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	alice := store.Namespace("alice")
+	bob := store.Namespace("bob")
+	alice.Put(ctx, "session", "a")
+	bob.Put(ctx, "session", "b")
+
+	if v, err := alice.Get(ctx, "session"); err != nil || v != "a" {
+		t.Errorf("Expected alice's value, got %v (%v)", v, err)
+	}
+	if v, err := store.Get(ctx, "bob:session"); err != nil || v != "b" {
+		t.Errorf("Expected prefixed key in base store, got %v (%v)", v, err)
+	}
+
+	if err := alice.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alice.Get(ctx, "session"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected alice's keys cleared, got %v", err)
+	}
+	if _, err := bob.Get(ctx, "session"); err != nil {
+		t.Errorf("Expected bob's keys untouched, got %v", err)
+	}
+}
+
+func TestNamespace_ListAndNesting(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	tenant := store.Namespace("tenant")
+	sessions := tenant.Namespace("sessions")
+	sessions.Put(ctx, "s1", 1)
+	sessions.Put(ctx, "s2", 2)
+	tenant.Put(ctx, "profile", "p")
+
+	keys, err := sessions.List(ctx, "")
+	if err != nil || !reflect.DeepEqual(keys, []string{"s1", "s2"}) {
+		t.Errorf("Expected unprefixed session keys, got %v (%v)", keys, err)
+	}
+	keys, _ = tenant.List(ctx, "")
+	if !reflect.DeepEqual(keys, []string{"profile", "sessions:s1", "sessions:s2"}) {
+		t.Errorf("Unexpected tenant keys %v", keys)
+	}
+
+	// Paginate through the view.
+	page, next, _ := sessions.Scan(ctx, "", "", 1)
+	page2, _, _ := sessions.Scan(ctx, "", next, 1)
+	if page[0] != "s1" || page2[0] != "s2" {
+		t.Errorf("Unexpected pages %v %v", page, page2)
+	}
+
+	sessions.Clear(ctx)
+	if keys, _ := store.List(ctx, ""); !reflect.DeepEqual(keys, []string{"tenant:profile"}) {
+		t.Errorf("Expected only the tenant profile left, got %v", keys)
+	}
+}
+
+func TestNamespace_TTL(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+
+	ns := store.Namespace("scratch")
+	if err := ns.PutWithTTL(ctx, "k", 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := store.TTL(ctx, "scratch:k"); ttl <= 0 {
+		t.Error("Expected TTL to pass through to the base store")
+	}
+}
+
+// plainStore is a Store with no optional capabilities.
+type plainStore struct{ Store }
+
+func TestNamespace_UnsupportedCapabilities(t *testing.T) {
+	ns := NewNamespace(plainStore{NewInMemoryStore()}, "x")
+	ctx := context.Background()
+
+	if err := ns.Clear(ctx); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported from Clear, got %v", err)
+	}
+	if err := ns.PutWithTTL(ctx, "k", 1, time.Second); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported from PutWithTTL, got %v", err)
+	}
+	if _, _, err := ns.Scan(ctx, "", "", 0); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported from Scan, got %v", err)
+	}
+	var v int
+	if err := ns.GetInto(ctx, "k", &v); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported from GetInto, got %v", err)
+	}
+}
+
+func TestNamespace_ScanEmptyKey(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	ns := store.Namespace("n")
+	ns.Put(ctx, "", 0)
+	ns.Put(ctx, "a", 1)
+	ns.Put(ctx, "b", 2)
+
+	var keys []string
+	it := Iterate(ns, "", 1)
+	for it.Next(ctx) {
+		keys = append(keys, it.Key())
+	}
+	if it.Err() != nil || !reflect.DeepEqual(keys, []string{"", "a", "b"}) {
+		t.Errorf("Expected every key page by page, got %q (%v)", keys, it.Err())
+	}
+
+}
+
+func TestNamespace_ClearViaScan(t *testing.T) {
+	// A scanner without DeletePrefix is cleared key by key.
+	base := struct{ Scanner }{NewInMemoryStore()}
+	ctx := context.Background()
+	base.Put(ctx, "a:1", 1)
+	base.Put(ctx, "b:1", 1)
+
+	if err := NewNamespace(base, "a").Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := List(ctx, base, ""); !reflect.DeepEqual(keys, []string{"b:1"}) {
+		t.Errorf("Expected only b:1 left, got %v", keys)
+	}
+}
@@ -0,0 +1,434 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisStore is a Store backed by a Redis-compatible server, so that
+// several processes can share state. It speaks RESP directly and keeps a
+// small pool of connections.
+//
+// Keys are stored under an optional prefix, which also scopes Clear, and
+// values are encoded with a Codec. Redis removes expired keys itself, so
+// Get returns ErrNotFound, never ErrExpired, for them.
+type RedisStore struct {
+	addr        string
+	password    string
+	db          int
+	prefix      string
+	codec       Codec
+	defaultTTL  time.Duration
+	dialTimeout time.Duration
+
+	mu     sync.Mutex
+	pool   chan *respConn
+	closed bool
+}
+
+// NewRedisStore creates a store for the server at addr, such as
+// "localhost:6379". Connections are opened on first use.
+func NewRedisStore(addr string, codec Codec) *RedisStore {
+	return &RedisStore{
+		addr:        addr,
+		codec:       codec,
+		dialTimeout: 5 * time.Second,
+		pool:        make(chan *respConn, 10),
+	}
+}
+
+// WithPrefix sets a prefix added to every key, such as "agent:".
+func (s *RedisStore) WithPrefix(prefix string) *RedisStore {
+	s.prefix = prefix
+	return s
+}
+
+// WithPassword sets the password sent with AUTH on connect.
+func (s *RedisStore) WithPassword(password string) *RedisStore {
+	s.password = password
+	return s
+}
+
+// WithDB selects the logical database on connect.
+func (s *RedisStore) WithDB(db int) *RedisStore {
+	s.db = db
+	return s
+}
+
+// WithDefaultTTL sets the TTL applied by Put. Zero disables expiry.
+func (s *RedisStore) WithDefaultTTL(ttl time.Duration) *RedisStore {
+	s.defaultTTL = ttl
+	return s
+}
+
+// WithDialTimeout sets the timeout for opening connections.
+func (s *RedisStore) WithDialTimeout(timeout time.Duration) *RedisStore {
+	s.dialTimeout = timeout
+	return s
+}
+
+// WithPoolSize sets how many idle connections are kept. Configure it
+// before use.
+func (s *RedisStore) WithPoolSize(n int) *RedisStore {
+	s.pool = make(chan *respConn, max(n, 1))
+	return s
+}
+
+// Put implements the Store interface. The value expires after the store's
+// default TTL, if any.
+func (s *RedisStore) Put(ctx context.Context, key string, value interface{}) error {
+	return s.PutWithTTL(ctx, key, value, s.defaultTTL)
+}
+
+// PutWithTTL implements the TTLStore interface. Redis expires keys with
+// millisecond precision.
+func (s *RedisStore) PutWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	cmd, err := s.setCommand(key, value, ttl)
+	if err != nil {
+		return err
+	}
+	return s.exec(ctx, cmd)
+}
+
+// Get implements the Store interface.
+func (s *RedisStore) Get(ctx context.Context, key string) (interface{}, error) {
+	var value interface{}
+	if err := s.GetInto(ctx, key, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetInto decodes the value of key into the variable pointed to by target,
+// letting the codec produce a concrete type instead of a generic one.
+func (s *RedisStore) GetInto(ctx context.Context, key string, target interface{}) error {
+	replies, err := s.do(ctx, []interface{}{"GET", s.prefix + key})
+	if err != nil {
+		return err
+	}
+	return s.decode(key, replies[0], target)
+}
+
+// GetMany returns the values of the keys that exist, fetched in one round
+// trip. Missing keys are omitted from the result.
+func (s *RedisStore) GetMany(ctx context.Context, keys ...string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	cmd := make([]interface{}, 0, len(keys)+1)
+	cmd = append(cmd, "MGET")
+	for _, key := range keys {
+		cmd = append(cmd, s.prefix+key)
+	}
+	replies, err := s.do(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := replies[0].([]interface{})
+	if !ok || len(items) != len(keys) {
+		return nil, fmt.Errorf("unexpected MGET reply %T", replies[0])
+	}
+	for i, item := range items {
+		var value interface{}
+		err := s.decode(keys[i], item, &value)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[keys[i]] = value
+	}
+	return values, nil
+}
+
+// TTL returns the time remaining before key expires, or zero if it never
+// expires.
+func (s *RedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	replies, err := s.do(ctx, []interface{}{"PTTL", s.prefix + key})
+	if err != nil {
+		return 0, err
+	}
+	ms, ok := replies[0].(int64)
+	switch {
+	case !ok:
+		return 0, fmt.Errorf("unexpected PTTL reply %T", replies[0])
+	case ms == -2:
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, key)
+	case ms < 0:
+		return 0, nil
+	default:
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+}
+
+// Delete implements the Store interface.
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.exec(ctx, []interface{}{"DEL", s.prefix + key})
+}
+
+// Clear implements the Store interface. It deletes the keys under the
+// store's prefix, found with SCAN, so with an empty prefix it deletes
+// every key in the database.
+func (s *RedisStore) Clear(ctx context.Context) error {
+	pattern := escapeGlob(s.prefix) + "*"
+	cursor := "0"
+	for {
+		replies, err := s.do(ctx, []interface{}{"SCAN", cursor, "MATCH", pattern, "COUNT", 500})
+		if err != nil {
+			return err
+		}
+		page, ok := replies[0].([]interface{})
+		if !ok || len(page) != 2 {
+			return fmt.Errorf("unexpected SCAN reply %T", replies[0])
+		}
+		next, _ := page[0].([]byte)
+		keys, _ := page[1].([]interface{})
+
+		if len(keys) > 0 {
+			del := append([]interface{}{"DEL"}, keys...)
+			if err := s.exec(ctx, del); err != nil {
+				return err
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// Ping checks that the server is reachable.
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.exec(ctx, []interface{}{"PING"})
+}
+
+// Pipeline returns a pipeline that sends several writes in one round trip.
+func (s *RedisStore) Pipeline() *RedisPipeline {
+	return &RedisPipeline{store: s}
+}
+
+// Close closes idle connections. The store cannot be used afterwards.
+func (s *RedisStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for {
+		select {
+		case c := <-s.pool:
+			c.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// RedisPipeline queues writes to a RedisStore and sends them together.
+// It is not safe for concurrent use.
+type RedisPipeline struct {
+	store *RedisStore
+	cmds  [][]interface{}
+	err   error
+}
+
+// Put queues a Put.
+func (p *RedisPipeline) Put(key string, value interface{}) *RedisPipeline {
+	return p.PutWithTTL(key, value, p.store.defaultTTL)
+}
+
+// PutWithTTL queues a PutWithTTL.
+func (p *RedisPipeline) PutWithTTL(key string, value interface{}, ttl time.Duration) *RedisPipeline {
+	if p.err != nil {
+		return p
+	}
+	cmd, err := p.store.setCommand(key, value, ttl)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.cmds = append(p.cmds, cmd)
+	return p
+}
+
+// Delete queues a Delete.
+func (p *RedisPipeline) Delete(key string) *RedisPipeline {
+	p.cmds = append(p.cmds, []interface{}{"DEL", p.store.prefix + key})
+	return p
+}
+
+// Len returns the number of queued commands.
+func (p *RedisPipeline) Len() int {
+	return len(p.cmds)
+}
+
+// Exec sends the queued commands and returns the first error, if any.
+// Commands are not transactional: those before and after a failed one
+// still take effect. The pipeline is empty afterwards.
+func (p *RedisPipeline) Exec(ctx context.Context) error {
+	cmds, err := p.cmds, p.err
+	p.cmds, p.err = nil, nil
+	if err != nil {
+		return err
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	replies, err := p.store.do(ctx, cmds...)
+	if err != nil {
+		return err
+	}
+	for _, reply := range replies {
+		if err := replyError(reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setCommand builds the SET command storing value under key.
+func (s *RedisStore) setCommand(key string, value interface{}, ttl time.Duration) ([]interface{}, error) {
+	data, err := s.codec.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", key, err)
+	}
+	cmd := []interface{}{"SET", s.prefix + key, data}
+	if ttl > 0 {
+		cmd = append(cmd, "PX", max(ttl.Milliseconds(), 1))
+	}
+	return cmd, nil
+}
+
+// decode decodes a GET reply for key into target.
+func (s *RedisStore) decode(key string, reply, target interface{}) error {
+	if err := replyError(reply); err != nil {
+		return err
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected reply %T for %s", reply, key)
+	}
+	if data == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err := s.codec.Unmarshal(data, target); err != nil {
+		return decodeError(key, err)
+	}
+	return nil
+}
+
+// exec runs a command and returns its error reply, if any.
+func (s *RedisStore) exec(ctx context.Context, cmd []interface{}) error {
+	replies, err := s.do(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return replyError(replies[0])
+}
+
+// do runs commands on a pooled connection.
+func (s *RedisStore) do(ctx context.Context, cmds ...[]interface{}) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := c.do(ctx, cmds...)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	s.release(c)
+	return replies, nil
+}
+
+// conn takes an idle connection from the pool or dials a new one.
+func (s *RedisStore) conn(ctx context.Context) (*respConn, error) {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, errors.New("redis store is closed")
+	}
+
+	select {
+	case c := <-s.pool:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: s.dialTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	c := newRespConn(nc)
+
+	var setup [][]interface{}
+	if s.password != "" {
+		setup = append(setup, []interface{}{"AUTH", s.password})
+	}
+	if s.db != 0 {
+		setup = append(setup, []interface{}{"SELECT", s.db})
+	}
+	if len(setup) > 0 {
+		replies, err := c.do(ctx, setup...)
+		if err == nil {
+			for _, reply := range replies {
+				if err = replyError(reply); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// release returns a healthy connection to the pool, closing it if the pool
+// is full or the store is closed.
+func (s *RedisStore) release(c *respConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		c.Close()
+		return
+	}
+	select {
+	case s.pool <- c:
+	default:
+		c.Close()
+	}
+}
+
+// escapeGlob escapes the Redis glob metacharacters in s.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
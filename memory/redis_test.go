@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/supadev-ai/go-dspy/memory/resptest"
+)
+
+func newTestRedis(t *testing.T) (*resptest.Server, *RedisStore) {
+	t.Helper()
+	server, err := resptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	store := NewRedisStore(server.Addr(), GobCodec{})
+	t.Cleanup(func() { store.Close() })
+	return server, store
+}
+
+func TestRedisStore_PutGetDelete(t *testing.T) {
+	_, store := newTestRedis(t)
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "a", codecRecord{Name: "a", Count: 1}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	v, err := store.Get(ctx, "a")
+	if err != nil || v != (codecRecord{Name: "a", Count: 1}) {
+		t.Errorf("Expected stored record, got %v (%v)", v, err)
+	}
+
+	var typed codecRecord
+	if err := store.GetInto(ctx, "a", &typed); err != nil || typed.Count != 1 {
+		t.Errorf("Expected typed value, got %+v (%v)", typed, err)
+	}
+
+	store.Delete(ctx, "a")
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRedisStore_PrefixAndClear(t *testing.T) {
+	server, err := resptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	ctx := context.Background()
+
+	tenantA := NewRedisStore(server.Addr(), JSONCodec{}).WithPrefix("tenant*a:")
+	tenantB := NewRedisStore(server.Addr(), JSONCodec{}).WithPrefix("tenant-b:")
+	defer tenantA.Close()
+	defer tenantB.Close()
+
+	for i := 0; i < 30; i++ {
+		tenantA.Put(ctx, strings.Repeat("k", i+1), i)
+	}
+	tenantB.Put(ctx, "k", "kept")
+
+	if err := tenantA.Clear(ctx); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if keys := server.Keys(); len(keys) != 1 || keys[0] != "tenant-b:k" {
+		t.Errorf("Expected only tenant-b key to remain, got %v", keys)
+	}
+	if v, err := tenantB.Get(ctx, "k"); err != nil || v != "kept" {
+		t.Errorf("Expected other prefix untouched, got %v (%v)", v, err)
+	}
+}
+
+func TestRedisStore_TTL(t *testing.T) {
+	_, store := newTestRedis(t)
+	ctx := context.Background()
+
+	store.PutWithTTL(ctx, "short", "x", 30*time.Millisecond)
+	store.Put(ctx, "forever", "y")
+
+	if ttl, err := store.TTL(ctx, "short"); err != nil || ttl <= 0 || ttl > 30*time.Millisecond {
+		t.Errorf("Expected TTL up to 30ms, got %v (%v)", ttl, err)
+	}
+	if ttl, err := store.TTL(ctx, "forever"); err != nil || ttl != 0 {
+		t.Errorf("Expected no TTL, got %v (%v)", ttl, err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := store.Get(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected expired key to be gone, got %v", err)
+	}
+	if _, err := store.TTL(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound from TTL, got %v", err)
+	}
+}
+
+func TestRedisStore_Pipeline(t *testing.T) {
+	server, store := newTestRedis(t)
+	ctx := context.Background()
+
+	if err := store.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	before := server.Commands()
+
+	p := store.Pipeline()
+	for i := 0; i < 5; i++ {
+		p.Put(string(rune('a'+i)), i)
+	}
+	p.Delete("e")
+	if p.Len() != 6 {
+		t.Errorf("Expected 6 queued commands, got %d", p.Len())
+	}
+	if err := p.Exec(ctx); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if got := server.Commands() - before; got != 6 {
+		t.Errorf("Expected 6 commands sent, got %d", got)
+	}
+
+	values, err := store.GetMany(ctx, "a", "c", "e", "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values["a"] != 0 || values["c"] != 2 {
+		t.Errorf("Unexpected values %v", values)
+	}
+}
+
+func TestRedisStore_Password(t *testing.T) {
+	server, err := resptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.WithPassword("secret")
+	ctx := context.Background()
+
+	anonymous := NewRedisStore(server.Addr(), JSONCodec{})
+	defer anonymous.Close()
+	if err := anonymous.Put(ctx, "k", 1); err == nil || !strings.Contains(err.Error(), "NOAUTH") {
+		t.Errorf("Expected NOAUTH error, got %v", err)
+	}
+
+	authed := NewRedisStore(server.Addr(), JSONCodec{}).WithPassword("secret").WithDB(2)
+	defer authed.Close()
+	if err := authed.Put(ctx, "k", 1); err != nil {
+		t.Errorf("Expected authenticated Put to succeed, got %v", err)
+	}
+}
+
+func TestRedisStore_ServerGone(t *testing.T) {
+	server, store := newTestRedis(t)
+	ctx := context.Background()
+
+	store.Put(ctx, "k", 1)
+	server.Close()
+
+	if _, err := store.Get(ctx, "k"); err == nil {
+		t.Error("Expected error after server shut down")
+	}
+}
+
+func TestRedisStore_ContextCancel(t *testing.T) {
+	_, store := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := store.Put(ctx, "k", 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
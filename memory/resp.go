@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisError is an error reply from a Redis server.
+type RedisError string
+
+// Error implements the error interface.
+func (e RedisError) Error() string { return string(e) }
+
+// respConn is a connection speaking RESP, the Redis serialization protocol.
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// newRespConn wraps conn.
+func newRespConn(conn net.Conn) *respConn {
+	return &respConn{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}
+}
+
+// do sends commands in one round trip and returns their replies in order.
+// Error replies are returned as RedisError values among the replies; the
+// returned error is only set for connection and protocol failures, after
+// which the connection must be discarded.
+func (c *respConn) do(ctx context.Context, cmds ...[]interface{}) ([]interface{}, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetDeadline(deadline)
+	} else {
+		c.conn.SetDeadline(time.Time{})
+	}
+	stop := context.AfterFunc(ctx, func() {
+		c.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	for _, cmd := range cmds {
+		if err := writeCommand(c.w, cmd); err != nil {
+			return nil, c.fail(ctx, err)
+		}
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, c.fail(ctx, err)
+	}
+
+	replies := make([]interface{}, len(cmds))
+	for i := range replies {
+		reply, err := readReply(c.r)
+		if err != nil {
+			return nil, c.fail(ctx, err)
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+// fail reports err, preferring the context's error if it caused it.
+func (c *respConn) fail(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// Close closes the connection.
+func (c *respConn) Close() error {
+	return c.conn.Close()
+}
+
+// writeCommand writes a command as a RESP array of bulk strings.
+// Arguments may be strings, byte slices or integers.
+func writeCommand(w *bufio.Writer, args []interface{}) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		case int:
+			b = strconv.AppendInt(nil, int64(v), 10)
+		case int64:
+			b = strconv.AppendInt(nil, v, 10)
+		default:
+			return fmt.Errorf("unsupported command argument %T", arg)
+		}
+		fmt.Fprintf(w, "$%d\r\n", len(b))
+		w.Write(b)
+		if _, err := w.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readReply reads one RESP reply. Simple strings are returned as string,
+// bulk strings as []byte (nil for a null reply), integers as int64, arrays
+// as []interface{} and error replies as RedisError.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	body := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return RedisError(body), nil
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed bulk length %q", body)
+		}
+		if n < 0 {
+			return []byte(nil), nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed array length %q", body)
+		}
+		if n < 0 {
+			return []interface{}(nil), nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", line[0])
+	}
+}
+
+// replyError returns the reply as an error if it is an error reply.
+func replyError(reply interface{}) error {
+	if err, ok := reply.(RedisError); ok {
+		return err
+	}
+	return nil
+}
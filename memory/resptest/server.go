@@ -0,0 +1,528 @@
+// Package resptest provides a small in-process server speaking the Redis
+// serialization protocol (RESP), for testing code that uses Redis-backed
+// stores without a real Redis.
+//
+// It implements the subset of commands used by memory.RedisStore: PING,
+// AUTH, SELECT, SET (with EX, PX, NX and XX), GET, MGET, DEL, EXISTS,
+// PEXPIRE, PTTL, SCAN, KEYS, DBSIZE and FLUSHDB. All databases share one
+// keyspace.
+package resptest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// item is a stored string value with its expiry time.
+type item struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// Server is an in-process RESP server. It is safe for concurrent use.
+type Server struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string]item
+	commands int
+	conns    map[net.Conn]bool
+	wg       sync.WaitGroup
+	closed   bool
+}
+
+// NewServer starts a server listening on a random local port.
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: l,
+		data:     make(map[string]item),
+		conns:    make(map[net.Conn]bool),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// WithPassword requires clients to AUTH with password.
+func (s *Server) WithPassword(password string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.password = password
+	return s
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Commands returns the number of commands processed so far.
+func (s *Server) Commands() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands
+}
+
+// Keys returns the live keys, sorted.
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keysLocked("*")
+}
+
+// Close stops the server and closes all client connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+// serve accepts connections until the listener is closed.
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+// handle serves one client connection.
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	authed := false
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				writeError(w, "ERR Protocol error: "+err.Error())
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		s.mu.Lock()
+		s.commands++
+		password := s.password
+		s.mu.Unlock()
+
+		name := strings.ToUpper(string(args[0]))
+		switch {
+		case name == "AUTH":
+			if len(args) == 2 && string(args[1]) == password {
+				authed = true
+				writeSimple(w, "OK")
+			} else {
+				writeError(w, "WRONGPASS invalid password")
+			}
+		case password != "" && !authed:
+			writeError(w, "NOAUTH Authentication required.")
+		default:
+			s.execute(w, name, args[1:])
+		}
+
+		// Only flush once the client has no more pipelined commands.
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// execute runs one command and writes its reply.
+func (s *Server) execute(w *bufio.Writer, name string, args [][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	switch name {
+	case "PING":
+		writeSimple(w, "PONG")
+	case "SELECT":
+		writeSimple(w, "OK")
+	case "SET":
+		s.set(w, args, now)
+	case "GET":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return
+		}
+		if it, ok := s.lookup(string(args[0]), now); ok {
+			writeBulk(w, it.value)
+		} else {
+			writeBulk(w, nil)
+		}
+	case "MGET":
+		fmt.Fprintf(w, "*%d\r\n", len(args))
+		for _, key := range args {
+			if it, ok := s.lookup(string(key), now); ok {
+				writeBulk(w, it.value)
+			} else {
+				writeBulk(w, nil)
+			}
+		}
+	case "DEL", "EXISTS":
+		n := 0
+		for _, key := range args {
+			if _, ok := s.lookup(string(key), now); ok {
+				n++
+				if name == "DEL" {
+					delete(s.data, string(key))
+				}
+			}
+		}
+		writeInt(w, int64(n))
+	case "PEXPIRE":
+		if len(args) != 2 {
+			writeArity(w, name)
+			return
+		}
+		ms, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		it, ok := s.lookup(string(args[0]), now)
+		if !ok {
+			writeInt(w, 0)
+			return
+		}
+		it.expiresAt = now.Add(time.Duration(ms) * time.Millisecond)
+		s.data[string(args[0])] = it
+		writeInt(w, 1)
+	case "PTTL":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return
+		}
+		it, ok := s.lookup(string(args[0]), now)
+		switch {
+		case !ok:
+			writeInt(w, -2)
+		case it.expiresAt.IsZero():
+			writeInt(w, -1)
+		default:
+			writeInt(w, it.expiresAt.Sub(now).Milliseconds())
+		}
+	case "SCAN":
+		s.scan(w, args)
+	case "KEYS":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return
+		}
+		keys := s.keysLocked(string(args[0]))
+		fmt.Fprintf(w, "*%d\r\n", len(keys))
+		for _, k := range keys {
+			writeBulk(w, []byte(k))
+		}
+	case "DBSIZE":
+		writeInt(w, int64(len(s.keysLocked("*"))))
+	case "FLUSHDB", "FLUSHALL":
+		s.data = make(map[string]item)
+		writeSimple(w, "OK")
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", name))
+	}
+}
+
+// set implements SET key value [EX seconds|PX milliseconds] [NX|XX].
+func (s *Server) set(w *bufio.Writer, args [][]byte, now time.Time) {
+	if len(args) < 2 {
+		writeArity(w, "SET")
+		return
+	}
+	key := string(args[0])
+	it := item{value: append([]byte(nil), args[1]...)}
+	nx, xx := false, false
+
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Millisecond
+			if strings.EqualFold(string(args[i]), "EX") {
+				unit = time.Second
+			}
+			it.expiresAt = now.Add(time.Duration(n) * unit)
+			i++
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+
+	_, exists := s.lookup(key, now)
+	if (nx && exists) || (xx && !exists) {
+		writeBulk(w, nil)
+		return
+	}
+	s.data[key] = it
+	writeSimple(w, "OK")
+}
+
+// scan implements SCAN cursor [MATCH pattern] [COUNT count]. The cursor is
+// an offset into the sorted keys.
+func (s *Server) scan(w *bufio.Writer, args [][]byte) {
+	if len(args) < 1 {
+		writeArity(w, "SCAN")
+		return
+	}
+	cursor, err := strconv.Atoi(string(args[0]))
+	if err != nil || cursor < 0 {
+		writeError(w, "ERR invalid cursor")
+		return
+	}
+	pattern, count := "*", 10
+	for i := 1; i+1 < len(args); i += 2 {
+		switch strings.ToUpper(string(args[i])) {
+		case "MATCH":
+			pattern = string(args[i+1])
+		case "COUNT":
+			count, _ = strconv.Atoi(string(args[i+1]))
+		}
+	}
+
+	all := s.keysLocked("*")
+	end := min(cursor+max(count, 1), len(all))
+	var keys []string
+	for _, k := range all[min(cursor, len(all)):end] {
+		if globMatch(pattern, k) {
+			keys = append(keys, k)
+		}
+	}
+	next := end
+	if next >= len(all) {
+		next = 0
+	}
+
+	fmt.Fprintf(w, "*2\r\n")
+	writeBulk(w, []byte(strconv.Itoa(next)))
+	fmt.Fprintf(w, "*%d\r\n", len(keys))
+	for _, k := range keys {
+		writeBulk(w, []byte(k))
+	}
+}
+
+// lookup returns the live item for key, deleting it if it has expired.
+// The caller must hold the lock.
+func (s *Server) lookup(key string, now time.Time) (item, bool) {
+	it, ok := s.data[key]
+	if !ok {
+		return item{}, false
+	}
+	if !it.expiresAt.IsZero() && !now.Before(it.expiresAt) {
+		delete(s.data, key)
+		return item{}, false
+	}
+	return it, true
+}
+
+// keysLocked returns the sorted live keys matching a glob pattern.
+// The caller must hold the lock.
+func (s *Server) keysLocked(pattern string) []string {
+	now := time.Now()
+	var keys []string
+	for k := range s.data {
+		if _, ok := s.lookup(k, now); !ok {
+			continue
+		}
+		if globMatch(pattern, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// globMatch reports whether s matches a Redis glob pattern, supporting
+// *, ?, [...] character classes (with ^ negation and ranges) and \ escapes.
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+			continue
+		case '[':
+			end := strings.IndexByte(pattern[1:], ']')
+			if end >= 0 && len(s) > 0 {
+				class := pattern[1 : end+1]
+				if !classMatch(class, s[0]) {
+					return false
+				}
+				pattern, s = pattern[end+2:], s[1:]
+				continue
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+		}
+		if len(s) == 0 || s[0] != pattern[0] {
+			return false
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return len(s) == 0
+}
+
+// classMatch reports whether c is in a glob character class.
+func classMatch(class string, c byte) bool {
+	negate := strings.HasPrefix(class, "^")
+	if negate {
+		class = class[1:]
+	}
+	matched := false
+	for i := 0; i < len(class); i++ {
+		if class[i] == '\\' && i+1 < len(class) {
+			i++
+		}
+		if i+2 < len(class) && class[i+1] == '-' {
+			if class[i] <= c && c <= class[i+2] {
+				matched = true
+			}
+			i += 2
+			continue
+		}
+		if class[i] == c {
+			matched = true
+		}
+	}
+	return matched != negate
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings.
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("expected array, got %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid array length %q", line)
+	}
+
+	args := make([][]byte, n)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("expected bulk string, got %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid bulk length %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = buf[:size]
+	}
+	return args, nil
+}
+
+// readLine reads a CRLF-terminated line without the terminator.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+func writeSimple(w *bufio.Writer, s string) { fmt.Fprintf(w, "+%s\r\n", s) }
+
+func writeError(w *bufio.Writer, msg string) { fmt.Fprintf(w, "-%s\r\n", msg) }
+
+func writeInt(w *bufio.Writer, n int64) { fmt.Fprintf(w, ":%d\r\n", n) }
+
+func writeArity(w *bufio.Writer, name string) {
+	writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
+
+// writeBulk writes a bulk string, or a null bulk string for nil.
+func writeBulk(w *bufio.Writer, b []byte) {
+	if b == nil {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	fmt.Fprintf(w, "$%d\r\n", len(b))
+	w.Write(b)
+	w.WriteString("\r\n")
+}
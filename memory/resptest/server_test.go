@@ -0,0 +1,88 @@
+package resptest
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestServer_Commands(t *testing.T) {
+	server, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	send := func(cmd string) string {
+		t.Helper()
+		args := strings.Fields(cmd)
+		var b strings.Builder
+		b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+		for _, a := range args {
+			b.WriteString("$" + strconv.Itoa(len(a)) + "\r\n" + a + "\r\n")
+		}
+		if _, err := conn.Write([]byte(b.String())); err != nil {
+			t.Fatal(err)
+		}
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(line, "$") && line != "$-1\r\n" {
+			value, _ := r.ReadString('\n')
+			return strings.TrimSpace(value)
+		}
+		return strings.TrimSpace(line)
+	}
+
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"PING", "+PONG"},
+		{"SET a 1", "+OK"},
+		{"SET a 2 NX", "$-1"},
+		{"GET a", "1"},
+		{"EXISTS a b", ":1"},
+		{"PEXPIRE a 100000", ":1"},
+		{"DEL a", ":1"},
+		{"GET a", "$-1"},
+		{"NOPE", "-ERR unknown command 'NOPE'"},
+	}
+	for _, tt := range tests {
+		if got := send(tt.cmd); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.cmd, tt.want, got)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "anything/with:colons", true},
+		{"user:*", "user:1", true},
+		{"user:*", "users:1", false},
+		{"h?llo", "hello", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("GlobMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
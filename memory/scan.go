@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotSupported is returned when a store lacks an optional capability.
+var ErrNotSupported = errors.New("operation not supported by store")
+
+// Scanner is a Store whose keys can be enumerated.
+type Scanner interface {
+	Store
+
+	// Scan returns up to limit live keys starting with prefix, in
+	// lexicographic order, from cursor on. Pass an empty cursor for the
+	// first page and the returned next cursor, which is opaque, for the
+	// following ones; next is empty after the last page. A limit of zero
+	// or less means no limit.
+	Scan(ctx context.Context, prefix, cursor string, limit int) (keys []string, next string, err error)
+}
+
+// List returns every live key in s starting with prefix, in lexicographic
+// order.
+func List(ctx context.Context, s Scanner, prefix string) ([]string, error) {
+	var all []string
+	it := Iterate(s, prefix, 1000)
+	for it.Next(ctx) {
+		all = append(all, it.Key())
+	}
+	return all, it.Err()
+}
+
+// Iterator walks the keys of a Scanner page by page. Use it like
+// bufio.Scanner:
+//
+//	it := memory.Iterate(store, "session:", 100)
+//	for it.Next(ctx) {
+//		fmt.Println(it.Key())
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator struct {
+	scanner  Scanner
+	prefix   string
+	pageSize int
+
+	page   []string
+	pos    int
+	cursor string
+	done   bool
+	err    error
+}
+
+// Iterate returns an iterator over the keys of s starting with prefix,
+// fetching pageSize keys at a time.
+func Iterate(s Scanner, prefix string, pageSize int) *Iterator {
+	return &Iterator{scanner: s, prefix: prefix, pageSize: pageSize}
+}
+
+// Next advances to the next key, fetching a new page when needed. It
+// returns false when the keys are exhausted or an error occurs.
+func (it *Iterator) Next(ctx context.Context) bool {
+	for it.pos >= len(it.page) {
+		if it.done || it.err != nil {
+			return false
+		}
+		keys, next, err := it.scanner.Scan(ctx, it.prefix, it.cursor, it.pageSize)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.pos, it.cursor = keys, 0, next
+		it.done = next == ""
+	}
+	it.pos++
+	return true
+}
+
+// Key returns the current key.
+func (it *Iterator) Key() string {
+	if it.pos == 0 || it.pos > len(it.page) {
+		return ""
+	}
+	return it.page[it.pos-1]
+}
+
+// Err returns the first error encountered.
+func (it *Iterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInMemoryStore_Scan(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		store.Put(ctx, fmt.Sprintf("session:%d", i), i)
+	}
+	store.Put(ctx, "user:1", "u")
+
+	keys, next, err := store.Scan(ctx, "session:", "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"session:0", "session:1"}) || next == "" {
+		t.Errorf("Unexpected first page %v, next %q", keys, next)
+	}
+
+	keys, next, _ = store.Scan(ctx, "session:", next, 2)
+	if !reflect.DeepEqual(keys, []string{"session:2", "session:3"}) {
+		t.Errorf("Unexpected second page %v", keys)
+	}
+	keys, next, _ = store.Scan(ctx, "session:", next, 2)
+	if !reflect.DeepEqual(keys, []string{"session:4"}) || next != "" {
+		t.Errorf("Unexpected last page %v, next %q", keys, next)
+	}
+}
+
+func TestInMemoryStore_ScanEmptyKey(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	store.Put(ctx, "", 0)
+	store.Put(ctx, "a", 1)
+
+	keys, next, _ := store.Scan(ctx, "", "", 1)
+	if !reflect.DeepEqual(keys, []string{""}) || next == "" {
+		t.Fatalf("Expected the empty key and a cursor, got %q, next %q", keys, next)
+	}
+	if keys, _, _ := store.Scan(ctx, "", next, 1); !reflect.DeepEqual(keys, []string{"a"}) {
+		t.Errorf("Expected a on the second page, got %q", keys)
+	}
+}
+
+func TestInMemoryStore_ScanSkipsExpired(t *testing.T) {
+	clock := newFakeClock()
+	store := NewInMemoryStore()
+	store.now = clock.Now
+	ctx := context.Background()
+
+	store.PutWithTTL(ctx, "a", 1, time.Second)
+	store.Put(ctx, "b", 2)
+	clock.Advance(time.Second)
+
+	keys, err := store.List(ctx, "")
+	if err != nil || !reflect.DeepEqual(keys, []string{"b"}) {
+		t.Errorf("Expected only live key b, got %v (%v)", keys, err)
+	}
+}
+
+func TestIterator(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		store.Put(ctx, fmt.Sprintf("k%d", i), i)
+	}
+
+	var keys []string
+	it := Iterate(store, "k", 3)
+	for it.Next(ctx) {
+		keys = append(keys, it.Key())
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if len(keys) != 7 || keys[0] != "k0" || keys[6] != "k6" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	it = Iterate(store, "k", 3)
+	if it.Next(cancelled) || it.Err() == nil {
+		t.Error("Expected iteration to stop with the context error")
+	}
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supadev-ai/go-dspy/index"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// Recollection is a stored value recalled by semantic similarity.
+type Recollection struct {
+	Key   string
+	Text  string
+	Score float64
+	Value interface{}
+}
+
+// SemanticStore wraps a Store with a vector index so that values can be
+// recalled by the meaning of an associated text, for example letting an
+// agent find past interactions relevant to the current question.
+// Values written with Put are stored but not indexed; use Remember.
+type SemanticStore struct {
+	Store
+	index    index.VectorIndex
+	embedder llm.Embedder
+}
+
+// NewSemanticStore creates a semantic store over store, indexing texts in
+// idx with embeddings from embedder.
+func NewSemanticStore(store Store, idx index.VectorIndex, embedder llm.Embedder) *SemanticStore {
+	return &SemanticStore{
+		Store:    store,
+		index:    idx,
+		embedder: embedder,
+	}
+}
+
+// Remember stores value under key and indexes text for recall.
+func (s *SemanticStore) Remember(ctx context.Context, key, text string, value interface{}) error {
+	vectors, err := s.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return fmt.Errorf("embed memory: %w", err)
+	}
+	if len(vectors) != 1 {
+		return fmt.Errorf("expected 1 embedding, got %d", len(vectors))
+	}
+
+	if err := s.Store.Put(ctx, key, value); err != nil {
+		return err
+	}
+	return s.index.Upsert(index.Document{ID: key, Text: text, Vector: vectors[0]})
+}
+
+// Recall returns up to k remembered values whose text is most similar to
+// query, ordered by descending similarity. Entries whose value is no longer
+// in the underlying store are skipped.
+func (s *SemanticStore) Recall(ctx context.Context, query string, k int) ([]Recollection, error) {
+	vectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("expected 1 embedding, got %d", len(vectors))
+	}
+
+	var recalled []Recollection
+	for _, r := range s.index.Search(vectors[0], k, nil) {
+		value, err := s.Store.Get(ctx, r.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		recalled = append(recalled, Recollection{
+			Key:   r.ID,
+			Text:  r.Text,
+			Score: r.Score,
+			Value: value,
+		})
+	}
+	return recalled, nil
+}
+
+// Delete removes the value and its index entry.
+func (s *SemanticStore) Delete(ctx context.Context, key string) error {
+	if err := s.Store.Delete(ctx, key); err != nil {
+		return err
+	}
+	s.index.Delete(key)
+	return nil
+}
+
+// Clear removes all values and index entries.
+func (s *SemanticStore) Clear(ctx context.Context) error {
+	if err := s.Store.Clear(ctx); err != nil {
+		return err
+	}
+	s.index.Clear()
+	return nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/index"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+func TestSemanticStore_RememberAndRecall(t *testing.T) {
+	store := NewSemanticStore(NewInMemoryStore(), index.NewFlat(index.Cosine), llm.NewHashEmbedder(128))
+	ctx := context.Background()
+
+	store.Remember(ctx, "turn-1", "user asked about the weather in Paris", map[string]string{"answer": "sunny"})
+	store.Remember(ctx, "turn-2", "user asked how to bake sourdough bread", "use a starter")
+	store.Remember(ctx, "turn-3", "user asked about Go channels", "they are typed conduits")
+
+	recalled, err := store.Recall(ctx, "how do I bake bread", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(recalled) != 1 || recalled[0].Key != "turn-2" || recalled[0].Value != "use a starter" {
+		t.Errorf("Expected bread memory, got %+v", recalled)
+	}
+
+	// Values remain reachable through the Store interface.
+	if v, err := store.Get(ctx, "turn-3"); err != nil || v != "they are typed conduits" {
+		t.Errorf("Expected stored value, got %v (%v)", v, err)
+	}
+}
+
+func TestSemanticStore_DeleteAndClear(t *testing.T) {
+	idx := index.NewFlat(index.Cosine)
+	store := NewSemanticStore(NewInMemoryStore(), idx, llm.NewHashEmbedder(64))
+	ctx := context.Background()
+
+	store.Remember(ctx, "a", "alpha", 1)
+	store.Remember(ctx, "b", "beta", 2)
+
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if idx.Len() != 1 {
+		t.Errorf("Expected index entry to be deleted, got %d entries", idx.Len())
+	}
+
+	if err := store.Clear(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	recalled, _ := store.Recall(ctx, "beta", 5)
+	if len(recalled) != 0 || idx.Len() != 0 {
+		t.Errorf("Expected nothing after Clear, got %v", recalled)
+	}
+}
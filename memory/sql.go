@@ -0,0 +1,357 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SQLDialect selects the SQL syntax used by SQLStore.
+type SQLDialect int
+
+const (
+	// SQLite uses ? placeholders and INSERT ... ON CONFLICT upserts.
+	SQLite SQLDialect = iota
+	// Postgres uses $n placeholders, a JSONB value column and
+	// INSERT ... ON CONFLICT upserts.
+	Postgres
+	// MySQL uses ? placeholders, a JSON value column and
+	// INSERT ... ON DUPLICATE KEY UPDATE upserts.
+	MySQL
+)
+
+// String returns the name of the dialect.
+func (d SQLDialect) String() string {
+	switch d {
+	case SQLite:
+		return "sqlite"
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	default:
+		return "unknown"
+	}
+}
+
+// placeholder returns the placeholder for the nth argument, counting from 1.
+func (d SQLDialect) placeholder(n int) string {
+	if d == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// jsonType returns the column type for JSON values.
+func (d SQLDialect) jsonType() string {
+	switch d {
+	case Postgres:
+		return "JSONB"
+	case MySQL:
+		return "JSON"
+	default:
+		return "TEXT"
+	}
+}
+
+// SQLOptions configures a SQLStore.
+type SQLOptions struct {
+	Dialect SQLDialect
+	// Table is the name of the table holding entries. A table named
+	// Table + "_schema" records applied migrations.
+	Table string
+	// Namespace scopes keys, so several stores can share one table.
+	Namespace string
+}
+
+// DefaultSQLOptions returns options for a SQLite table named "dspy_memory"
+// in the "default" namespace.
+func DefaultSQLOptions() SQLOptions {
+	return SQLOptions{
+		Dialect:   SQLite,
+		Table:     "dspy_memory",
+		Namespace: "default",
+	}
+}
+
+// tableName matches safe table names.
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLStore is a Store backed by a database/sql database, such as Postgres,
+// MySQL or SQLite through their drivers.
+//
+// Entries live in one table keyed by namespace and key, with the value in
+// a JSON column and an optional expiry time. Values are stored as JSON, so
+// Get returns generic JSON values; use GetInto to decode into a concrete
+// type. Expired entries read as ErrExpired until DeleteExpired, or the
+// janitor started with StartJanitor, removes them.
+type SQLStore struct {
+	db      *sql.DB
+	opts    SQLOptions
+	now     func() time.Time
+	janitor janitor
+
+	upsertSQL string
+	getSQL    string
+	deleteSQL string
+	clearSQL  string
+	prefixSQL string
+	expireSQL string
+}
+
+// OpenSQLStore creates a store over db and applies any pending schema
+// migrations.
+func OpenSQLStore(ctx context.Context, db *sql.DB, opts SQLOptions) (*SQLStore, error) {
+	if opts.Table == "" {
+		opts.Table = DefaultSQLOptions().Table
+	}
+	if !tableName.MatchString(opts.Table) {
+		return nil, fmt.Errorf("invalid table name %q", opts.Table)
+	}
+
+	s := &SQLStore{db: db, opts: opts, now: time.Now}
+	s.prepareQueries()
+	if err := s.migrate(ctx); err != nil {
+		return nil, fmt.Errorf("migrate %s: %w", opts.Table, err)
+	}
+	return s, nil
+}
+
+// prepareQueries builds the statements for the store's dialect and table.
+func (s *SQLStore) prepareQueries() {
+	d, t := s.opts.Dialect, s.opts.Table
+	p := d.placeholder
+
+	s.upsertSQL = fmt.Sprintf(
+		"INSERT INTO %s (namespace, item_key, value, expires_at, updated_at) VALUES (%s, %s, %s, %s, %s) ",
+		t, p(1), p(2), p(3), p(4), p(5))
+	if d == MySQL {
+		s.upsertSQL += "ON DUPLICATE KEY UPDATE value = VALUES(value), expires_at = VALUES(expires_at), updated_at = VALUES(updated_at)"
+	} else {
+		s.upsertSQL += "ON CONFLICT (namespace, item_key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at, updated_at = excluded.updated_at"
+	}
+
+	s.getSQL = fmt.Sprintf("SELECT value, expires_at FROM %s WHERE namespace = %s AND item_key = %s", t, p(1), p(2))
+	s.deleteSQL = fmt.Sprintf("DELETE FROM %s WHERE namespace = %s AND item_key = %s", t, p(1), p(2))
+	s.clearSQL = fmt.Sprintf("DELETE FROM %s WHERE namespace = %s", t, p(1))
+	s.prefixSQL = fmt.Sprintf("DELETE FROM %s WHERE namespace = %s AND item_key LIKE %s ESCAPE '!'", t, p(1), p(2))
+	s.expireSQL = fmt.Sprintf("DELETE FROM %s WHERE namespace = %s AND expires_at IS NOT NULL AND expires_at <= %s", t, p(1), p(2))
+}
+
+// migrations returns the schema changes in order. Each is applied once,
+// in a transaction, and recorded in the schema table. They are written to
+// be harmless if repeated, as far as the dialect allows, since concurrent
+// openers may both apply them.
+func (s *SQLStore) migrations() []string {
+	t, d := s.opts.Table, s.opts.Dialect
+	ifNotExists := "IF NOT EXISTS "
+	if d == MySQL {
+		// MySQL has no CREATE INDEX IF NOT EXISTS.
+		ifNotExists = ""
+	}
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	namespace VARCHAR(191) NOT NULL,
+	item_key VARCHAR(191) NOT NULL,
+	value %s NOT NULL,
+	expires_at BIGINT,
+	updated_at BIGINT NOT NULL,
+	PRIMARY KEY (namespace, item_key)
+)`, t, d.jsonType()),
+		fmt.Sprintf("CREATE INDEX %s%s_expires_at ON %s (expires_at)", ifNotExists, t, t),
+	}
+}
+
+// migrate creates the schema table if needed and applies pending
+// migrations. Other processes may be migrating the same table at once: a
+// migration that another opener has recorded by the time it is applied
+// is skipped, and one that fails is treated as applied if another opener
+// has since recorded it.
+func (s *SQLStore) migrate(ctx context.Context) error {
+	schema := s.opts.Table + "_schema"
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version INTEGER NOT NULL PRIMARY KEY, applied_at BIGINT NOT NULL)", schema)); err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(ctx, s.db, schema)
+	if err != nil {
+		return err
+	}
+
+	for i, stmt := range s.migrations() {
+		version := i + 1
+		if version <= current {
+			continue
+		}
+		if err := s.applyMigration(ctx, schema, version, stmt); err != nil {
+			if applied, verr := schemaVersion(ctx, s.db, schema); verr == nil && applied >= version {
+				continue
+			}
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration applies stmt as the given schema version and records it,
+// in one transaction, unless the version is already recorded.
+func (s *SQLStore) applyMigration(ctx context.Context, schema string, version int, stmt string) error {
+	p := s.opts.Dialect.placeholder
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := schemaVersion(ctx, tx, schema)
+	if err != nil {
+		return err
+	}
+	if current >= version {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, applied_at) VALUES (%s, %s)", schema, p(1), p(2)),
+		version, s.now().UnixMilli()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// schemaVersion returns the highest migration recorded in schema.
+func schemaVersion(ctx context.Context, q rowQuerier, schema string) (int, error) {
+	var version int
+	err := q.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", schema)).Scan(&version)
+	return version, err
+}
+
+// Put implements the Store interface.
+func (s *SQLStore) Put(ctx context.Context, key string, value interface{}) error {
+	return s.PutWithTTL(ctx, key, value, 0)
+}
+
+// PutWithTTL implements the TTLStore interface. Expiry times are stored
+// with millisecond precision.
+func (s *SQLStore) PutWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+
+	now := s.now()
+	var expires sql.NullInt64
+	if ttl > 0 {
+		expires = sql.NullInt64{Int64: now.Add(ttl).UnixMilli(), Valid: true}
+	}
+	_, err = s.db.ExecContext(ctx, s.upsertSQL, s.opts.Namespace, key, string(data), expires, now.UnixMilli())
+	return err
+}
+
+// Get implements the Store interface.
+func (s *SQLStore) Get(ctx context.Context, key string) (interface{}, error) {
+	var value interface{}
+	if err := s.GetInto(ctx, key, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetInto decodes the JSON value of key into the variable pointed to by
+// target.
+func (s *SQLStore) GetInto(ctx context.Context, key string, target interface{}) error {
+	var data []byte
+	var expires sql.NullInt64
+	err := s.db.QueryRowContext(ctx, s.getSQL, s.opts.Namespace, key).Scan(&data, &expires)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return err
+	}
+	if expires.Valid && s.now().UnixMilli() >= expires.Int64 {
+		return fmt.Errorf("%w: %s", ErrExpired, key)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return decodeError(key, err)
+	}
+	return nil
+}
+
+// Delete implements the Store interface.
+func (s *SQLStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.deleteSQL, s.opts.Namespace, key)
+	return err
+}
+
+// Clear implements the Store interface. It removes only the entries in
+// the store's namespace.
+func (s *SQLStore) Clear(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.clearSQL, s.opts.Namespace)
+	return err
+}
+
+// likeEscaper escapes LIKE wildcards with '!', which needs no escaping in
+// string literals of any supported dialect.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// DeletePrefix deletes the keys in the store's namespace starting with
+// prefix and returns how many were deleted. It lets a NamespacedStore over
+// the store be cleared.
+func (s *SQLStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	res, err := s.db.ExecContext(ctx, s.prefixSQL, s.opts.Namespace, likeEscaper.Replace(prefix)+"%")
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// DeleteExpired removes expired entries in the store's namespace and
+// returns how many were removed.
+func (s *SQLStore) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.expireSQL, s.opts.Namespace, s.now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// StartJanitor starts a goroutine that calls DeleteExpired every interval,
+// or every DefaultJanitorInterval if interval is not positive, replacing
+// any janitor already running. Stop it with StopJanitor or Close.
+func (s *SQLStore) StartJanitor(interval time.Duration) {
+	interval = janitorInterval(interval)
+	s.janitor.start(interval, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+		s.DeleteExpired(ctx)
+	})
+}
+
+// StopJanitor stops the janitor goroutine, if running, and waits for it to
+// exit.
+func (s *SQLStore) StopJanitor() {
+	s.janitor.halt()
+}
+
+// Close stops the janitor. It does not close the database, which the
+// caller owns.
+func (s *SQLStore) Close() error {
+	s.StopJanitor()
+	return nil
+}
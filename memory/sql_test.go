@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/supadev-ai/go-dspy/memory/internal/sqltest"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqltest.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLStore_Dialects(t *testing.T) {
+	for _, dialect := range []SQLDialect{SQLite, Postgres, MySQL} {
+		t.Run(dialect.String(), func(t *testing.T) {
+			ctx := context.Background()
+			opts := DefaultSQLOptions()
+			opts.Dialect = dialect
+
+			store, err := OpenSQLStore(ctx, openTestDB(t), opts)
+			if err != nil {
+				t.Fatalf("OpenSQLStore failed: %v", err)
+			}
+
+			if err := store.Put(ctx, "turn", map[string]string{"role": "user"}); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			if err := store.Put(ctx, "turn", map[string]string{"role": "assistant"}); err != nil {
+				t.Fatalf("Upsert failed: %v", err)
+			}
+
+			v, err := store.Get(ctx, "turn")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if m, ok := v.(map[string]interface{}); !ok || m["role"] != "assistant" {
+				t.Errorf("Expected upserted value, got %#v", v)
+			}
+
+			var typed struct{ Role string }
+			if err := store.GetInto(ctx, "turn", &typed); err != nil || typed.Role != "assistant" {
+				t.Errorf("Expected typed value, got %+v (%v)", typed, err)
+			}
+
+			store.Delete(ctx, "turn")
+			if _, err := store.Get(ctx, "turn"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLStore_MigratesOnce(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	first, err := OpenSQLStore(ctx, db, DefaultSQLOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Put(ctx, "k", "v")
+
+	// Reopening must not re-run migrations or lose data.
+	second, err := OpenSQLStore(ctx, db, DefaultSQLOptions())
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if v, err := second.Get(ctx, "k"); err != nil || v != "v" {
+		t.Errorf("Expected data to survive reopen, got %v (%v)", v, err)
+	}
+
+	var version int
+	db.QueryRow("SELECT MAX(version) FROM dspy_memory_schema").Scan(&version)
+	if version != 2 {
+		t.Errorf("Expected schema version 2, got %d", version)
+	}
+}
+
+func TestSQLStore_ConcurrentMigrations(t *testing.T) {
+	for _, dialect := range []SQLDialect{SQLite, Postgres, MySQL} {
+		t.Run(dialect.String(), func(t *testing.T) {
+			ctx := context.Background()
+			db := openTestDB(t)
+			opts := DefaultSQLOptions()
+			opts.Dialect = dialect
+
+			start := make(chan struct{})
+			errs := make(chan error, 8)
+			for i := 0; i < cap(errs); i++ {
+				go func() {
+					<-start
+					_, err := OpenSQLStore(ctx, db, opts)
+					errs <- err
+				}()
+			}
+			close(start)
+			for i := 0; i < cap(errs); i++ {
+				if err := <-errs; err != nil {
+					t.Errorf("Expected concurrent opens to succeed, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLStore_MigrationAppliedByAnotherOpener(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	opts := DefaultSQLOptions()
+	opts.Dialect = MySQL
+	store, err := OpenSQLStore(ctx, db, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An opener that read version 1 before this one finished re-checks in
+	// its transaction rather than creating the index again.
+	migrations := store.migrations()
+	if err := store.applyMigration(ctx, opts.Table+"_schema", 2, migrations[1]); err != nil {
+		t.Errorf("Expected an applied migration to be skipped, got %v", err)
+	}
+	if _, err := db.Exec(migrations[1]); err == nil {
+		t.Fatal("Expected re-running the MySQL index migration to fail")
+	}
+}
+
+func TestSQLStore_Namespaces(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	optsA := DefaultSQLOptions()
+	optsA.Namespace = "tenant-a"
+	optsB := DefaultSQLOptions()
+	optsB.Namespace = "tenant-b"
+	a, _ := OpenSQLStore(ctx, db, optsA)
+	b, _ := OpenSQLStore(ctx, db, optsB)
+
+	a.Put(ctx, "k", "a")
+	b.Put(ctx, "k", "b")
+	if err := a.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected tenant-a to be cleared, got %v", err)
+	}
+	if v, err := b.Get(ctx, "k"); err != nil || v != "b" {
+		t.Errorf("Expected tenant-b untouched, got %v (%v)", v, err)
+	}
+}
+
+func TestSQLStore_NamespaceClear(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenSQLStore(ctx, openTestDB(t), DefaultSQLOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Put(ctx, "a:1", 1)
+	store.Put(ctx, "a:2", 2)
+	store.Put(ctx, "a_b", 3)
+	store.Put(ctx, "b:1", 4)
+
+	if err := NewNamespace(store, "a").Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a:1", "a:2"} {
+		if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected %s cleared, got %v", key, err)
+		}
+	}
+	for _, key := range []string{"a_b", "b:1"} {
+		if _, err := store.Get(ctx, key); err != nil {
+			t.Errorf("Expected %s untouched, got %v", key, err)
+		}
+	}
+}
+
+func TestSQLStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	store, err := OpenSQLStore(ctx, openTestDB(t), DefaultSQLOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.now = clock.Now
+
+	store.PutWithTTL(ctx, "session", "x", time.Minute)
+	store.Put(ctx, "profile", "y")
+	clock.Advance(time.Minute)
+
+	if _, err := store.Get(ctx, "session"); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+	n, err := store.DeleteExpired(ctx)
+	if err != nil || n != 1 {
+		t.Errorf("Expected 1 expired row deleted, got %d (%v)", n, err)
+	}
+	if _, err := store.Get(ctx, "session"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after cleanup, got %v", err)
+	}
+	if v, err := store.Get(ctx, "profile"); err != nil || v != "y" {
+		t.Errorf("Expected entry without TTL to remain, got %v (%v)", v, err)
+	}
+}
+
+func TestSQLStore_Janitor(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenSQLStore(ctx, openTestDB(t), DefaultSQLOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	store.PutWithTTL(ctx, "k", "v", time.Millisecond)
+	store.StartJanitor(5 * time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := store.Get(ctx, "k"); errors.Is(err, ErrNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected janitor to delete expired row")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSQLStore_JanitorNonPositiveInterval(t *testing.T) {
+	store, err := OpenSQLStore(context.Background(), openTestDB(t), DefaultSQLOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// Both fall back to DefaultJanitorInterval instead of panicking.
+	store.StartJanitor(0)
+	store.StartJanitor(-time.Second)
+	store.StopJanitor()
+}
+
+func TestOpenSQLStore_InvalidTable(t *testing.T) {
+	opts := DefaultSQLOptions()
+	opts.Table = "memory; DROP TABLE users"
+	if _, err := OpenSQLStore(context.Background(), openTestDB(t), opts); err == nil {
+		t.Error("Expected error for unsafe table name")
+	}
+}
@@ -1,19 +1,40 @@
 package memory
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Errors returned by Store implementations. Use errors.Is to test for them.
+var (
+	// ErrNotFound is returned when a key does not exist.
+	ErrNotFound = errors.New("key not found")
+	// ErrExpired is returned when a key existed but its TTL has elapsed.
+	ErrExpired = errors.New("key expired")
+)
 
 // Store is an interface for storing and retrieving conversation history
 // and intermediate results in DSPy pipelines.
 type Store interface {
 	// Put stores a value with the given key.
 	Put(ctx context.Context, key string, value interface{}) error
-	
+
 	// Get retrieves a value by key.
 	Get(ctx context.Context, key string) (interface{}, error)
-	
+
 	// Delete removes a value by key.
 	Delete(ctx context.Context, key string) error
-	
+
 	// Clear removes all stored values.
 	Clear(ctx context.Context) error
 }
+
+// TTLStore is a Store whose entries can expire.
+type TTLStore interface {
+	Store
+
+	// PutWithTTL stores a value that expires after ttl.
+	// A ttl of zero or less means the value never expires.
+	PutWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+}
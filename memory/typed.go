@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrTypeMismatch is returned when a stored value cannot be decoded into or
+// converted to the requested type.
+var ErrTypeMismatch = errors.New("stored value has a different type")
+
+// DecodingStore is a Store that persists encoded values and can decode
+// them directly into a variable of the caller's type. FileStore,
+// RedisStore and SQLStore implement it.
+type DecodingStore interface {
+	Store
+
+	// GetInto decodes the value of key into the variable pointed to by
+	// target. Decoding failures wrap ErrTypeMismatch.
+	GetInto(ctx context.Context, key string, target interface{}) error
+}
+
+// Typed is a view of a Store holding values of type T, sparing callers
+// type assertions:
+//
+//	turns := memory.NewTyped[[]Turn](store)
+//	history, err := turns.Get(ctx, "session:42")
+//
+// Stores implementing DecodingStore decode values straight into T, unless
+// GetInto returns ErrNotSupported, as wrappers such as NamespacedStore do
+// over stores that cannot decode. Other stores must return values of type T, or values that a codec set with
+// WithCodec can convert, such as the generic maps produced by decoding
+// JSON. Anything else yields an error wrapping ErrTypeMismatch.
+type Typed[T any] struct {
+	store Store
+	codec Codec
+}
+
+// NewTyped returns a typed view of store.
+func NewTyped[T any](store Store) *Typed[T] {
+	return &Typed[T]{store: store}
+}
+
+// WithCodec sets a codec used to convert values that are not already of
+// type T, by encoding them and decoding the result into T.
+func (t *Typed[T]) WithCodec(codec Codec) *Typed[T] {
+	t.codec = codec
+	return t
+}
+
+// Store returns the underlying store.
+func (t *Typed[T]) Store() Store {
+	return t.store
+}
+
+// Put stores value at key.
+func (t *Typed[T]) Put(ctx context.Context, key string, value T) error {
+	return t.store.Put(ctx, key, value)
+}
+
+// PutWithTTL stores value at key, expiring after ttl. It returns
+// ErrNotSupported if the store has no TTL support.
+func (t *Typed[T]) PutWithTTL(ctx context.Context, key string, value T, ttl time.Duration) error {
+	ts, ok := t.store.(TTLStore)
+	if !ok {
+		return fmt.Errorf("PutWithTTL: %w", ErrNotSupported)
+	}
+	return ts.PutWithTTL(ctx, key, value, ttl)
+}
+
+// Get returns the value at key as a T.
+func (t *Typed[T]) Get(ctx context.Context, key string) (T, error) {
+	var zero T
+	if ds, ok := t.store.(DecodingStore); ok {
+		var value T
+		err := ds.GetInto(ctx, key, &value)
+		switch {
+		case err == nil:
+			return value, nil
+		case !errors.Is(err, ErrNotSupported):
+			return zero, err
+		}
+		// A wrapper over a store that does not decode values itself.
+	}
+
+	raw, err := t.store.Get(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+	return t.convert(key, raw)
+}
+
+// Delete removes key.
+func (t *Typed[T]) Delete(ctx context.Context, key string) error {
+	return t.store.Delete(ctx, key)
+}
+
+// Update atomically replaces the value at key with fn's result, as the
+// package-level Update does. It returns ErrNotSupported if the store is not
+// a VersionedStore.
+func (t *Typed[T]) Update(ctx context.Context, key string, fn func(old T, found bool) (T, error)) (T, error) {
+	var zero T
+	vs, ok := t.store.(VersionedStore)
+	if !ok {
+		return zero, fmt.Errorf("update: %w", ErrNotSupported)
+	}
+
+	value, err := Update(ctx, vs, key, func(old interface{}, found bool) (interface{}, error) {
+		var typed T
+		if found {
+			var err error
+			if typed, err = t.convert(key, old); err != nil {
+				return nil, err
+			}
+		}
+		return fn(typed, found)
+	})
+	if err != nil {
+		return zero, err
+	}
+	typed, _ := value.(T)
+	return typed, nil
+}
+
+// convert returns raw as a T, using the codec if it is not one already.
+func (t *Typed[T]) convert(key string, raw interface{}) (T, error) {
+	if value, ok := raw.(T); ok {
+		return value, nil
+	}
+
+	var value T
+	if t.codec == nil {
+		return value, fmt.Errorf("%w: %s holds %T, not %s", ErrTypeMismatch, key, raw, typeName[T]())
+	}
+	data, err := t.codec.Marshal(raw)
+	if err != nil {
+		return value, decodeError(key, err)
+	}
+	if err := t.codec.Unmarshal(data, &value); err != nil {
+		var zero T
+		return zero, decodeError(key, err)
+	}
+	return value, nil
+}
+
+// typeName returns the name of T, including interface types.
+func typeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type typedTurn struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func TestTyped_InMemory(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	turns := NewTyped[typedTurn](store)
+
+	if err := turns.Put(ctx, "t1", typedTurn{Role: "user", Content: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	turn, err := turns.Get(ctx, "t1")
+	if err != nil || turn.Content != "hi" {
+		t.Errorf("Expected stored turn, got %+v (%v)", turn, err)
+	}
+
+	if _, err := turns.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	store.Put(ctx, "wrong", 42)
+	if _, err := turns.Get(ctx, "wrong"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestTyped_FileStoreDecodesIntoT(t *testing.T) {
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "mem.log"), JSONCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	turns := NewTyped[typedTurn](store)
+	turns.Put(ctx, "t1", typedTurn{Role: "assistant", Content: "hello"})
+	turn, err := turns.Get(ctx, "t1")
+	if err != nil || turn != (typedTurn{Role: "assistant", Content: "hello"}) {
+		t.Errorf("Expected decoded struct, got %+v (%v)", turn, err)
+	}
+
+	store.Put(ctx, "text", "not a turn")
+	if _, err := turns.Get(ctx, "text"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestTyped_CodecConversion(t *testing.T) {
+	store := plainStore{NewInMemoryStore()}
+	ctx := context.Background()
+
+	// Simulate a persistent store returning generic JSON values.
+	store.Put(ctx, "t1", map[string]interface{}{"role": "user", "content": "hi"})
+
+	if _, err := NewTyped[typedTurn](store).Get(ctx, "t1"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch without a codec, got %v", err)
+	}
+	turn, err := NewTyped[typedTurn](store).WithCodec(JSONCodec{}).Get(ctx, "t1")
+	if err != nil || turn.Role != "user" || turn.Content != "hi" {
+		t.Errorf("Expected codec conversion, got %+v (%v)", turn, err)
+	}
+}
+
+func TestTyped_TTLAndUpdate(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	counts := NewTyped[int](store)
+
+	for i := 0; i < 3; i++ {
+		_, err := counts.Update(ctx, "n", func(old int, found bool) (int, error) {
+			return old + 1, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := counts.Get(ctx, "n"); n != 3 {
+		t.Errorf("Expected 3 after updates, got %d", n)
+	}
+
+	if err := counts.PutWithTTL(ctx, "ttl", 1, time.Minute); err != nil {
+		t.Error(err)
+	}
+	if err := NewTyped[int](plainStore{store}).PutWithTTL(ctx, "ttl", 1, time.Minute); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+	if _, err := NewTyped[int](plainStore{store}).Update(ctx, "n", nil); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+// undecodingStore is a DecodingStore whose GetInto is not supported.
+type undecodingStore struct{ Store }
+
+func (undecodingStore) GetInto(ctx context.Context, key string, target interface{}) error {
+	return fmt.Errorf("GetInto: %w", ErrNotSupported)
+}
+
+func TestTyped_GetIntoNotSupported(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	store.Put(ctx, "n", 7)
+
+	if n, err := NewTyped[int](undecodingStore{store}).Get(ctx, "n"); err != nil || n != 7 {
+		t.Errorf("Expected a fallback to Get, got %v (%v)", n, err)
+	}
+}
+
+func TestTyped_NamespaceWithCodec(t *testing.T) {
+	type profile struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	profiles := NewTyped[profile](NewNamespace(store, "users")).WithCodec(JSONCodec{})
+
+	// A generic map, as left by decoding JSON elsewhere, is converted.
+	store.Put(ctx, "users:ada", map[string]interface{}{"name": "Ada", "age": 36})
+	if p, err := profiles.Get(ctx, "ada"); err != nil || p != (profile{Name: "Ada", Age: 36}) {
+		t.Errorf("Expected the codec to convert the map, got %+v (%v)", p, err)
+	}
+}
+
+func TestTyped_Namespace(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	names := NewTyped[string](store.Namespace("users"))
+
+	names.Put(ctx, "u1", "Ada")
+	if name, err := names.Get(ctx, "u1"); err != nil || name != "Ada" {
+		t.Errorf("Expected value through namespace, got %q (%v)", name, err)
+	}
+	store.Put(ctx, "users:u2", 7)
+	if _, err := names.Get(ctx, "u2"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch through namespace, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// EventType identifies the kind of change reported by Watch.
+type EventType int
+
+const (
+	// EventPut means a value was stored.
+	EventPut EventType = iota
+	// EventDelete means a value was deleted explicitly.
+	EventDelete
+	// EventExpire means a value was removed because its TTL elapsed.
+	EventExpire
+	// EventEvict means a value was removed to respect capacity limits.
+	EventEvict
+	// EventOverflow is the last event sent to a watcher that fell too far
+	// behind. Its channel is closed after it and later changes are lost.
+	EventOverflow
+)
+
+// String returns the lowercase name of t.
+func (t EventType) String() string {
+	switch t {
+	case EventPut:
+		return "put"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	case EventEvict:
+		return "evict"
+	case EventOverflow:
+		return "overflow"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change to a key, delivered by Watch.
+type Event struct {
+	Type EventType
+	Key  string
+	// Value is the stored value for puts and the removed value otherwise.
+	Value interface{}
+	// Version is the version of the entry that was stored or removed, for
+	// stores that implement VersionedStore.
+	Version uint64
+}
+
+// Watcher is a Store that publishes changes to its keys.
+type Watcher interface {
+	Store
+
+	// Watch returns a channel of changes to keys starting with prefix.
+	// The channel is closed when ctx is done. Watchers that do not keep up
+	// receive a final EventOverflow and are then closed; they should re-read
+	// the state they need and watch again.
+	Watch(ctx context.Context, prefix string) (<-chan Event, error)
+}
+
+// defaultWatchBuffer is the channel capacity of each watcher.
+const defaultWatchBuffer = 256
+
+// subscriber is a single Watch call.
+type subscriber struct {
+	prefix string
+	ch     chan Event
+	stop   func() bool
+}
+
+// broadcaster fans events out to subscribers without blocking. Publishers
+// serialize calls to publish so that every subscriber sees events in the
+// order they happened.
+type broadcaster struct {
+	mu   sync.Mutex
+	subs map[*subscriber]struct{}
+}
+
+// subscribe registers a subscriber for keys starting with prefix until ctx
+// is done. buffer is the channel capacity, one slot of which is reserved
+// for the overflow event.
+func (b *broadcaster) subscribe(ctx context.Context, prefix string, buffer int) <-chan Event {
+	if buffer < 2 {
+		buffer = 2
+	}
+	sub := &subscriber{prefix: prefix, ch: make(chan Event, buffer)}
+
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[*subscriber]struct{})
+	}
+	b.subs[sub] = struct{}{}
+	sub.stop = context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.removeLocked(sub)
+	})
+	b.mu.Unlock()
+	return sub.ch
+}
+
+// active reports whether anyone is subscribed.
+func (b *broadcaster) active() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs) > 0
+}
+
+// publish delivers events to matching subscribers. A subscriber whose
+// buffer is full receives EventOverflow in its reserved slot and is
+// removed.
+func (b *broadcaster) publish(events ...Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ev := range events {
+		for sub := range b.subs {
+			if !strings.HasPrefix(ev.Key, sub.prefix) {
+				continue
+			}
+			if len(sub.ch) < cap(sub.ch)-1 {
+				sub.ch <- ev
+				continue
+			}
+			sub.ch <- Event{Type: EventOverflow, Key: ev.Key}
+			sub.stop()
+			b.removeLocked(sub)
+		}
+	}
+}
+
+// removeLocked unregisters sub and closes its channel. The caller must hold
+// b.mu.
+func (b *broadcaster) removeLocked(sub *subscriber) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// nextEvent receives one event from ch or fails the test after a timeout.
+func nextEvent(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatal("Expected an event, channel closed")
+		}
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+		return Event{}
+	}
+}
+
+func TestInMemoryStore_Watch(t *testing.T) {
+	clock := newFakeClock()
+	store := NewInMemoryStore()
+	store.now = clock.Now
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := store.Watch(ctx, "chat:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Put(ctx, "other", 0)
+	store.Put(ctx, "chat:1", "hello")
+	store.Delete(ctx, "chat:1")
+	store.PutWithTTL(ctx, "chat:2", "brief", time.Minute)
+	clock.Advance(2 * time.Minute)
+	store.DeleteExpired()
+
+	want := []struct {
+		typ EventType
+		key string
+	}{
+		{EventPut, "chat:1"},
+		{EventDelete, "chat:1"},
+		{EventPut, "chat:2"},
+		{EventExpire, "chat:2"},
+	}
+	for _, w := range want {
+		ev := nextEvent(t, events)
+		if ev.Type != w.typ || ev.Key != w.key {
+			t.Errorf("Expected %v %s, got %v %s", w.typ, w.key, ev.Type, ev.Key)
+		}
+	}
+
+	cancel()
+	select {
+	case ev, ok := <-events:
+		if ok {
+			t.Errorf("Expected channel closed after cancel, got %v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected channel to close after cancel")
+	}
+}
+
+func TestInMemoryStore_WatchValuesAndVersions(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _ := store.Watch(ctx, "")
+	store.Put(ctx, "k", "v")
+	ev := nextEvent(t, events)
+	_, version, _ := store.GetVersion(ctx, "k")
+	if ev.Value != "v" || ev.Version != version {
+		t.Errorf("Expected value and version in event, got %+v", ev)
+	}
+}
+
+func TestInMemoryStore_WatchEviction(t *testing.T) {
+	store := NewBoundedStore(1, LRU)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _ := store.Watch(ctx, "")
+	store.Put(ctx, "a", 1)
+	store.Put(ctx, "b", 2)
+
+	types := []EventType{nextEvent(t, events).Type, nextEvent(t, events).Type, nextEvent(t, events).Type}
+	if types[0] != EventPut || types[1] != EventEvict || types[2] != EventPut {
+		t.Errorf("Expected put, evict, put; got %v", types)
+	}
+}
+
+func TestInMemoryStore_WatchSlowConsumer(t *testing.T) {
+	store := NewInMemoryStore().WithWatchBuffer(4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slow, _ := store.Watch(ctx, "")
+
+	// Writers must never block on a watcher that is not reading.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			store.Put(ctx, "k", i)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected writes not to block on slow watchers")
+	}
+
+	var got []Event
+	for ev := range slow {
+		got = append(got, ev)
+	}
+	if len(got) != 4 || got[3].Type != EventOverflow {
+		t.Errorf("Expected 3 events then overflow, got %v", got)
+	}
+}
+
+func TestNamespace_Watch(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := store.Namespace("session").Watch(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Put(ctx, "other:turn", 0)
+	store.Namespace("session").Put(ctx, "turn", 1)
+
+	if ev := nextEvent(t, events); ev.Key != "turn" {
+		t.Errorf("Expected unprefixed key, got %q", ev.Key)
+	}
+
+	if _, err := NewNamespace(plainStore{NewInMemoryStore()}, "n").Watch(ctx, ""); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for an unwatchable base, got %v", err)
+	}
+}
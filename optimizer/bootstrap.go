@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/tracing"
 )
 
 // BootstrapOptimizer is a simple optimizer that runs the module on examples
@@ -49,19 +50,25 @@ func (b *BootstrapOptimizer[I, O]) Optimize(
 	module dspy.Module[I, O],
 	examples []dspy.Example[I, O],
 	metric Metric[I, O],
-) (dspy.Module[I, O], error) {
+) (_ dspy.Module[I, O], err error) {
 	if len(examples) == 0 {
 		return module, fmt.Errorf("no examples provided")
 	}
 
+	ctx = tracing.WithLabels(ctx, tracing.AttrOptimizer.String("bootstrap"))
+	ctx, span := tracing.Start(ctx, "optimize bootstrap",
+		tracing.AttrOptimizer.String("bootstrap"), tracing.AttrExamples.Int(len(examples)))
+	defer func() { tracing.End(span, err) }()
+
 	// Create a context with timeout
 	optCtx, cancel := context.WithTimeout(ctx, b.Timeout)
 	defer cancel()
 
 	// Evaluate initial performance
-	initialScore, err := Evaluate(module, examples, metric)
+	initialScore, err := evaluateScore(optCtx, module, examples, metric)
 	if err != nil {
-		return module, fmt.Errorf("initial evaluation failed: %w", err)
+		err = fmt.Errorf("initial evaluation failed: %w", err)
+		return module, err
 	}
 
 	bestModule := module
@@ -72,13 +79,14 @@ func (b *BootstrapOptimizer[I, O]) Optimize(
 	for i := 0; i < b.MaxIterations; i++ {
 		select {
 		case <-optCtx.Done():
-			return bestModule, optCtx.Err()
+			err = optCtx.Err()
+			return bestModule, err
 		default:
 		}
 
 		// Run module on all examples
-		currentScore, err := Evaluate(bestModule, examples, metric)
-		if err != nil {
+		currentScore, iterErr := b.iterate(optCtx, i, bestModule, examples, metric)
+		if iterErr != nil {
 			continue // Skip this iteration on error
 		}
 
@@ -98,5 +106,25 @@ func (b *BootstrapOptimizer[I, O]) Optimize(
 		// For now, we just return the module as-is
 	}
 
+	span.SetAttributes(tracing.AttrScore.Float64(bestScore))
 	return bestModule, nil
 }
+
+// iterate runs one optimization iteration, evaluating module in its own
+// span.
+func (b *BootstrapOptimizer[I, O]) iterate(
+	ctx context.Context,
+	i int,
+	module dspy.Module[I, O],
+	examples []dspy.Example[I, O],
+	metric Metric[I, O],
+) (score float64, err error) {
+	ctx, span := tracing.Start(ctx, "optimize iteration", tracing.AttrIteration.Int(i))
+	defer func() { tracing.End(span, err) }()
+
+	score, err = evaluateScore(ctx, module, examples, metric)
+	if err == nil {
+		span.SetAttributes(tracing.AttrScore.Float64(score))
+	}
+	return score, err
+}
@@ -0,0 +1,51 @@
+package optimizer
+
+import (
+	"context"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+// HarvestDemos runs module on each example under a fresh trace and collects
+// the predictor calls from runs that score at least threshold.
+// The result is keyed by predictor name so that demos from multi-stage
+// programs can be attributed to the stage that produced them.
+// The metric receives the run's trace, so it can also validate intermediate
+// steps. Examples whose run fails are skipped, as are failed calls and
+// retrievals within a run.
+func HarvestDemos[I any, O any](
+	ctx context.Context,
+	module dspy.Module[I, O],
+	examples []dspy.Example[I, O],
+	metric ExampleMetric[I, O],
+	threshold float64,
+) (map[string][]dspy.TraceEntry, error) {
+	demos := make(map[string][]dspy.TraceEntry)
+
+	for _, ex := range examples {
+		select {
+		case <-ctx.Done():
+			return demos, ctx.Err()
+		default:
+		}
+
+		trace := dspy.NewTrace()
+		predicted, err := module.Forward(dspy.WithTrace(ctx, trace), ex.Input)
+		if err != nil {
+			continue
+		}
+
+		if metric(ctx, ex.Input, ex.Output, predicted, trace).Value < threshold {
+			continue
+		}
+
+		for _, entry := range trace.Entries() {
+			if entry.Kind != dspy.TraceKindPredict || entry.Err != nil {
+				continue
+			}
+			demos[entry.Predictor] = append(demos[entry.Predictor], entry)
+		}
+	}
+
+	return demos, nil
+}
@@ -0,0 +1,64 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+type questionInput struct {
+	Question string
+}
+
+type queryOutput struct {
+	Query string
+}
+
+type answerOutput struct {
+	Answer string
+}
+
+// twoStage is a multi-stage program used to exercise trace harvesting.
+type twoStage struct {
+	rewrite *dspy.Predictor[questionInput, queryOutput]
+	answer  *dspy.Predictor[questionInput, answerOutput]
+}
+
+func (m *twoStage) Forward(ctx context.Context, input questionInput) (answerOutput, error) {
+	q, err := m.rewrite.Forward(ctx, input)
+	if err != nil {
+		return answerOutput{}, err
+	}
+	return m.answer.Forward(ctx, questionInput{Question: q.Query})
+}
+
+func TestHarvestDemos(t *testing.T) {
+	client := llm.NewMockClient().
+		WithResponse("Rewrite", "Query: go language").
+		WithResponse("Question: go language", "Answer: Go")
+
+	module := &twoStage{
+		rewrite: dspy.NewPredictor(dspy.NewSignature[questionInput, queryOutput]("Rewrite", "Rewrite the question"), client),
+		answer:  dspy.NewPredictor(dspy.NewSignature[questionInput, answerOutput]("Answer", "Answer the question"), client),
+	}
+
+	examples := []dspy.Example[questionInput, answerOutput]{
+		dspy.NewExample(questionInput{Question: "What is Go?"}, answerOutput{Answer: "Go"}),
+		dspy.NewExample(questionInput{Question: "What is Rust?"}, answerOutput{Answer: "Rust"}),
+	}
+
+	demos, err := HarvestDemos[questionInput, answerOutput](context.Background(), module, examples, ExactMatch[questionInput, answerOutput]().Extend(), 1.0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(demos["Rewrite"]) != 1 || len(demos["Answer"]) != 1 {
+		t.Fatalf("Expected one demo per stage, got %d rewrite and %d answer", len(demos["Rewrite"]), len(demos["Answer"]))
+	}
+
+	if out, ok := demos["Rewrite"][0].Output.(queryOutput); !ok || out.Query != "go language" {
+		t.Errorf("Expected rewrite demo output, got %#v", demos["Rewrite"][0].Output)
+	}
+}
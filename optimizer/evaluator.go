@@ -5,12 +5,39 @@ import (
 	"strings"
 
 	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/tracing"
 )
 
 // Metric is a function that evaluates the quality of a prediction.
 // It returns a score where higher is better.
 type Metric[I any, O any] func(predicted, expected O) float64
 
+// Score is the result of an ExampleMetric: a numeric score where higher is
+// better, plus optional feedback explaining it.
+type Score struct {
+	Value    float64
+	Feedback string
+}
+
+// ExampleMetric is a metric that sees the whole example rather than just
+// the outputs. It receives the evaluation's context, the example input, the
+// expected output, the prediction and the trace of predictor calls that
+// produced the prediction. The trace is nil when the caller did not record
+// one.
+type ExampleMetric[I any, O any] func(ctx context.Context, input I, expected, predicted O, trace *dspy.Trace) Score
+
+// FromMetric adapts a Metric to an ExampleMetric that ignores the input and trace.
+func FromMetric[I any, O any](metric Metric[I, O]) ExampleMetric[I, O] {
+	return func(_ context.Context, _ I, expected, predicted O, _ *dspy.Trace) Score {
+		return Score{Value: metric(predicted, expected)}
+	}
+}
+
+// Extend adapts m to an ExampleMetric. It is shorthand for FromMetric(m).
+func (m Metric[I, O]) Extend() ExampleMetric[I, O] {
+	return FromMetric(m)
+}
+
 // ExactMatch returns 1.0 if predicted exactly matches expected, 0.0 otherwise.
 func ExactMatch[I any, O comparable]() Metric[I, O] {
 	return func(predicted, expected O) float64 {
@@ -39,26 +66,100 @@ func StringContains[I any]() Metric[I, string] {
 	}
 }
 
+// Result is the outcome of running a module on a single example.
+type Result[I any, O any] struct {
+	Example   dspy.Example[I, O]
+	Predicted O
+	Score     Score
+	Trace     *dspy.Trace
+}
+
+// Evaluation holds per-example results and their average score.
+type Evaluation[I any, O any] struct {
+	Score   float64
+	Results []Result[I, O]
+}
+
 // Evaluate runs a module on examples and returns the average metric score.
 func Evaluate[I any, O any](
 	module dspy.Module[I, O],
 	examples []dspy.Example[I, O],
 	metric Metric[I, O],
 ) (float64, error) {
+	return evaluateScore(context.Background(), module, examples, metric)
+}
+
+// evaluateScore runs a module on examples under ctx and returns the average
+// metric score.
+func evaluateScore[I any, O any](
+	ctx context.Context,
+	module dspy.Module[I, O],
+	examples []dspy.Example[I, O],
+	metric Metric[I, O],
+) (float64, error) {
+	eval, err := EvaluateExamples(ctx, module, examples, FromMetric(metric))
+	if err != nil {
+		return 0.0, err
+	}
+	return eval.Score, nil
+}
+
+// EvaluateExamples runs a module on examples, recording a trace for each run,
+// and scores each prediction with metric. The evaluation and each example
+// run are wrapped in OpenTelemetry spans, and each score is recorded in the
+// evaluation score metric with the labels on ctx.
+func EvaluateExamples[I any, O any](
+	ctx context.Context,
+	module dspy.Module[I, O],
+	examples []dspy.Example[I, O],
+	metric ExampleMetric[I, O],
+) (_ *Evaluation[I, O], err error) {
+	ctx, span := tracing.Start(ctx, "evaluate", tracing.AttrExamples.Int(len(examples)))
+	defer func() { tracing.End(span, err) }()
+
+	eval := &Evaluation[I, O]{}
 	if len(examples) == 0 {
-		return 0.0, nil
+		return eval, nil
 	}
 
-	ctx := context.Background()
 	var totalScore float64
-	for _, ex := range examples {
-		predicted, err := module.Forward(ctx, ex.Input)
+	for i, ex := range examples {
+		result, err := evaluateExample(ctx, module, ex, i, metric)
 		if err != nil {
-			return 0.0, err
+			return nil, err
 		}
-		score := metric(predicted, ex.Output)
-		totalScore += score
+		totalScore += result.Score.Value
+		eval.Results = append(eval.Results, result)
 	}
 
-	return totalScore / float64(len(examples)), nil
+	eval.Score = totalScore / float64(len(examples))
+	span.SetAttributes(tracing.AttrScore.Float64(eval.Score))
+	return eval, nil
+}
+
+// evaluateExample runs module on the example at index i and scores it.
+func evaluateExample[I any, O any](
+	ctx context.Context,
+	module dspy.Module[I, O],
+	ex dspy.Example[I, O],
+	i int,
+	metric ExampleMetric[I, O],
+) (_ Result[I, O], err error) {
+	ctx, span := tracing.Start(ctx, "evaluate example", tracing.AttrExample.Int(i))
+	defer func() { tracing.End(span, err) }()
+
+	trace := dspy.NewTrace()
+	predicted, err := module.Forward(dspy.WithTrace(ctx, trace), ex.Input)
+	if err != nil {
+		return Result[I, O]{}, err
+	}
+	score := metric(ctx, ex.Input, ex.Output, predicted, trace)
+	span.SetAttributes(tracing.AttrScore.Float64(score.Value))
+	tracing.RecordScore(ctx, score.Value)
+	return Result[I, O]{
+		Example:   ex,
+		Predicted: predicted,
+		Score:     score,
+		Trace:     trace,
+	}, nil
 }
@@ -1,9 +1,14 @@
 package optimizer
 
 import (
+	"context"
 	"testing"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/tracing"
 )
 
 func TestExactMatch(t *testing.T) {
@@ -96,7 +101,7 @@ func TestEvaluate(t *testing.T) {
 	// Create a simple mock module
 	mockModule := &mockModule[Input, Output]{
 		responses: map[string]Output{
-			"What is Go?": {Answer: "Go is a programming language."},
+			"What is Go?":     {Answer: "Go is a programming language."},
 			"What is Python?": {Answer: "Python is a programming language."},
 		},
 	}
@@ -179,3 +184,107 @@ func TestEvaluate_EmptyExamples(t *testing.T) {
 	}
 }
 
+func TestFromMetric(t *testing.T) {
+	type Input struct {
+		Text string
+	}
+
+	metric := FromMetric(StringContains[Input]())
+
+	score := metric(context.Background(), Input{Text: "review"}, "positive", "a positive review", nil)
+	if score.Value != 1.0 {
+		t.Errorf("Expected score 1.0, got %f", score.Value)
+	}
+}
+
+func TestEvaluateExamples(t *testing.T) {
+	type Input struct {
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	mockModule := &mockModule[Input, Output]{
+		responses: map[string]Output{
+			"What is Go?": {Answer: "Go"},
+		},
+	}
+
+	examples := []dspy.Example[Input, Output]{
+		dspy.NewExample(Input{Question: "What is Go?"}, Output{Answer: "Go"}),
+		dspy.NewExample(Input{Question: "What is Rust?"}, Output{Answer: "Rust"}),
+	}
+
+	// The metric grades relative to the question and explains failures.
+	metric := func(ctx context.Context, input Input, expected, predicted Output, trace *dspy.Trace) Score {
+		if trace == nil {
+			t.Error("Expected trace to be passed to metric")
+		}
+		if predicted == expected {
+			return Score{Value: 1.0}
+		}
+		return Score{Feedback: "wrong answer to " + input.Question}
+	}
+
+	eval, err := EvaluateExamples(context.Background(), mockModule, examples, metric)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if eval.Score != 0.5 {
+		t.Errorf("Expected score 0.5, got %f", eval.Score)
+	}
+
+	if len(eval.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(eval.Results))
+	}
+
+	if eval.Results[1].Score.Feedback != "wrong answer to What is Rust?" {
+		t.Errorf("Expected feedback for failed example, got '%s'", eval.Results[1].Score.Feedback)
+	}
+}
+
+func TestEvaluateExamples_Spans(t *testing.T) {
+	type Input struct {
+		Question string
+	}
+	type Output struct {
+		Answer string
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer tracing.SetTracerProvider(nil)
+
+	module := &mockModule[Input, Output]{
+		responses: map[string]Output{"What is Go?": {Answer: "Go"}},
+	}
+	examples := []dspy.Example[Input, Output]{
+		dspy.NewExample(Input{Question: "What is Go?"}, Output{Answer: "Go"}),
+		dspy.NewExample(Input{Question: "What is Rust?"}, Output{Answer: "Rust"}),
+	}
+
+	if _, err := EvaluateExamples(context.Background(), module, examples, FromMetric(ExactMatch[Input, Output]())); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 2 example spans and 1 evaluation span, got %d", len(spans))
+	}
+	root := spans[2]
+	if root.Name() != "evaluate" {
+		t.Fatalf("Expected the evaluation span to end last, got %q", root.Name())
+	}
+	for i, span := range spans[:2] {
+		if span.Name() != "evaluate example" || span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("Expected example span %d under the evaluation, got %q", i, span.Name())
+		}
+	}
+	for _, kv := range root.Attributes() {
+		if kv.Key == tracing.AttrScore && kv.Value.AsFloat64() != 0.5 {
+			t.Errorf("Expected score 0.5 on the evaluation span, got %v", kv.Value.AsFloat64())
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+	"github.com/supadev-ai/go-dspy/tracing"
+)
+
+// DefaultRubric is the grading rubric used by a Judge when none is set.
+const DefaultRubric = "Grade how well the prediction answers the input, using the reference as the correct answer. " +
+	"Consider correctness first, then completeness and clarity."
+
+// DefaultJudgeScale is the maximum grade used by a Judge whose Scale is not
+// positive.
+const DefaultJudgeScale = 10
+
+// JudgeInput is the input to a judge predictor.
+type JudgeInput struct {
+	Rubric     string
+	Scale      string
+	Input      string
+	Reference  string
+	Prediction string
+}
+
+// JudgeOutput is the output of a judge predictor.
+// Score is kept as text so that free-form answers such as "8/10" parse.
+type JudgeOutput struct {
+	Score     string
+	Rationale string
+}
+
+// JudgeSignature returns the signature used by judge predictors.
+func JudgeSignature() dspy.Signature[JudgeInput, JudgeOutput] {
+	return dspy.NewSignature[JudgeInput, JudgeOutput](
+		"Judge",
+		"You are a strict grader. Follow the rubric to grade the prediction. "+
+			"Give a numeric Score between 0 and the scale, and a one-line Rationale.",
+	)
+}
+
+// Judge is an LLM-as-judge metric. It asks a judge predictor to grade each
+// prediction against the example input and reference output, and
+// normalizes the grade to [0, 1].
+type Judge[I any, O any] struct {
+	Predictor dspy.Module[JudgeInput, JudgeOutput]
+	Rubric    string
+	Scale     float64
+	Samples   int
+	Cache     bool
+
+	mu    sync.Mutex
+	cache map[JudgeInput]Score
+}
+
+// NewJudge creates a judge that grades with a Predictor using JudgeSignature
+// and the given client.
+func NewJudge[I any, O any](client llm.Client) *Judge[I, O] {
+	return NewJudgeWithPredictor[I, O](dspy.NewPredictor(JudgeSignature(), client))
+}
+
+// NewJudgeWithPredictor creates a judge that grades with the given module,
+// for example a predictor that an optimizer has already tuned.
+func NewJudgeWithPredictor[I any, O any](predictor dspy.Module[JudgeInput, JudgeOutput]) *Judge[I, O] {
+	return &Judge[I, O]{
+		Predictor: predictor,
+		Rubric:    DefaultRubric,
+		Scale:     DefaultJudgeScale,
+		Samples:   1,
+		Cache:     true,
+		cache:     make(map[JudgeInput]Score),
+	}
+}
+
+// WithRubric sets the grading rubric.
+func (j *Judge[I, O]) WithRubric(rubric string) *Judge[I, O] {
+	j.Rubric = rubric
+	return j
+}
+
+// WithScale sets the maximum grade the judge is asked to give. A
+// non-positive scale means DefaultJudgeScale.
+func (j *Judge[I, O]) WithScale(scale float64) *Judge[I, O] {
+	j.Scale = scale
+	return j
+}
+
+// WithSamples sets how many times each prediction is graded.
+// The grades are averaged to reduce judge variance.
+func (j *Judge[I, O]) WithSamples(n int) *Judge[I, O] {
+	j.Samples = n
+	return j
+}
+
+// WithCache enables or disables caching of grades for identical inputs.
+func (j *Judge[I, O]) WithCache(enabled bool) *Judge[I, O] {
+	j.Cache = enabled
+	return j
+}
+
+// Metric returns the judge as an ExampleMetric.
+// Grading errors produce a zero score with the error as feedback.
+func (j *Judge[I, O]) Metric() ExampleMetric[I, O] {
+	return func(ctx context.Context, input I, expected, predicted O, _ *dspy.Trace) Score {
+		score, err := j.Grade(ctx, input, expected, predicted)
+		if err != nil {
+			return Score{Feedback: err.Error()}
+		}
+		return score
+	}
+}
+
+// Grade asks the judge to grade predicted against expected for input.
+// The returned score is normalized to [0, 1] and its feedback is the
+// judge's rationale.
+func (j *Judge[I, O]) Grade(ctx context.Context, input I, expected, predicted O) (Score, error) {
+	scale := j.Scale
+	if scale <= 0 {
+		scale = DefaultJudgeScale
+	}
+	req := JudgeInput{
+		Rubric:     j.Rubric,
+		Scale:      fmt.Sprintf("0 to %g", scale),
+		Input:      renderValue(input),
+		Reference:  renderValue(expected),
+		Prediction: renderValue(predicted),
+	}
+
+	if j.Cache {
+		j.mu.Lock()
+		score, ok := j.cache[req]
+		j.mu.Unlock()
+		tracing.RecordCacheLookup(ctx, "judge", ok)
+		if ok {
+			return score, nil
+		}
+	}
+
+	samples := j.Samples
+	if samples < 1 {
+		samples = 1
+	}
+
+	var total float64
+	var rationales []string
+	var lastErr error
+	graded := 0
+	for i := 0; i < samples; i++ {
+		out, err := j.Predictor.Forward(ctx, req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		value, ok := ParseNumber(out.Score)
+		if !ok {
+			lastErr = fmt.Errorf("judge returned non-numeric score %q", out.Score)
+			continue
+		}
+		total += clamp(value/scale, 0, 1)
+		if out.Rationale != "" {
+			rationales = append(rationales, out.Rationale)
+		}
+		graded++
+	}
+
+	if graded == 0 {
+		return Score{}, dspy.ErrModuleExecution("judge.Grade", lastErr)
+	}
+
+	score := Score{
+		Value:    total / float64(graded),
+		Feedback: strings.Join(rationales, "\n"),
+	}
+
+	if j.Cache {
+		j.mu.Lock()
+		if j.cache == nil {
+			j.cache = make(map[JudgeInput]Score)
+		}
+		j.cache[req] = score
+		j.mu.Unlock()
+	}
+
+	return score, nil
+}
+
+// renderValue formats a value for inclusion in a judge prompt.
+func renderValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// clamp restricts v to [lo, hi].
+func clamp(v, lo, hi float64) float64 {
+	return max(lo, min(hi, v))
+}
@@ -0,0 +1,115 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// scriptedJudge is a judge module that returns scores in order.
+type scriptedJudge struct {
+	scores []string
+	calls  int
+}
+
+func (s *scriptedJudge) Forward(ctx context.Context, input JudgeInput) (JudgeOutput, error) {
+	if len(s.scores) == 0 {
+		return JudgeOutput{}, errors.New("no scores")
+	}
+	score := s.scores[s.calls%len(s.scores)]
+	s.calls++
+	return JudgeOutput{Score: score, Rationale: "graded " + input.Prediction}, nil
+}
+
+func TestJudge_WithClient(t *testing.T) {
+	client := llm.NewMockClient().
+		WithResponse("Prediction: Paris", "Score: 9\nRationale: Correct capital.").
+		WithResponse("Prediction: Lyon", "Score: 2\nRationale: Wrong city.")
+
+	metric := NewJudge[string, string](client).Metric()
+
+	good := metric(context.Background(), "Capital of France?", "Paris", "Paris", nil)
+	if !approxEqual(good.Value, 0.9) {
+		t.Errorf("Expected score 0.9, got %f", good.Value)
+	}
+	if good.Feedback != "Correct capital." {
+		t.Errorf("Expected rationale as feedback, got '%s'", good.Feedback)
+	}
+
+	bad := metric(context.Background(), "Capital of France?", "Paris", "Lyon", nil)
+	if !approxEqual(bad.Value, 0.2) {
+		t.Errorf("Expected score 0.2, got %f", bad.Value)
+	}
+}
+
+func TestJudge_SamplesAveraged(t *testing.T) {
+	module := &scriptedJudge{scores: []string{"4", "2"}}
+	judge := NewJudgeWithPredictor[string, string](module).
+		WithScale(4).
+		WithSamples(2).
+		WithCache(false)
+
+	score, err := judge.Grade(context.Background(), "q", "a", "b")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !approxEqual(score.Value, 0.75) {
+		t.Errorf("Expected averaged score 0.75, got %f", score.Value)
+	}
+
+	if module.calls != 2 {
+		t.Errorf("Expected 2 judge calls, got %d", module.calls)
+	}
+}
+
+func TestJudge_NonPositiveScale(t *testing.T) {
+	for _, scale := range []float64{0, -10} {
+		judge := NewJudgeWithPredictor[string, string](&scriptedJudge{scores: []string{"8"}}).WithScale(scale)
+		score, err := judge.Grade(context.Background(), "q", "a", "a")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !approxEqual(score.Value, 0.8) {
+			t.Errorf("Expected scale %g to fall back to %d, got %f", scale, DefaultJudgeScale, score.Value)
+		}
+	}
+}
+
+func TestJudge_Cache(t *testing.T) {
+	module := &scriptedJudge{scores: []string{"10"}}
+	judge := NewJudgeWithPredictor[string, string](module)
+
+	for i := 0; i < 3; i++ {
+		if _, err := judge.Grade(context.Background(), "q", "a", "a"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if module.calls != 1 {
+		t.Errorf("Expected cached grades to skip the judge, got %d calls", module.calls)
+	}
+}
+
+func TestJudge_ClampsAndRejectsNonNumeric(t *testing.T) {
+	judge := NewJudgeWithPredictor[string, string](&scriptedJudge{scores: []string{"15/10"}})
+	score, err := judge.Grade(context.Background(), "q", "a", "a")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if score.Value != 1.0 {
+		t.Errorf("Expected score clamped to 1.0, got %f", score.Value)
+	}
+
+	judge = NewJudgeWithPredictor[string, string](&scriptedJudge{scores: []string{"excellent"}})
+	if _, err := judge.Grade(context.Background(), "q", "a", "a"); err == nil {
+		t.Error("Expected error for non-numeric score, got nil")
+	}
+
+	result := judge.Metric()(context.Background(), "q", "a", "a", nil)
+	if result.Value != 0.0 || result.Feedback == "" {
+		t.Errorf("Expected zero score with error feedback, got %+v", result)
+	}
+}
@@ -0,0 +1,372 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NormalizedExactMatch returns 1.0 if predicted and expected are equal after
+// SQuAD-style normalization (lowercasing, removing punctuation and articles,
+// and collapsing whitespace), 0.0 otherwise.
+func NormalizedExactMatch[I any]() Metric[I, string] {
+	return func(predicted, expected string) float64 {
+		if NormalizeAnswer(predicted) == NormalizeAnswer(expected) {
+			return 1.0
+		}
+		return 0.0
+	}
+}
+
+// TokenF1 returns the SQuAD-style token-level F1 between the normalized
+// predicted and expected strings. Two empty strings score 1.0, and an
+// empty string against a non-empty one 0.0.
+func TokenF1[I any]() Metric[I, string] {
+	return func(predicted, expected string) float64 {
+		predTokens := strings.Fields(NormalizeAnswer(predicted))
+		expTokens := strings.Fields(NormalizeAnswer(expected))
+		if len(predTokens) == 0 || len(expTokens) == 0 {
+			return emptyScore(predTokens, expTokens)
+		}
+
+		counts := make(map[string]int)
+		for _, tok := range expTokens {
+			counts[tok]++
+		}
+		common := 0
+		for _, tok := range predTokens {
+			if counts[tok] > 0 {
+				counts[tok]--
+				common++
+			}
+		}
+		if common == 0 {
+			return 0.0
+		}
+
+		precision := float64(common) / float64(len(predTokens))
+		recall := float64(common) / float64(len(expTokens))
+		return 2 * precision * recall / (precision + recall)
+	}
+}
+
+// emptyScore scores token lists of which at least one is empty: 1.0 if
+// both are, 0.0 otherwise.
+func emptyScore(predicted, expected []string) float64 {
+	if len(predicted) == len(expected) {
+		return 1.0
+	}
+	return 0.0
+}
+
+// RougeL returns the ROUGE-L F-measure, based on the longest common
+// subsequence of lowercased word tokens. Like TokenF1, it scores two empty
+// strings 1.0.
+func RougeL[I any]() Metric[I, string] {
+	return func(predicted, expected string) float64 {
+		predTokens := tokenize(predicted)
+		expTokens := tokenize(expected)
+		if len(predTokens) == 0 || len(expTokens) == 0 {
+			return emptyScore(predTokens, expTokens)
+		}
+
+		lcs := lcsLength(predTokens, expTokens)
+		if lcs == 0 {
+			return 0.0
+		}
+
+		precision := float64(lcs) / float64(len(predTokens))
+		recall := float64(lcs) / float64(len(expTokens))
+		return 2 * precision * recall / (precision + recall)
+	}
+}
+
+// BLEU returns the sentence-level BLEU score of predicted against expected,
+// using uniformly weighted 1- to 4-gram precisions, add-one smoothing for
+// higher-order n-grams and the standard brevity penalty. Like TokenF1, it
+// scores two empty strings 1.0.
+func BLEU[I any]() Metric[I, string] {
+	const maxN = 4
+	return func(predicted, expected string) float64 {
+		predTokens := tokenize(predicted)
+		expTokens := tokenize(expected)
+		if len(predTokens) == 0 || len(expTokens) == 0 {
+			return emptyScore(predTokens, expTokens)
+		}
+
+		var logSum float64
+		for n := 1; n <= maxN; n++ {
+			predGrams := ngrams(predTokens, n)
+			expGrams := ngrams(expTokens, n)
+
+			total := 0
+			matched := 0
+			for gram, count := range predGrams {
+				total += count
+				if ref := expGrams[gram]; ref > 0 {
+					matched += min(count, ref)
+				}
+			}
+
+			if n == 1 {
+				if matched == 0 {
+					return 0.0
+				}
+				logSum += math.Log(float64(matched) / float64(total))
+				continue
+			}
+			logSum += math.Log(float64(matched+1) / float64(total+1))
+		}
+
+		brevity := 1.0
+		if len(predTokens) < len(expTokens) {
+			brevity = math.Exp(1 - float64(len(expTokens))/float64(len(predTokens)))
+		}
+		return brevity * math.Exp(logSum/maxN)
+	}
+}
+
+// NumericMatch returns 1.0 if the first number found in predicted is within
+// tolerance of the first number found in expected, 0.0 otherwise.
+// Thousands separators are ignored.
+func NumericMatch[I any](tolerance float64) Metric[I, string] {
+	return func(predicted, expected string) float64 {
+		p, ok := ParseNumber(predicted)
+		if !ok {
+			return 0.0
+		}
+		e, ok := ParseNumber(expected)
+		if !ok {
+			return 0.0
+		}
+		if math.Abs(p-e) <= tolerance {
+			return 1.0
+		}
+		return 0.0
+	}
+}
+
+// WithinTolerance returns 1.0 if predicted is within tolerance of expected,
+// 0.0 otherwise.
+func WithinTolerance[I any](tolerance float64) Metric[I, float64] {
+	return func(predicted, expected float64) float64 {
+		if math.Abs(predicted-expected) <= tolerance {
+			return 1.0
+		}
+		return 0.0
+	}
+}
+
+// Jaccard returns the Jaccard similarity between the sets of normalized
+// predicted and expected items.
+func Jaccard[I any]() Metric[I, []string] {
+	return func(predicted, expected []string) float64 {
+		predSet := normalizedSet(predicted)
+		expSet := normalizedSet(expected)
+		if len(predSet) == 0 && len(expSet) == 0 {
+			return 1.0
+		}
+
+		intersection := 0
+		for item := range predSet {
+			if expSet[item] {
+				intersection++
+			}
+		}
+		union := len(predSet) + len(expSet) - intersection
+		return float64(intersection) / float64(union)
+	}
+}
+
+// SetF1 returns the F1 between the sets of normalized predicted and
+// expected items, treating expected as the relevant set.
+func SetF1[I any]() Metric[I, []string] {
+	return func(predicted, expected []string) float64 {
+		predSet := normalizedSet(predicted)
+		expSet := normalizedSet(expected)
+		if len(predSet) == 0 || len(expSet) == 0 {
+			if len(predSet) == len(expSet) {
+				return 1.0
+			}
+			return 0.0
+		}
+
+		intersection := 0
+		for item := range predSet {
+			if expSet[item] {
+				intersection++
+			}
+		}
+		if intersection == 0 {
+			return 0.0
+		}
+
+		precision := float64(intersection) / float64(len(predSet))
+		recall := float64(intersection) / float64(len(expSet))
+		return 2 * precision * recall / (precision + recall)
+	}
+}
+
+// PositionalMatch returns the fraction of positions at which the normalized
+// predicted and expected lists agree, relative to the longer list.
+// Use it when order matters, such as for rankings.
+func PositionalMatch[I any]() Metric[I, []string] {
+	return func(predicted, expected []string) float64 {
+		longest := max(len(predicted), len(expected))
+		if longest == 0 {
+			return 1.0
+		}
+
+		matched := 0
+		for i := 0; i < min(len(predicted), len(expected)); i++ {
+			if NormalizeAnswer(predicted[i]) == NormalizeAnswer(expected[i]) {
+				matched++
+			}
+		}
+		return float64(matched) / float64(longest)
+	}
+}
+
+// Select applies metric to a value selected from each output.
+// It lets field-level metrics score structured outputs with compile-time
+// checked accessors:
+//
+//	optimizer.Select(func(o QAOutput) string { return o.Answer }, optimizer.TokenF1[QAInput]())
+func Select[I any, O any, F any](selector func(O) F, metric Metric[I, F]) Metric[I, O] {
+	return func(predicted, expected O) float64 {
+		return metric(selector(predicted), selector(expected))
+	}
+}
+
+// Field applies a string metric to the named exported field of a struct
+// output type. Non-string fields are formatted with fmt.Sprint.
+// If O is not a struct (or pointer to struct) with that exported field,
+// every prediction scores 0.
+func Field[I any, O any](name string, metric Metric[I, string]) Metric[I, O] {
+	return func(predicted, expected O) float64 {
+		p, ok := fieldString(predicted, name)
+		if !ok {
+			return 0.0
+		}
+		e, ok := fieldString(expected, name)
+		if !ok {
+			return 0.0
+		}
+		return metric(p, e)
+	}
+}
+
+// fieldString returns the named exported field of v as a string, and
+// whether v has such a field. A nil pointer, or a field promoted through a
+// nil embedded pointer, yields an empty string.
+func fieldString(v interface{}, name string) (string, bool) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "", false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "", false
+	}
+	sf, ok := t.FieldByName(name)
+	if !ok || !sf.IsExported() {
+		return "", false
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if !val.IsValid() {
+		return "", true
+	}
+	field, err := val.FieldByIndexErr(sf.Index)
+	if err != nil {
+		return "", true
+	}
+	if field.Kind() == reflect.String {
+		return field.String(), true
+	}
+	return fmt.Sprint(field.Interface()), true
+}
+
+var articlesPattern = regexp.MustCompile(`\b(a|an|the)\b`)
+
+// NormalizeAnswer lowercases s and removes punctuation, the articles
+// "a", "an" and "the", and extra whitespace, following the SQuAD
+// evaluation script.
+func NormalizeAnswer(s string) string {
+	s = strings.ToLower(s)
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = articlesPattern.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// numberPattern matches a decimal number. Commas are only accepted as
+// thousands separators, so that "1,2,3" reads as the list it is.
+var numberPattern = regexp.MustCompile(`[-+]?(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?(?:[eE][-+]?\d+)?|[-+]?\.\d+`)
+
+// ParseNumber returns the first number found in s.
+func ParseNumber(s string) (float64, bool) {
+	match := numberPattern.FindString(s)
+	if match == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(match, ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// tokenize splits s into lowercased runs of letters and digits.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ngrams counts the n-grams of tokens.
+func ngrams(tokens []string, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(tokens); i++ {
+		counts[strings.Join(tokens[i:i+n], " ")]++
+	}
+	return counts
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// normalizedSet returns the set of normalized, non-empty items.
+func normalizedSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if norm := NormalizeAnswer(item); norm != "" {
+			set[norm] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,228 @@
+package optimizer
+
+import (
+	"math"
+	"testing"
+)
+
+type metricInput struct {
+	Question string
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"The Eiffel Tower!", "eiffel tower"},
+		{"  an   apple, a pear ", "apple pear"},
+		{"Theory", "theory"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeAnswer(tt.in); got != tt.want {
+			t.Errorf("NormalizeAnswer(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizedExactMatch(t *testing.T) {
+	metric := NormalizedExactMatch[metricInput]()
+
+	if score := metric("The Eiffel Tower.", "eiffel tower"); score != 1.0 {
+		t.Errorf("Expected score 1.0, got %f", score)
+	}
+
+	if score := metric("Eiffel", "eiffel tower"); score != 0.0 {
+		t.Errorf("Expected score 0.0, got %f", score)
+	}
+}
+
+func TestTokenF1(t *testing.T) {
+	metric := TokenF1[metricInput]()
+
+	tests := []struct {
+		predicted string
+		expected  string
+		want      float64
+	}{
+		{"the cat sat", "cat sat", 1.0},
+		{"cat", "cat sat", 2.0 / 3.0},
+		{"dog", "cat", 0.0},
+		{"", "", 1.0},
+		{"cat", "", 0.0},
+	}
+
+	for _, tt := range tests {
+		if got := metric(tt.predicted, tt.expected); !approxEqual(got, tt.want) {
+			t.Errorf("TokenF1(%q, %q) = %f, want %f", tt.predicted, tt.expected, got, tt.want)
+		}
+	}
+}
+
+func TestRougeL(t *testing.T) {
+	metric := RougeL[metricInput]()
+
+	if score := metric("police killed the gunman", "police kill the gunman"); !approxEqual(score, 0.75) {
+		t.Errorf("Expected score 0.75, got %f", score)
+	}
+
+	if score := metric("a b c", "a b c"); !approxEqual(score, 1.0) {
+		t.Errorf("Expected score 1.0 for identical text, got %f", score)
+	}
+
+	if score := metric("x", "y"); score != 0.0 {
+		t.Errorf("Expected score 0.0, got %f", score)
+	}
+}
+
+func TestTextMetrics_EmptyInputs(t *testing.T) {
+	metrics := map[string]Metric[metricInput, string]{
+		"TokenF1": TokenF1[metricInput](),
+		"RougeL":  RougeL[metricInput](),
+		"BLEU":    BLEU[metricInput](),
+	}
+	for name, metric := range metrics {
+		if score := metric("", ""); score != 1.0 {
+			t.Errorf("Expected %s to score two empty strings 1.0, got %f", name, score)
+		}
+		if score := metric("cat", ""); score != 0.0 {
+			t.Errorf("Expected %s to score an empty expected string 0.0, got %f", name, score)
+		}
+		if score := metric("", "cat"); score != 0.0 {
+			t.Errorf("Expected %s to score an empty prediction 0.0, got %f", name, score)
+		}
+	}
+}
+
+func TestBLEU(t *testing.T) {
+	metric := BLEU[metricInput]()
+
+	if score := metric("the cat is on the mat", "the cat is on the mat"); !approxEqual(score, 1.0) {
+		t.Errorf("Expected score 1.0 for identical text, got %f", score)
+	}
+
+	partial := metric("the cat on the mat", "the cat is on the mat")
+	if partial <= 0.0 || partial >= 1.0 {
+		t.Errorf("Expected partial score in (0, 1), got %f", partial)
+	}
+
+	if score := metric("dog", "the cat is on the mat"); score != 0.0 {
+		t.Errorf("Expected score 0.0 with no unigram overlap, got %f", score)
+	}
+}
+
+func TestNumericMatch(t *testing.T) {
+	metric := NumericMatch[metricInput](0.01)
+
+	tests := []struct {
+		predicted string
+		expected  string
+		want      float64
+	}{
+		{"The answer is 1,234.5 dollars", "1234.5", 1.0},
+		{"about 3.14", "3.141", 1.0},
+		{"-2", "2", 0.0},
+		{"no number", "2", 0.0},
+		{"1,000,000 users", "1000000", 1.0},
+		{"1,2,3", "1", 1.0},
+		{"1,2,3", "123", 0.0},
+		{"pick 10, 20 or 30", "10", 1.0},
+		{"1234,567", "1234", 1.0},
+	}
+
+	for _, tt := range tests {
+		if got := metric(tt.predicted, tt.expected); got != tt.want {
+			t.Errorf("NumericMatch(%q, %q) = %f, want %f", tt.predicted, tt.expected, got, tt.want)
+		}
+	}
+}
+
+func TestWithinTolerance(t *testing.T) {
+	metric := WithinTolerance[metricInput](0.5)
+
+	if score := metric(1.4, 1.0); score != 1.0 {
+		t.Errorf("Expected score 1.0, got %f", score)
+	}
+
+	if score := metric(2.0, 1.0); score != 0.0 {
+		t.Errorf("Expected score 0.0, got %f", score)
+	}
+}
+
+func TestSetMetrics(t *testing.T) {
+	predicted := []string{"Paris", "london", "Rome"}
+	expected := []string{"paris", "London", "Berlin", "Madrid"}
+
+	if score := Jaccard[metricInput]()(predicted, expected); !approxEqual(score, 2.0/5.0) {
+		t.Errorf("Expected Jaccard 0.4, got %f", score)
+	}
+
+	if score := SetF1[metricInput]()(predicted, expected); !approxEqual(score, 4.0/7.0) {
+		t.Errorf("Expected SetF1 %f, got %f", 4.0/7.0, score)
+	}
+}
+
+func TestPositionalMatch(t *testing.T) {
+	metric := PositionalMatch[metricInput]()
+
+	if score := metric([]string{"a1", "B2", "c3"}, []string{"a1", "b2", "x"}); !approxEqual(score, 2.0/3.0) {
+		t.Errorf("Expected score 2/3, got %f", score)
+	}
+
+	if score := metric(nil, nil); score != 1.0 {
+		t.Errorf("Expected score 1.0 for empty lists, got %f", score)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	type Output struct {
+		Answer string
+		Score  float64
+	}
+
+	metric := Select(func(o Output) string { return o.Answer }, TokenF1[metricInput]())
+
+	score := metric(Output{Answer: "the cat", Score: 1}, Output{Answer: "cat", Score: 2})
+	if score != 1.0 {
+		t.Errorf("Expected score 1.0, got %f", score)
+	}
+}
+
+func TestField(t *testing.T) {
+	type Output struct {
+		Answer string
+		Count  int
+	}
+
+	answer := Field[metricInput, Output]("Answer", NormalizedExactMatch[metricInput]())
+	if score := answer(Output{Answer: "The answer"}, Output{Answer: "answer"}); score != 1.0 {
+		t.Errorf("Expected score 1.0, got %f", score)
+	}
+
+	count := Field[metricInput, *Output]("Count", NumericMatch[metricInput](0))
+	if score := count(&Output{Count: 3}, &Output{Count: 3}); score != 1.0 {
+		t.Errorf("Expected score 1.0 for non-string field, got %f", score)
+	}
+}
+
+func TestField_MissingFieldScoresZero(t *testing.T) {
+	type Output struct {
+		Answer string
+		secret string
+	}
+
+	for _, name := range []string{"Missing", "secret"} {
+		metric := Field[metricInput, Output](name, NormalizedExactMatch[metricInput]())
+		if score := metric(Output{}, Output{}); score != 0.0 {
+			t.Errorf("Expected score 0.0 for field %q, got %f", name, score)
+		}
+	}
+	if score := Field[metricInput, string]("Answer", NormalizedExactMatch[metricInput]())("a", "a"); score != 0.0 {
+		t.Errorf("Expected score 0.0 for a non-struct output, got %f", score)
+	}
+}
@@ -0,0 +1,52 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// SemanticSimilarity returns the cosine similarity between the embeddings of
+// predicted and expected, clamped to [0, 1]. Embeddings are requested with
+// the evaluation's context. Embedding errors score 0 with the error as
+// feedback.
+func SemanticSimilarity[I any](embedder llm.Embedder) ExampleMetric[I, string] {
+	return func(ctx context.Context, _ I, expected, predicted string, _ *dspy.Trace) Score {
+		similarity, err := embeddingSimilarity(ctx, embedder, predicted, expected)
+		if err != nil {
+			return Score{Feedback: err.Error()}
+		}
+		return Score{Value: similarity}
+	}
+}
+
+// SemanticMatch returns 1.0 if the semantic similarity of predicted and
+// expected is at least threshold, 0.0 otherwise. The pass/fail form is
+// suited to bootstrap thresholds.
+func SemanticMatch[I any](embedder llm.Embedder, threshold float64) ExampleMetric[I, string] {
+	similarity := SemanticSimilarity[I](embedder)
+	return func(ctx context.Context, input I, expected, predicted string, trace *dspy.Trace) Score {
+		score := similarity(ctx, input, expected, predicted, trace)
+		if score.Value >= threshold {
+			score.Value = 1.0
+		} else {
+			score.Value = 0.0
+		}
+		return score
+	}
+}
+
+// embeddingSimilarity embeds a and b in one call and returns their clamped
+// cosine similarity.
+func embeddingSimilarity(ctx context.Context, embedder llm.Embedder, a, b string) (float64, error) {
+	vectors, err := embedder.Embed(ctx, []string{a, b})
+	if err != nil {
+		return 0, err
+	}
+	if len(vectors) != 2 {
+		return 0, fmt.Errorf("expected 2 embeddings, got %d", len(vectors))
+	}
+	return clamp(llm.CosineSimilarity(vectors[0], vectors[1]), 0, 1), nil
+}
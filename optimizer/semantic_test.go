@@ -0,0 +1,89 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, errors.New("embedding failed")
+}
+
+// semanticScore runs an ExampleMetric on a pair of strings.
+func semanticScore(metric ExampleMetric[metricInput, string], predicted, expected string) float64 {
+	return metric(context.Background(), metricInput{}, expected, predicted, nil).Value
+}
+
+func TestSemanticSimilarity(t *testing.T) {
+	metric := SemanticSimilarity[metricInput](llm.NewHashEmbedder(128))
+
+	if score := semanticScore(metric, "The cat sat on the mat", "the cat sat on the mat"); !approxEqual(score, 1.0) {
+		t.Errorf("Expected score 1.0 for identical words, got %f", score)
+	}
+
+	related := semanticScore(metric, "the cat sat", "the cat slept")
+	unrelated := semanticScore(metric, "the cat sat", "quantum chromodynamics")
+	if related <= unrelated {
+		t.Errorf("Expected related text (%f) to score above unrelated text (%f)", related, unrelated)
+	}
+}
+
+func TestSemanticSimilarity_EmbedderError(t *testing.T) {
+	metric := SemanticSimilarity[metricInput](failingEmbedder{})
+
+	score := metric(context.Background(), metricInput{}, "a", "a", nil)
+	if score.Value != 0.0 || score.Feedback == "" {
+		t.Errorf("Expected score 0.0 with the error as feedback, got %+v", score)
+	}
+}
+
+// shortEmbedder returns fewer vectors than texts.
+type shortEmbedder struct{}
+
+func (shortEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return [][]float32{{1, 0}}, nil
+}
+
+func TestSemanticSimilarity_WrongVectorCount(t *testing.T) {
+	score := SemanticSimilarity[metricInput](shortEmbedder{})(context.Background(), metricInput{}, "a", "b", nil)
+	if score.Value != 0.0 || score.Feedback == "" {
+		t.Errorf("Expected score 0.0 with an error as feedback, got %+v", score)
+	}
+}
+
+func TestSemanticSimilarity_UsesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	metric := SemanticSimilarity[metricInput](contextEmbedder{})
+
+	if score := metric(ctx, metricInput{}, "a", "a", nil); score.Feedback != context.Canceled.Error() {
+		t.Errorf("Expected the metric's context passed to the embedder, got %+v", score)
+	}
+}
+
+// contextEmbedder fails once its context is done.
+type contextEmbedder struct{}
+
+func (contextEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return llm.NewHashEmbedder(8).Embed(ctx, texts)
+}
+
+func TestSemanticMatch(t *testing.T) {
+	metric := SemanticMatch[metricInput](llm.NewHashEmbedder(128), 0.9)
+
+	if score := semanticScore(metric, "Paris is the capital", "paris is the capital"); score != 1.0 {
+		t.Errorf("Expected score 1.0 above threshold, got %f", score)
+	}
+
+	if score := semanticScore(metric, "Paris is the capital", "Berlin has a zoo"); score != 0.0 {
+		t.Errorf("Expected score 0.0 below threshold, got %f", score)
+	}
+}
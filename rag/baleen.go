@@ -0,0 +1,135 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// Question is the input to a RAG program.
+type Question struct {
+	Question string
+}
+
+// Answer is the output of a RAG program, with the passages it used.
+type Answer struct {
+	Answer  string
+	Context []dspy.Passage
+}
+
+// QueryInput is the input to a search query generator.
+type QueryInput struct {
+	Context  string
+	Question string
+}
+
+// QueryOutput is the output of a search query generator.
+type QueryOutput struct {
+	Query string
+}
+
+// AnswerInput is the input to an answer generator.
+type AnswerInput struct {
+	Context  string
+	Question string
+}
+
+// AnswerOutput is the output of an answer generator.
+type AnswerOutput struct {
+	Answer string
+}
+
+// GenerateQuerySignature returns the signature for follow-up search query
+// generation at the given hop (counting from 1).
+func GenerateQuerySignature(hop int) dspy.Signature[QueryInput, QueryOutput] {
+	return dspy.NewSignature[QueryInput, QueryOutput](
+		fmt.Sprintf("GenerateQuery%d", hop),
+		"Write a simple search query that will help answer a complex question. "+
+			"Use the context gathered so far to look for the missing information.",
+	)
+}
+
+// GenerateAnswerSignature returns the signature for answering from context.
+func GenerateAnswerSignature() dspy.Signature[AnswerInput, AnswerOutput] {
+	return dspy.NewSignature[AnswerInput, AnswerOutput](
+		"GenerateAnswer",
+		"Answer the question using only the context. Answers are often short factoid phrases.",
+	)
+}
+
+// SimplifiedBaleen is a multi-hop retrieve-then-answer program after DSPy's
+// SimplifiedBaleen. On each hop it generates a search query from the
+// question and the context gathered so far, retrieves passages and adds
+// the new ones to the context; finally it answers from the context.
+//
+// The query generators and answer generator are exported so that
+// optimizers can tune them individually and the results can be assigned
+// back. Each has a distinct signature name for trace attribution.
+type SimplifiedBaleen struct {
+	GenerateQuery  []dspy.Module[QueryInput, QueryOutput]
+	Retrieve       *dspy.Retrieve
+	GenerateAnswer dspy.Module[AnswerInput, AnswerOutput]
+	// TokenBudget limits the accumulated context. Zero means unlimited.
+	TokenBudget int
+}
+
+// NewSimplifiedBaleen creates a program making maxHops retrievals of
+// passagesPerHop passages each, with predictors backed by client. It makes
+// at least one hop.
+func NewSimplifiedBaleen(client llm.Client, retriever dspy.Retriever, maxHops, passagesPerHop int) *SimplifiedBaleen {
+	maxHops = max(maxHops, 1)
+	queries := make([]dspy.Module[QueryInput, QueryOutput], maxHops)
+	for i := range queries {
+		queries[i] = dspy.NewPredictor(GenerateQuerySignature(i+1), client)
+	}
+
+	return &SimplifiedBaleen{
+		GenerateQuery:  queries,
+		Retrieve:       dspy.NewRetrieve(retriever, passagesPerHop),
+		GenerateAnswer: dspy.NewPredictor(GenerateAnswerSignature(), client),
+	}
+}
+
+// WithTokenBudget sets the context token budget.
+func (b *SimplifiedBaleen) WithTokenBudget(tokens int) *SimplifiedBaleen {
+	b.TokenBudget = tokens
+	return b
+}
+
+// Forward implements the Module interface.
+func (b *SimplifiedBaleen) Forward(ctx context.Context, input Question) (Answer, error) {
+	acc := NewContextAccumulator(b.TokenBudget)
+
+	for _, generate := range b.GenerateQuery {
+		q, err := generate.Forward(ctx, QueryInput{
+			Context:  acc.String(),
+			Question: input.Question,
+		})
+		if err != nil {
+			return Answer{}, err
+		}
+
+		query := q.Query
+		if query == "" {
+			query = input.Question
+		}
+
+		passages, err := b.Retrieve.Forward(ctx, query)
+		if err != nil {
+			return Answer{}, err
+		}
+		acc.Add(passages...)
+	}
+
+	out, err := b.GenerateAnswer.Forward(ctx, AnswerInput{
+		Context:  acc.String(),
+		Question: input.Question,
+	})
+	if err != nil {
+		return Answer{}, err
+	}
+
+	return Answer{Answer: out.Answer, Context: acc.Passages()}, nil
+}
@@ -0,0 +1,149 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/index"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// funcClient answers prompts with a function.
+type funcClient struct {
+	respond func(prompt string) (string, error)
+}
+
+func (c *funcClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return c.respond(prompt)
+}
+
+func (c *funcClient) GenerateWithOptions(ctx context.Context, prompt string, opts *llm.GenerateOptions) (string, error) {
+	return c.respond(prompt)
+}
+
+func newTestIndex(t *testing.T) *index.BM25 {
+	t.Helper()
+	idx := index.NewBM25()
+	err := idx.Upsert(
+		index.Document{ID: "oppenheimer", Text: "The film Oppenheimer was directed by Christopher Nolan."},
+		index.Document{ID: "nolan", Text: "Christopher Nolan was born in London in 1970."},
+		index.Document{ID: "london", Text: "London is the capital of England."},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestSimplifiedBaleen_Forward(t *testing.T) {
+	var prompts []string
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		switch {
+		case strings.Contains(prompt, "search query") && !strings.Contains(prompt, "[1]"):
+			return `{"query": "Oppenheimer director"}`, nil
+		case strings.Contains(prompt, "search query"):
+			return `{"query": "Christopher Nolan born"}`, nil
+		default:
+			return `{"answer": "London"}`, nil
+		}
+	}}
+
+	baleen := NewSimplifiedBaleen(client, newTestIndex(t), 2, 1)
+	trace := dspy.NewTrace()
+	ctx := dspy.WithTrace(context.Background(), trace)
+
+	out, err := baleen.Forward(ctx, Question{Question: "Where was the director of Oppenheimer born?"})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if out.Answer != "London" {
+		t.Errorf("Expected answer London, got %q", out.Answer)
+	}
+
+	ids := make([]string, len(out.Context))
+	for i, p := range out.Context {
+		ids[i] = p.ID
+	}
+	if strings.Join(ids, ",") != "oppenheimer,nolan" {
+		t.Errorf("Expected context from both hops, got %v", ids)
+	}
+
+	last := prompts[len(prompts)-1]
+	if !strings.Contains(last, "[2] Christopher Nolan was born") {
+		t.Errorf("Expected answer prompt to include accumulated context, got %q", last)
+	}
+
+	var names []string
+	for _, e := range trace.Entries() {
+		names = append(names, e.Predictor)
+	}
+	want := "GenerateQuery1,Retrieve,GenerateQuery2,Retrieve,GenerateAnswer"
+	if strings.Join(names, ",") != want {
+		t.Errorf("Expected trace %s, got %v", want, names)
+	}
+}
+
+func TestSimplifiedBaleen_EmptyQueryFallsBack(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		if strings.Contains(prompt, "search query") {
+			return `{"query": ""}`, nil
+		}
+		return `{"answer": "Nolan"}`, nil
+	}}
+
+	baleen := NewSimplifiedBaleen(client, newTestIndex(t), 1, 2)
+	out, err := baleen.Forward(context.Background(), Question{Question: "Who directed Oppenheimer?"})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if len(out.Context) == 0 || out.Context[0].ID != "oppenheimer" {
+		t.Errorf("Expected question to be used as the query, got %+v", out.Context)
+	}
+}
+
+func TestSimplifiedBaleen_TokenBudget(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		if strings.Contains(prompt, "search query") {
+			return `{"query": "Christopher Nolan London"}`, nil
+		}
+		return `{"answer": "?"}`, nil
+	}}
+
+	baleen := NewSimplifiedBaleen(client, newTestIndex(t), 1, 3).WithTokenBudget(15)
+	out, err := baleen.Forward(context.Background(), Question{Question: "q"})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+
+	total := 0
+	for _, p := range out.Context {
+		total += llm.EstimateTokens(p.Text)
+	}
+	if total > 15 || len(out.Context) == 0 {
+		t.Errorf("Expected non-empty context within budget, got %d tokens in %d passages", total, len(out.Context))
+	}
+}
+
+func TestSimplifiedBaleen_Error(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		return "", errors.New("unavailable")
+	}}
+
+	baleen := NewSimplifiedBaleen(client, newTestIndex(t), 2, 1)
+	if _, err := baleen.Forward(context.Background(), Question{Question: "q"}); err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
+func TestSimplifiedBaleen_ClampsHops(t *testing.T) {
+	for _, hops := range []int{0, -1} {
+		baleen := NewSimplifiedBaleen(&funcClient{}, newTestIndex(t), hops, 1)
+		if len(baleen.GenerateQuery) != 1 {
+			t.Errorf("Expected %d hops to become 1, got %d", hops, len(baleen.GenerateQuery))
+		}
+	}
+}
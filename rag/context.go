@@ -0,0 +1,99 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// ContextAccumulator collects retrieved passages across hops, dropping
+// duplicates and stopping at a token budget.
+type ContextAccumulator struct {
+	// Budget is the maximum number of tokens of passage text kept.
+	// Zero means unlimited.
+	Budget int
+	// CountTokens measures passage text. It defaults to llm.EstimateTokens.
+	CountTokens func(text string) int
+
+	passages []dspy.Passage
+	seen     map[string]bool
+	tokens   int
+}
+
+// NewContextAccumulator creates an accumulator with the given token budget.
+func NewContextAccumulator(budget int) *ContextAccumulator {
+	return &ContextAccumulator{
+		Budget:      budget,
+		CountTokens: llm.EstimateTokens,
+		seen:        make(map[string]bool),
+	}
+}
+
+// Add appends passages not seen before, in order, skipping any that would
+// exceed the budget. Passages are identified by ID, or by their normalized
+// text when they have none. It returns the number of passages added.
+func (c *ContextAccumulator) Add(passages ...dspy.Passage) int {
+	if c.seen == nil {
+		c.seen = make(map[string]bool)
+	}
+	count := c.CountTokens
+	if count == nil {
+		count = llm.EstimateTokens
+	}
+
+	added := 0
+	for _, p := range passages {
+		key := passageKey(p)
+		if c.seen[key] {
+			continue
+		}
+		tokens := count(p.Text)
+		if c.Budget > 0 && c.tokens+tokens > c.Budget {
+			continue
+		}
+		c.seen[key] = true
+		c.tokens += tokens
+		c.passages = append(c.passages, p)
+		added++
+	}
+	return added
+}
+
+// Passages returns the accumulated passages in the order they were added.
+func (c *ContextAccumulator) Passages() []dspy.Passage {
+	return append([]dspy.Passage(nil), c.passages...)
+}
+
+// Tokens returns the number of tokens accumulated.
+func (c *ContextAccumulator) Tokens() int {
+	return c.tokens
+}
+
+// Len returns the number of accumulated passages.
+func (c *ContextAccumulator) Len() int {
+	return len(c.passages)
+}
+
+// String formats the passages as a numbered list for use in prompts.
+func (c *ContextAccumulator) String() string {
+	return FormatPassages(c.passages)
+}
+
+// FormatPassages formats passages as a numbered list, one per line.
+func FormatPassages(passages []dspy.Passage) string {
+	lines := make([]string, len(passages))
+	for i, p := range passages {
+		lines[i] = fmt.Sprintf("[%d] %s", i+1, strings.Join(strings.Fields(p.Text), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// passageKey returns the deduplication key of p.
+func passageKey(p dspy.Passage) string {
+	if p.ID != "" {
+		return "id:" + p.ID
+	}
+	return "text:" + strings.ToLower(strings.Join(strings.Fields(p.Text), " "))
+}
@@ -0,0 +1,69 @@
+package rag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+func TestContextAccumulator_Dedup(t *testing.T) {
+	acc := NewContextAccumulator(0)
+
+	added := acc.Add(
+		dspy.Passage{ID: "a", Text: "Paris is the capital of France."},
+		dspy.Passage{ID: "b", Text: "Berlin is the capital of Germany."},
+		dspy.Passage{ID: "a", Text: "Paris is the capital of France."},
+	)
+	if added != 2 {
+		t.Errorf("Expected 2 passages added, got %d", added)
+	}
+
+	added = acc.Add(
+		dspy.Passage{Text: "Rome is  the capital of Italy."},
+		dspy.Passage{Text: "rome is the capital of italy."},
+		dspy.Passage{ID: "b", Text: "Berlin is the capital of Germany."},
+	)
+	if added != 1 {
+		t.Errorf("Expected 1 passage added, got %d", added)
+	}
+	if acc.Len() != 3 {
+		t.Errorf("Expected 3 passages, got %d", acc.Len())
+	}
+}
+
+func TestContextAccumulator_Budget(t *testing.T) {
+	acc := NewContextAccumulator(10)
+	acc.CountTokens = func(text string) int { return len(strings.Fields(text)) }
+
+	acc.Add(
+		dspy.Passage{ID: "1", Text: "one two three four five six"},
+		dspy.Passage{ID: "2", Text: "seven eight nine ten eleven"},
+		dspy.Passage{ID: "3", Text: "twelve thirteen"},
+	)
+
+	passages := acc.Passages()
+	if len(passages) != 2 || passages[0].ID != "1" || passages[1].ID != "3" {
+		t.Fatalf("Expected passages 1 and 3, got %+v", passages)
+	}
+	if acc.Tokens() != 8 {
+		t.Errorf("Expected 8 tokens, got %d", acc.Tokens())
+	}
+
+	// A skipped passage may still be added later if it fits.
+	acc.Budget = 20
+	if acc.Add(dspy.Passage{ID: "2", Text: "seven eight nine ten eleven"}) != 1 {
+		t.Error("Expected previously skipped passage to be added")
+	}
+}
+
+func TestFormatPassages(t *testing.T) {
+	got := FormatPassages([]dspy.Passage{
+		{Text: "First\npassage."},
+		{Text: "Second passage."},
+	})
+	want := "[1] First passage.\n[2] Second passage."
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,91 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+// DefaultRRFConstant is the rank offset commonly used for reciprocal rank
+// fusion. Larger values flatten the contribution of top ranks.
+const DefaultRRFConstant = 60
+
+// ReciprocalRankFusion merges ranked passage lists into one. Each passage
+// scores the sum of 1/(c+rank) over the lists it appears in, with ranks
+// counted from 1, so scores from differently scaled retrievers never need
+// to be compared. Passages are matched by ID, or by text when they have
+// none; the first occurrence supplies the text and metadata.
+func ReciprocalRankFusion(c float64, lists ...[]dspy.Passage) []dspy.Passage {
+	if c < 0 {
+		c = DefaultRRFConstant
+	}
+
+	var fused []dspy.Passage
+	positions := make(map[string]int)
+	for _, list := range lists {
+		for rank, p := range list {
+			score := 1 / (c + float64(rank+1))
+			key := passageKey(p)
+			if i, ok := positions[key]; ok {
+				fused[i].Score += score
+				continue
+			}
+			positions[key] = len(fused)
+			p.Score = score
+			fused = append(fused, p)
+		}
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+	return fused
+}
+
+// Fusion is a retriever that queries several retrievers and merges their
+// results with reciprocal rank fusion, typically to combine a keyword index
+// with a vector index.
+type Fusion struct {
+	Retrievers []dspy.Retriever
+	// Constant is the RRF rank offset. It defaults to DefaultRRFConstant.
+	Constant float64
+	// Candidates is how many passages are requested from each retriever.
+	// Values smaller than k are raised to k.
+	Candidates int
+}
+
+// NewFusion creates a fusion retriever over retrievers.
+func NewFusion(retrievers ...dspy.Retriever) *Fusion {
+	return &Fusion{
+		Retrievers: retrievers,
+		Constant:   DefaultRRFConstant,
+	}
+}
+
+// WithConstant sets the RRF rank offset.
+func (f *Fusion) WithConstant(c float64) *Fusion {
+	f.Constant = c
+	return f
+}
+
+// WithCandidates sets how many passages are requested from each retriever.
+func (f *Fusion) WithCandidates(n int) *Fusion {
+	f.Candidates = n
+	return f
+}
+
+// Retrieve implements the dspy.Retriever interface.
+func (f *Fusion) Retrieve(ctx context.Context, query string, k int) ([]dspy.Passage, error) {
+	n := max(f.Candidates, k)
+	lists := make([][]dspy.Passage, len(f.Retrievers))
+	for i, r := range f.Retrievers {
+		passages, err := r.Retrieve(ctx, query, n)
+		if err != nil {
+			return nil, fmt.Errorf("retriever %d: %w", i, err)
+		}
+		lists[i] = passages
+	}
+	return truncate(ReciprocalRankFusion(f.Constant, lists...), k), nil
+}
@@ -0,0 +1,80 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+// listRetriever returns a fixed ranked list.
+type listRetriever struct {
+	passages []dspy.Passage
+	err      error
+	k        int
+}
+
+func (r *listRetriever) Retrieve(ctx context.Context, query string, k int) ([]dspy.Passage, error) {
+	r.k = k
+	if r.err != nil {
+		return nil, r.err
+	}
+	return truncate(r.passages, k), nil
+}
+
+func ids(passages []dspy.Passage) string {
+	s := make([]string, len(passages))
+	for i, p := range passages {
+		s[i] = p.ID
+	}
+	return strings.Join(s, ",")
+}
+
+func TestReciprocalRankFusion(t *testing.T) {
+	keyword := []dspy.Passage{{ID: "a", Score: 12}, {ID: "b", Score: 8}, {ID: "c", Score: 1}}
+	vector := []dspy.Passage{{ID: "c", Score: 0.9}, {ID: "b", Score: 0.8}, {ID: "d", Score: 0.7}}
+
+	fused := ReciprocalRankFusion(60, keyword, vector)
+	if got := ids(fused); got != "c,b,a,d" {
+		t.Errorf("Expected c,b,a,d, got %s", got)
+	}
+
+	want := 1.0/63 + 1.0/61
+	if d := fused[0].Score - want; d > 1e-12 || d < -1e-12 {
+		t.Errorf("Expected score %v, got %v", want, fused[0].Score)
+	}
+}
+
+func TestReciprocalRankFusion_TextKeys(t *testing.T) {
+	fused := ReciprocalRankFusion(0,
+		[]dspy.Passage{{Text: "Same passage."}},
+		[]dspy.Passage{{Text: "same  passage."}, {Text: "Other."}},
+	)
+	if len(fused) != 2 || fused[0].Score != 2 {
+		t.Errorf("Expected passages without IDs to merge by text, got %+v", fused)
+	}
+}
+
+func TestFusion_Retrieve(t *testing.T) {
+	keyword := &listRetriever{passages: []dspy.Passage{{ID: "a"}, {ID: "b"}, {ID: "c"}}}
+	vector := &listRetriever{passages: []dspy.Passage{{ID: "b"}, {ID: "d"}}}
+
+	fusion := NewFusion(keyword, vector).WithCandidates(10)
+	passages, err := fusion.Retrieve(context.Background(), "q", 2)
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if got := ids(passages); got != "b,a" {
+		t.Errorf("Expected b,a, got %s", got)
+	}
+	if keyword.k != 10 || vector.k != 10 {
+		t.Errorf("Expected 10 candidates per retriever, got %d and %d", keyword.k, vector.k)
+	}
+
+	vector.err = errors.New("offline")
+	if _, err := fusion.Retrieve(context.Background(), "q", 2); err == nil {
+		t.Error("Expected error from failing retriever")
+	}
+}
@@ -0,0 +1,82 @@
+package rag
+
+import (
+	"context"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// HypotheticalInput is the input to a hypothetical document generator.
+type HypotheticalInput struct {
+	Question string
+}
+
+// HypotheticalOutput is the output of a hypothetical document generator.
+type HypotheticalOutput struct {
+	Passage string
+}
+
+// HypotheticalDocumentSignature returns the signature used by HyDE.
+func HypotheticalDocumentSignature() dspy.Signature[HypotheticalInput, HypotheticalOutput] {
+	return dspy.NewSignature[HypotheticalInput, HypotheticalOutput](
+		"HypotheticalDocument",
+		"Write a short passage that answers the question, in the style of a reference document. "+
+			"It is used as a search query, so favor specific terms over hedging.",
+	)
+}
+
+// HyDE expands queries with Hypothetical Document Embeddings: it asks an
+// LLM to write a passage answering the query and retrieves with that
+// passage instead, which often lands closer to relevant documents in
+// embedding space than a short question does.
+//
+// HyDE implements dspy.Retriever, so it can be used anywhere a retriever
+// is, including inside a Retrieve module or SimplifiedBaleen.
+type HyDE struct {
+	Generate  dspy.Module[HypotheticalInput, HypotheticalOutput]
+	Retriever dspy.Retriever
+	// IncludeQuery appends the original query to the hypothetical passage.
+	IncludeQuery bool
+}
+
+// NewHyDE creates a HyDE retriever generating passages with client and
+// retrieving from retriever.
+func NewHyDE(client llm.Client, retriever dspy.Retriever) *HyDE {
+	return &HyDE{
+		Generate:  dspy.NewPredictor(HypotheticalDocumentSignature(), client),
+		Retriever: retriever,
+	}
+}
+
+// WithIncludeQuery sets whether the original query is kept in the expansion.
+func (h *HyDE) WithIncludeQuery(include bool) *HyDE {
+	h.IncludeQuery = include
+	return h
+}
+
+// Expand returns the expanded query for query.
+func (h *HyDE) Expand(ctx context.Context, query string) (string, error) {
+	out, err := h.Generate.Forward(ctx, HypotheticalInput{Question: query})
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case out.Passage == "":
+		return query, nil
+	case h.IncludeQuery:
+		return query + "\n" + out.Passage, nil
+	default:
+		return out.Passage, nil
+	}
+}
+
+// Retrieve implements the dspy.Retriever interface.
+func (h *HyDE) Retrieve(ctx context.Context, query string, k int) ([]dspy.Passage, error) {
+	expanded, err := h.Expand(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return h.Retriever.Retrieve(ctx, expanded, k)
+}
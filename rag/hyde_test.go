@@ -0,0 +1,61 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+// recordingRetriever records the queries it receives.
+type recordingRetriever struct {
+	queries []string
+}
+
+func (r *recordingRetriever) Retrieve(ctx context.Context, query string, k int) ([]dspy.Passage, error) {
+	r.queries = append(r.queries, query)
+	return []dspy.Passage{{ID: "1", Text: "result"}}, nil
+}
+
+func TestHyDE_Retrieve(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		return `{"passage": "Christopher Nolan directed the 2023 film Oppenheimer."}`, nil
+	}}
+	inner := &recordingRetriever{}
+
+	hyde := NewHyDE(client, inner)
+	passages, err := hyde.Retrieve(context.Background(), "Who directed Oppenheimer?", 3)
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(passages) != 1 {
+		t.Errorf("Expected 1 passage, got %d", len(passages))
+	}
+	if inner.queries[0] != "Christopher Nolan directed the 2023 film Oppenheimer." {
+		t.Errorf("Expected hypothetical passage as query, got %q", inner.queries[0])
+	}
+
+	hyde.WithIncludeQuery(true)
+	if _, err := hyde.Retrieve(context.Background(), "Who directed Oppenheimer?", 3); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(inner.queries[1], "Who directed Oppenheimer?\n") {
+		t.Errorf("Expected original query to be kept, got %q", inner.queries[1])
+	}
+}
+
+func TestHyDE_AsRetrieveModule(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		return `{"passage": ""}`, nil
+	}}
+	inner := &recordingRetriever{}
+
+	retrieve := dspy.NewRetrieve(NewHyDE(client, inner), 2)
+	if _, err := retrieve.Forward(context.Background(), "plain query"); err != nil {
+		t.Fatal(err)
+	}
+	if inner.queries[0] != "plain query" {
+		t.Errorf("Expected empty expansion to fall back to the query, got %q", inner.queries[0])
+	}
+}
@@ -0,0 +1,173 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+	"github.com/supadev-ai/go-dspy/llm"
+)
+
+// RelevanceInput is the input to a pointwise relevance grader.
+type RelevanceInput struct {
+	Query   string
+	Passage string
+}
+
+// RelevanceOutput is the output of a pointwise relevance grader.
+// Score is a number from 0 (irrelevant) to 10 (fully answers the query).
+type RelevanceOutput struct {
+	Score string
+}
+
+// RankingInput is the input to a listwise ranker.
+type RankingInput struct {
+	Query    string
+	Passages string
+}
+
+// RankingOutput is the output of a listwise ranker: passage numbers, most
+// relevant first, such as "3, 1, 2".
+type RankingOutput struct {
+	Ranking string
+}
+
+// RelevanceSignature returns the signature used by pointwise reranking.
+func RelevanceSignature() dspy.Signature[RelevanceInput, RelevanceOutput] {
+	return dspy.NewSignature[RelevanceInput, RelevanceOutput](
+		"RerankPointwise",
+		"Rate how relevant the passage is to the query on a scale from 0 (irrelevant) "+
+			"to 10 (fully answers the query). Reply with the number only.",
+	)
+}
+
+// RankingSignature returns the signature used by listwise reranking.
+func RankingSignature() dspy.Signature[RankingInput, RankingOutput] {
+	return dspy.NewSignature[RankingInput, RankingOutput](
+		"RerankListwise",
+		"Rank the numbered passages by relevance to the query. "+
+			"Reply with the passage numbers, most relevant first, separated by commas.",
+	)
+}
+
+// PointwiseReranker scores each passage independently with an LLM and
+// sorts by score, keeping retrieval order for ties. The passage Score is
+// replaced with the relevance grade scaled to [0, 1].
+type PointwiseReranker struct {
+	Grade dspy.Module[RelevanceInput, RelevanceOutput]
+}
+
+// NewPointwiseReranker creates a pointwise reranker backed by client.
+func NewPointwiseReranker(client llm.Client) *PointwiseReranker {
+	return &PointwiseReranker{
+		Grade: dspy.NewPredictor(RelevanceSignature(), client),
+	}
+}
+
+// Rerank implements the dspy.Reranker interface.
+func (r *PointwiseReranker) Rerank(ctx context.Context, query string, passages []dspy.Passage, k int) ([]dspy.Passage, error) {
+	scored := make([]dspy.Passage, len(passages))
+	for i, p := range passages {
+		out, err := r.Grade.Forward(ctx, RelevanceInput{Query: query, Passage: p.Text})
+		if err != nil {
+			return nil, err
+		}
+		grade, err := parseGrade(out.Score)
+		if err != nil {
+			return nil, fmt.Errorf("passage %d: %w", i+1, err)
+		}
+		p.Score = grade / 10
+		scored[i] = p
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	return truncate(scored, k), nil
+}
+
+// ListwiseReranker asks an LLM to order all passages at once, which lets
+// it compare them against each other. Passages the LLM leaves out keep
+// their retrieval order after the ranked ones. Passage scores are replaced
+// with reciprocal ranks.
+type ListwiseReranker struct {
+	Rank dspy.Module[RankingInput, RankingOutput]
+}
+
+// NewListwiseReranker creates a listwise reranker backed by client.
+func NewListwiseReranker(client llm.Client) *ListwiseReranker {
+	return &ListwiseReranker{
+		Rank: dspy.NewPredictor(RankingSignature(), client),
+	}
+}
+
+// Rerank implements the dspy.Reranker interface.
+func (r *ListwiseReranker) Rerank(ctx context.Context, query string, passages []dspy.Passage, k int) ([]dspy.Passage, error) {
+	out, err := r.Rank.Forward(ctx, RankingInput{
+		Query:    query,
+		Passages: FormatPassages(passages),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	used := make([]bool, len(passages))
+	ranked := make([]dspy.Passage, 0, len(passages))
+	for _, n := range parseRanking(out.Ranking) {
+		if n < 1 || n > len(passages) || used[n-1] {
+			continue
+		}
+		used[n-1] = true
+		ranked = append(ranked, passages[n-1])
+	}
+	for i, p := range passages {
+		if !used[i] {
+			ranked = append(ranked, p)
+		}
+	}
+
+	for i := range ranked {
+		ranked[i].Score = 1 / float64(i+1)
+	}
+	return truncate(ranked, k), nil
+}
+
+// number matches an unsigned integer or decimal.
+var number = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// parseGrade extracts a 0-10 grade from an LLM reply.
+func parseGrade(s string) (float64, error) {
+	m := number.FindString(s)
+	if m == "" {
+		return 0, fmt.Errorf("no relevance score in %q", s)
+	}
+	grade, err := strconv.ParseFloat(m, 64)
+	if err != nil {
+		return 0, err
+	}
+	return min(max(grade, 0), 10), nil
+}
+
+// parseRanking extracts passage numbers in order from an LLM reply,
+// accepting forms like "3, 1, 2", "[3] > [1] > [2]" and one per line.
+func parseRanking(s string) []int {
+	var ranking []int
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r < '0' || r > '9' }) {
+		if n, err := strconv.Atoi(field); err == nil {
+			ranking = append(ranking, n)
+		}
+	}
+	return ranking
+}
+
+// truncate returns the first k passages, or all of them if k is not positive.
+func truncate(passages []dspy.Passage, k int) []dspy.Passage {
+	if k > 0 && len(passages) > k {
+		return passages[:k]
+	}
+	return passages
+}
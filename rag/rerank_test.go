@@ -0,0 +1,109 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/supadev-ai/go-dspy/dspy"
+)
+
+func testPassages() []dspy.Passage {
+	return []dspy.Passage{
+		{ID: "a", Text: "Go is a programming language."},
+		{ID: "b", Text: "Goroutines are lightweight threads."},
+		{ID: "c", Text: "The Go gopher is a mascot."},
+	}
+}
+
+func TestPointwiseReranker(t *testing.T) {
+	grades := map[string]string{
+		"Go is a programming language.":       `{"score": "4"}`,
+		"Goroutines are lightweight threads.": `{"score": "9"}`,
+		"The Go gopher is a mascot.":          `{"score": "4"}`,
+	}
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		for text, reply := range grades {
+			if strings.Contains(prompt, text) {
+				return reply, nil
+			}
+		}
+		return "", nil
+	}}
+
+	ranked, err := NewPointwiseReranker(client).Rerank(context.Background(), "concurrency in Go", testPassages(), 2)
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if len(ranked) != 2 || ranked[0].ID != "b" || ranked[1].ID != "a" {
+		t.Fatalf("Expected b then a, got %+v", ranked)
+	}
+	if ranked[0].Score != 0.9 {
+		t.Errorf("Expected score 0.9, got %v", ranked[0].Score)
+	}
+}
+
+func TestPointwiseReranker_BadGrade(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		return `{"score": "very relevant"}`, nil
+	}}
+
+	if _, err := NewPointwiseReranker(client).Rerank(context.Background(), "q", testPassages(), 3); err == nil {
+		t.Fatal("Expected error for unparseable grade")
+	}
+}
+
+func TestListwiseReranker(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		if !strings.Contains(prompt, "[3] The Go gopher is a mascot.") {
+			t.Errorf("Expected numbered passages in prompt, got %q", prompt)
+		}
+		return `{"ranking": "[3] > [3] > [7] > [1]"}`, nil
+	}}
+
+	ranked, err := NewListwiseReranker(client).Rerank(context.Background(), "mascot", testPassages(), 0)
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+
+	var ids []string
+	for _, p := range ranked {
+		ids = append(ids, p.ID)
+	}
+	if strings.Join(ids, ",") != "c,a,b" {
+		t.Errorf("Expected c,a,b, got %v", ids)
+	}
+	if ranked[0].Score != 1 || ranked[1].Score != 0.5 {
+		t.Errorf("Expected reciprocal rank scores, got %+v", ranked)
+	}
+}
+
+func TestParseRanking(t *testing.T) {
+	got := parseRanking("3, 1\n2")
+	if len(got) != 3 || got[0] != 3 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("Unexpected ranking %v", got)
+	}
+}
+
+func TestRerankerInRetrieve(t *testing.T) {
+	client := &funcClient{respond: func(prompt string) (string, error) {
+		return `{"ranking": "2, 1"}`, nil
+	}}
+
+	retrieve := dspy.NewRetrieve(newTestIndex(t), 1).WithReranker(NewListwiseReranker(client), 2)
+	passages, err := retrieve.Forward(context.Background(), "Christopher Nolan")
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if len(passages) != 1 {
+		t.Fatalf("Expected 1 passage, got %d", len(passages))
+	}
+
+	top, err := newTestIndex(t).Retrieve(context.Background(), "Christopher Nolan", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passages[0].ID != top[1].ID {
+		t.Errorf("Expected second retrieved passage on top, got %s", passages[0].ID)
+	}
+}
@@ -0,0 +1,186 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the spans emitted by go-dspy.
+const InstrumentationName = "github.com/supadev-ai/go-dspy"
+
+// Attributes from the OpenTelemetry semantic conventions for generative AI.
+const (
+	AttrGenAISystem                = attribute.Key("gen_ai.system")
+	AttrGenAIOperationName         = attribute.Key("gen_ai.operation.name")
+	AttrGenAIRequestModel          = attribute.Key("gen_ai.request.model")
+	AttrGenAIRequestTemperature    = attribute.Key("gen_ai.request.temperature")
+	AttrGenAIRequestMaxTokens      = attribute.Key("gen_ai.request.max_tokens")
+	AttrGenAIRequestStopSequences  = attribute.Key("gen_ai.request.stop_sequences")
+	AttrGenAIResponseID            = attribute.Key("gen_ai.response.id")
+	AttrGenAIResponseModel         = attribute.Key("gen_ai.response.model")
+	AttrGenAIResponseFinishReasons = attribute.Key("gen_ai.response.finish_reasons")
+	AttrGenAIUsageInputTokens      = attribute.Key("gen_ai.usage.input_tokens")
+	AttrGenAIUsageOutputTokens     = attribute.Key("gen_ai.usage.output_tokens")
+	AttrGenAIPrompt                = attribute.Key("gen_ai.prompt")
+	AttrGenAICompletion            = attribute.Key("gen_ai.completion")
+)
+
+// Attributes describing DSPy programs.
+const (
+	AttrPredictor = attribute.Key("dspy.predictor")
+	AttrOptimizer = attribute.Key("dspy.optimizer")
+	AttrIteration = attribute.Key("dspy.iteration")
+	AttrExamples  = attribute.Key("dspy.examples")
+	AttrExample   = attribute.Key("dspy.example")
+	AttrScore     = attribute.Key("dspy.score")
+)
+
+// Events carrying captured content.
+const (
+	EventPrompt     = "gen_ai.content.prompt"
+	EventCompletion = "gen_ai.content.completion"
+)
+
+var config struct {
+	mu             sync.RWMutex
+	provider       trace.TracerProvider
+	captureContent bool
+}
+
+// SetTracerProvider sets the provider used for the spans go-dspy emits
+// automatically. A nil provider restores the default, the global provider
+// registered with otel.SetTracerProvider.
+func SetTracerProvider(tp trace.TracerProvider) {
+	config.mu.Lock()
+	defer config.mu.Unlock()
+	config.provider = tp
+}
+
+// TracerProvider returns the provider used for automatic spans.
+func TracerProvider() trace.TracerProvider {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+	if config.provider != nil {
+		return config.provider
+	}
+	return otel.GetTracerProvider()
+}
+
+// SetCaptureContent enables or disables recording prompts and completions
+// on spans. They may contain sensitive data, so capture is off by default.
+func SetCaptureContent(enabled bool) {
+	config.mu.Lock()
+	defer config.mu.Unlock()
+	config.captureContent = enabled
+}
+
+// CaptureContent reports whether prompts and completions are recorded.
+func CaptureContent() bool {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+	return config.captureContent
+}
+
+// Start starts a span with the configured tracer provider.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return TracerProvider().Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RecordPrompt adds prompt to span as an event if content capture is
+// enabled.
+func RecordPrompt(span trace.Span, prompt string) {
+	if CaptureContent() {
+		span.AddEvent(EventPrompt, trace.WithAttributes(AttrGenAIPrompt.String(prompt)))
+	}
+}
+
+// RecordCompletion adds completion to span as an event if content capture
+// is enabled.
+func RecordCompletion(span trace.Span, completion string) {
+	if CaptureContent() {
+		span.AddEvent(EventCompletion, trace.WithAttributes(AttrGenAICompletion.String(completion)))
+	}
+}
+
+// ChatRequest describes a call to a chat model for StartChat.
+type ChatRequest struct {
+	// System is the provider, such as "openai" or "anthropic".
+	System        string
+	Model         string
+	Temperature   float64
+	MaxTokens     int
+	StopSequences []string
+	Prompt        string
+}
+
+// ChatResponse describes the outcome of a chat model call for ChatSpan.End.
+type ChatResponse struct {
+	ID            string
+	Model         string
+	FinishReasons []string
+	InputTokens   int
+	OutputTokens  int
+	Completion    string
+}
+
+// ChatSpan is a span around a single chat model call.
+type ChatSpan struct {
+	span trace.Span
+}
+
+// StartChat starts a span named "chat <model>" for a model call, following
+// the generative AI semantic conventions.
+func StartChat(ctx context.Context, req ChatRequest) (context.Context, *ChatSpan) {
+	attrs := []attribute.KeyValue{
+		AttrGenAISystem.String(req.System),
+		AttrGenAIOperationName.String("chat"),
+		AttrGenAIRequestModel.String(req.Model),
+		AttrGenAIRequestTemperature.Float64(req.Temperature),
+	}
+	if req.MaxTokens > 0 {
+		attrs = append(attrs, AttrGenAIRequestMaxTokens.Int(req.MaxTokens))
+	}
+	if len(req.StopSequences) > 0 {
+		attrs = append(attrs, AttrGenAIRequestStopSequences.StringSlice(req.StopSequences))
+	}
+
+	ctx, span := TracerProvider().Tracer(InstrumentationName).Start(ctx, "chat "+req.Model,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	RecordPrompt(span, req.Prompt)
+	return ctx, &ChatSpan{span: span}
+}
+
+// End records the response, or err, and ends the span.
+func (s *ChatSpan) End(resp ChatResponse, err error) {
+	if err == nil {
+		if resp.ID != "" {
+			s.span.SetAttributes(AttrGenAIResponseID.String(resp.ID))
+		}
+		if resp.Model != "" {
+			s.span.SetAttributes(AttrGenAIResponseModel.String(resp.Model))
+		}
+		if len(resp.FinishReasons) > 0 {
+			s.span.SetAttributes(AttrGenAIResponseFinishReasons.StringSlice(resp.FinishReasons))
+		}
+		s.span.SetAttributes(
+			AttrGenAIUsageInputTokens.Int(resp.InputTokens),
+			AttrGenAIUsageOutputTokens.Int(resp.OutputTokens),
+		)
+		RecordCompletion(s.span, resp.Completion)
+	}
+	End(s.span, err)
+}
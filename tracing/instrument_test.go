@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans routes automatic spans to an in-memory recorder for the
+// duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		SetTracerProvider(nil)
+		SetCaptureContent(false)
+	})
+	return recorder
+}
+
+// attrs indexes the attributes of a span by key.
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTracerProvider_DefaultsToGlobal(t *testing.T) {
+	SetTracerProvider(nil)
+	if TracerProvider() != otel.GetTracerProvider() {
+		t.Error("Expected the global provider by default")
+	}
+}
+
+func TestStartChat(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := StartChat(context.Background(), ChatRequest{
+		System:        "openai",
+		Model:         "gpt-4o",
+		Temperature:   0.2,
+		MaxTokens:     256,
+		StopSequences: []string{"\n\n"},
+		Prompt:        "secret prompt",
+	})
+	span.End(ChatResponse{
+		ID:            "resp-1",
+		Model:         "gpt-4o-2024-08-06",
+		FinishReasons: []string{"stop"},
+		InputTokens:   12,
+		OutputTokens:  3,
+		Completion:    "secret answer",
+	}, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "chat gpt-4o" {
+		t.Errorf("Expected span named after the model, got %q", s.Name())
+	}
+
+	a := attrs(s)
+	if a[AttrGenAISystem].AsString() != "openai" ||
+		a[AttrGenAIRequestModel].AsString() != "gpt-4o" ||
+		a[AttrGenAIRequestTemperature].AsFloat64() != 0.2 ||
+		a[AttrGenAIRequestMaxTokens].AsInt64() != 256 ||
+		a[AttrGenAIResponseModel].AsString() != "gpt-4o-2024-08-06" ||
+		a[AttrGenAIResponseFinishReasons].AsStringSlice()[0] != "stop" ||
+		a[AttrGenAIUsageInputTokens].AsInt64() != 12 ||
+		a[AttrGenAIUsageOutputTokens].AsInt64() != 3 {
+		t.Errorf("Unexpected attributes %v", s.Attributes())
+	}
+	if len(s.Events()) != 0 {
+		t.Errorf("Expected no content captured by default, got %v", s.Events())
+	}
+}
+
+func TestStartChat_CaptureContent(t *testing.T) {
+	recorder := recordSpans(t)
+	SetCaptureContent(true)
+
+	_, span := StartChat(context.Background(), ChatRequest{System: "anthropic", Model: "claude", Prompt: "hi"})
+	span.End(ChatResponse{Completion: "hello"}, nil)
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 2 || events[0].Name != EventPrompt || events[1].Name != EventCompletion {
+		t.Fatalf("Expected prompt and completion events, got %v", events)
+	}
+	if events[0].Attributes[0].Value.AsString() != "hi" || events[1].Attributes[0].Value.AsString() != "hello" {
+		t.Errorf("Unexpected captured content %v", events)
+	}
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "work")
+	End(span, errors.New("boom"))
+
+	s := recorder.Ended()[0]
+	if s.Status().Code != codes.Error || s.Status().Description != "boom" {
+		t.Errorf("Expected error status, got %v", s.Status())
+	}
+	if len(s.Events()) != 1 || s.Events()[0].Name != "exception" {
+		t.Errorf("Expected an exception event, got %v", s.Events())
+	}
+}
@@ -0,0 +1,190 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metric instrument names.
+const (
+	MetricLLMRequests     = "dspy.llm.requests"
+	MetricLLMDuration     = "dspy.llm.duration"
+	MetricLLMTokens       = "dspy.llm.tokens"
+	MetricLLMErrors       = "dspy.llm.errors"
+	MetricCacheLookups    = "dspy.cache.lookups"
+	MetricEvaluationScore = "dspy.evaluation.score"
+)
+
+// Attributes used only on metrics.
+const (
+	AttrGenAITokenType = attribute.Key("gen_ai.token.type")
+	AttrErrorType      = attribute.Key("error.type")
+	AttrCache          = attribute.Key("dspy.cache")
+	AttrCacheHit       = attribute.Key("dspy.cache.hit")
+)
+
+// ErrorTyper is implemented by errors that report a low-cardinality type
+// for the error.type metric attribute, such as an HTTP status code.
+type ErrorTyper interface {
+	ErrorType() string
+}
+
+type instruments struct {
+	requests     metric.Int64Counter
+	duration     metric.Float64Histogram
+	tokens       metric.Int64Counter
+	errors       metric.Int64Counter
+	cacheLookups metric.Int64Counter
+	scores       metric.Float64Histogram
+}
+
+var meters struct {
+	mu          sync.Mutex
+	provider    metric.MeterProvider
+	instruments *instruments
+}
+
+// SetMeterProvider sets the provider used for the metrics go-dspy records
+// automatically. A nil provider restores the default, the global provider
+// registered with otel.SetMeterProvider.
+func SetMeterProvider(mp metric.MeterProvider) {
+	meters.mu.Lock()
+	defer meters.mu.Unlock()
+	meters.provider = mp
+	meters.instruments = nil
+}
+
+// MeterProvider returns the provider used for automatic metrics.
+func MeterProvider() metric.MeterProvider {
+	meters.mu.Lock()
+	defer meters.mu.Unlock()
+	return meterProviderLocked()
+}
+
+func meterProviderLocked() metric.MeterProvider {
+	if meters.provider != nil {
+		return meters.provider
+	}
+	return otel.GetMeterProvider()
+}
+
+// instrumentsFor returns the instruments of the configured provider,
+// creating them on first use.
+func instrumentsFor() *instruments {
+	meters.mu.Lock()
+	defer meters.mu.Unlock()
+	if meters.instruments != nil {
+		return meters.instruments
+	}
+
+	meter := meterProviderLocked().Meter(InstrumentationName)
+	var inst instruments
+	var errs [6]error
+	inst.requests, errs[0] = meter.Int64Counter(MetricLLMRequests,
+		metric.WithDescription("Number of LLM requests."),
+		metric.WithUnit("{request}"))
+	inst.duration, errs[1] = meter.Float64Histogram(MetricLLMDuration,
+		metric.WithDescription("Duration of LLM requests."),
+		metric.WithUnit("s"))
+	inst.tokens, errs[2] = meter.Int64Counter(MetricLLMTokens,
+		metric.WithDescription("Number of tokens used by LLM requests."),
+		metric.WithUnit("{token}"))
+	inst.errors, errs[3] = meter.Int64Counter(MetricLLMErrors,
+		metric.WithDescription("Number of failed LLM requests."),
+		metric.WithUnit("{error}"))
+	inst.cacheLookups, errs[4] = meter.Int64Counter(MetricCacheLookups,
+		metric.WithDescription("Number of cache lookups, labeled by hit or miss."),
+		metric.WithUnit("{lookup}"))
+	inst.scores, errs[5] = meter.Float64Histogram(MetricEvaluationScore,
+		metric.WithDescription("Metric scores of evaluated examples."),
+		metric.WithUnit("1"))
+	if err := errors.Join(errs[:]...); err != nil {
+		otel.Handle(err)
+	}
+
+	meters.instruments = &inst
+	return meters.instruments
+}
+
+type labelsKey struct{}
+
+// WithLabels returns a context whose metrics carry attrs in addition to
+// their own attributes. Predictors label the calls they make with
+// AttrPredictor this way; callers can add their own, such as a pipeline
+// name. A label set later replaces one with the same key.
+func WithLabels(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	prev := Labels(ctx)
+	labels := make([]attribute.KeyValue, 0, len(prev)+len(attrs))
+	labels = append(labels, prev...)
+	return context.WithValue(ctx, labelsKey{}, append(labels, attrs...))
+}
+
+// Labels returns the labels attached to ctx by WithLabels.
+func Labels(ctx context.Context) []attribute.KeyValue {
+	labels, _ := ctx.Value(labelsKey{}).([]attribute.KeyValue)
+	return labels
+}
+
+// measure combines the labels on ctx with attrs.
+func measure(ctx context.Context, attrs ...attribute.KeyValue) metric.MeasurementOption {
+	labels := Labels(ctx)
+	all := make([]attribute.KeyValue, 0, len(labels)+len(attrs))
+	all = append(append(all, labels...), attrs...)
+	return metric.WithAttributeSet(attribute.NewSet(all...))
+}
+
+// RecordChat records the request count, latency, token usage and errors of
+// a chat model call, labeled by provider, model and the labels on ctx.
+func RecordChat(ctx context.Context, req ChatRequest, resp ChatResponse, elapsed time.Duration, err error) {
+	inst := instrumentsFor()
+	attrs := []attribute.KeyValue{
+		AttrGenAISystem.String(req.System),
+		AttrGenAIOperationName.String("chat"),
+		AttrGenAIRequestModel.String(req.Model),
+	}
+
+	inst.requests.Add(ctx, 1, measure(ctx, attrs...))
+	if err != nil {
+		failed := measure(ctx, append(attrs, AttrErrorType.String(ErrorType(err)))...)
+		inst.errors.Add(ctx, 1, failed)
+		inst.duration.Record(ctx, elapsed.Seconds(), failed)
+		return
+	}
+	inst.duration.Record(ctx, elapsed.Seconds(), measure(ctx, attrs...))
+	inst.tokens.Add(ctx, int64(resp.InputTokens), measure(ctx, append(attrs, AttrGenAITokenType.String("input"))...))
+	inst.tokens.Add(ctx, int64(resp.OutputTokens), measure(ctx, append(attrs, AttrGenAITokenType.String("output"))...))
+}
+
+// RecordCacheLookup records a hit or miss in the named cache.
+func RecordCacheLookup(ctx context.Context, cache string, hit bool) {
+	instrumentsFor().cacheLookups.Add(ctx, 1, measure(ctx, AttrCache.String(cache), AttrCacheHit.Bool(hit)))
+}
+
+// RecordScore records the metric score of one evaluated example.
+func RecordScore(ctx context.Context, score float64) {
+	instrumentsFor().scores.Record(ctx, score, measure(ctx))
+}
+
+// ErrorType returns the error.type attribute value for err: the value of
+// an ErrorTyper in its chain, "timeout" or "canceled" for context errors,
+// and the Go type of err otherwise.
+func ErrorType(err error) string {
+	var typer ErrorTyper
+	switch {
+	case errors.As(err, &typer):
+		return typer.ErrorType()
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return fmt.Sprintf("%T", err)
+	}
+}
@@ -0,0 +1,154 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// recordMetrics routes automatic metrics to a manual reader for the
+// duration of the test.
+func recordMetrics(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { SetMeterProvider(nil) })
+	return reader
+}
+
+// collect returns the metrics recorded so far, indexed by name.
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			m[metric.Name] = metric.Data
+		}
+	}
+	return m
+}
+
+// sum returns the value of the counter data point carrying attr.
+func sum(data metricdata.Aggregation, attr attribute.KeyValue) int64 {
+	for _, dp := range data.(metricdata.Sum[int64]).DataPoints {
+		if v, ok := dp.Attributes.Value(attr.Key); ok && v == attr.Value {
+			return dp.Value
+		}
+	}
+	return 0
+}
+
+type statusError struct{ code int }
+
+func (e statusError) Error() string     { return "status" }
+func (e statusError) ErrorType() string { return fmt.Sprint(e.code) }
+
+func TestRecordChat(t *testing.T) {
+	reader := recordMetrics(t)
+	ctx := WithLabels(context.Background(), AttrPredictor.String("QA"))
+	req := ChatRequest{System: "openai", Model: "gpt-4o"}
+
+	RecordChat(ctx, req, ChatResponse{InputTokens: 10, OutputTokens: 4}, 200*time.Millisecond, nil)
+	RecordChat(ctx, req, ChatResponse{InputTokens: 5, OutputTokens: 1}, 100*time.Millisecond, nil)
+	RecordChat(ctx, req, ChatResponse{}, time.Second, fmt.Errorf("Call: %w", statusError{429}))
+
+	m := collect(t, reader)
+	requests := m[MetricLLMRequests].(metricdata.Sum[int64]).DataPoints
+	if len(requests) != 1 || requests[0].Value != 3 {
+		t.Fatalf("Expected 3 requests in one series, got %v", requests)
+	}
+	for _, attr := range []attribute.KeyValue{
+		AttrGenAISystem.String("openai"),
+		AttrGenAIRequestModel.String("gpt-4o"),
+		AttrPredictor.String("QA"),
+	} {
+		if v, ok := requests[0].Attributes.Value(attr.Key); !ok || v != attr.Value {
+			t.Errorf("Expected label %v, got %v", attr, requests[0].Attributes)
+		}
+	}
+
+	if got := sum(m[MetricLLMTokens], AttrGenAITokenType.String("input")); got != 15 {
+		t.Errorf("Expected 15 input tokens, got %d", got)
+	}
+	if got := sum(m[MetricLLMTokens], AttrGenAITokenType.String("output")); got != 5 {
+		t.Errorf("Expected 5 output tokens, got %d", got)
+	}
+	if got := sum(m[MetricLLMErrors], AttrErrorType.String("429")); got != 1 {
+		t.Errorf("Expected one error of type 429, got %d", got)
+	}
+
+	var calls uint64
+	for _, dp := range m[MetricLLMDuration].(metricdata.Histogram[float64]).DataPoints {
+		calls += dp.Count
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 latency observations, got %d", calls)
+	}
+}
+
+func TestRecordCacheLookupAndScore(t *testing.T) {
+	reader := recordMetrics(t)
+	ctx := WithLabels(context.Background(), attribute.String("pipeline", "rag"))
+
+	RecordCacheLookup(ctx, "judge", true)
+	RecordCacheLookup(ctx, "judge", true)
+	RecordCacheLookup(ctx, "judge", false)
+	RecordScore(ctx, 1)
+	RecordScore(ctx, 0.5)
+
+	m := collect(t, reader)
+	if hits := sum(m[MetricCacheLookups], AttrCacheHit.Bool(true)); hits != 2 {
+		t.Errorf("Expected 2 hits, got %d", hits)
+	}
+	if misses := sum(m[MetricCacheLookups], AttrCacheHit.Bool(false)); misses != 1 {
+		t.Errorf("Expected 1 miss, got %d", misses)
+	}
+
+	scores := m[MetricEvaluationScore].(metricdata.Histogram[float64]).DataPoints
+	if len(scores) != 1 || scores[0].Count != 2 || scores[0].Sum != 1.5 {
+		t.Fatalf("Expected two scores summing to 1.5, got %v", scores)
+	}
+	if v, _ := scores[0].Attributes.Value("pipeline"); v.AsString() != "rag" {
+		t.Errorf("Expected the pipeline label, got %v", scores[0].Attributes)
+	}
+}
+
+func TestWithLabels_LaterReplacesEarlier(t *testing.T) {
+	ctx := WithLabels(context.Background(), AttrPredictor.String("outer"))
+	inner := WithLabels(ctx, AttrPredictor.String("inner"))
+
+	set := attribute.NewSet(Labels(inner)...)
+	if v, _ := set.Value(AttrPredictor); v.AsString() != "inner" {
+		t.Errorf("Expected the inner predictor, got %v", v.AsString())
+	}
+	if len(Labels(ctx)) != 1 {
+		t.Errorf("Expected the outer context unchanged, got %v", Labels(ctx))
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("Wrapped: %w", statusError{503}), "503"},
+		{context.DeadlineExceeded, "timeout"},
+		{fmt.Errorf("Execute request: %w", context.Canceled), "canceled"},
+		{errors.New("boom"), "*errors.errorString"},
+	}
+	for _, tt := range tests {
+		if got := ErrorType(tt.err); got != tt.want {
+			t.Errorf("ErrorType(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}